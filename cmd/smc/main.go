@@ -1,19 +1,305 @@
 package main
 
 import (
+	"bytes"
+	"flag"
 	"fmt"
+	"go/token"
+	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/geisonbiazus/smc/internal/smc"
+	"github.com/geisonbiazus/smc/internal/smc/coverage"
+	"github.com/geisonbiazus/smc/internal/smc/semantic"
+)
+
+const (
+	exitOK            = 0
+	exitUsage         = 1
+	exitSyntaxError   = 2
+	exitSemanticError = 3
+	exitIOError       = 4
+	exitUnhandled     = 5
+	exitFormatError   = 6
+	exitTemplateError = 7
 )
 
 func main() {
-	compiler := smc.NewCompiler(os.Stdin, os.Stdout)
-	err := compiler.Compile()
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+type cli struct {
+	stdin              io.Reader
+	stdout             io.Writer
+	stderr             io.Writer
+	command            string
+	flags              *flag.FlagSet
+	outputFile         string
+	pkg                string
+	generator          string
+	language           string
+	templateDir        string
+	warningsAsErrors   bool
+	suppressedWarnings warningTypes
+	coverageFormat     string
+	failOnUnhandled    bool
+}
+
+const (
+	commandCompile  = "compile"
+	commandCoverage = "coverage"
+)
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c := &cli{stdin: stdin, stdout: stdout, stderr: stderr}
+	if !c.parseFlags(args) {
+		return exitUsage
+	}
+
+	switch c.command {
+	case commandCoverage:
+		return c.coverage()
+	default:
+		return c.compile()
+	}
+}
+
+func (c *cli) parseFlags(args []string) bool {
+	c.command = commandCompile
+	if len(args) > 0 && args[0] == commandCoverage {
+		c.command = commandCoverage
+		args = args[1:]
+	}
+
+	c.flags = flag.NewFlagSet("smc", flag.ContinueOnError)
+	c.flags.SetOutput(c.stderr)
+	c.flags.Usage = c.usage
+	c.flags.StringVar(&c.outputFile, "o", "", "output file (default: stdout)")
+	c.flags.BoolVar(&c.warningsAsErrors, "Werror", false, "treat warnings as errors")
+	c.flags.Var(&c.suppressedWarnings, "Wno", "comma separated warning types to suppress, e.g. UNUSED_STATE (repeatable)")
+
+	switch c.command {
+	case commandCoverage:
+		c.flags.StringVar(&c.coverageFormat, "format", string(coverage.FormatText), "output format: text, markdown or csv")
+		c.flags.BoolVar(&c.failOnUnhandled, "fail-unhandled", false, "fail when any state leaves an event unhandled")
+	default:
+		c.flags.StringVar(&c.pkg, "package", "", "package name of the generated code (default: Package header or output directory name)")
+		c.flags.StringVar(&c.generator, "generator", string(smc.GeneratorStatePattern), "code generator: statepattern, table or switch")
+		c.flags.StringVar(&c.language, "language", string(smc.LanguageGo), "target language: go or java")
+		c.flags.StringVar(&c.templateDir, "templates", "", "directory with text/template files overriding the statepattern output")
+	}
+
+	if err := c.flags.Parse(args); err != nil {
+		return false
+	}
+
+	if c.flags.NArg() > 1 || !c.validCoverageFormat() || !c.validGenerator() || !c.validTemplates() || !c.validLanguage() {
+		c.usage()
+		return false
+	}
+	return true
+}
 
+func (c *cli) validCoverageFormat() bool {
+	switch coverage.Format(c.coverageFormat) {
+	case "", coverage.FormatText, coverage.FormatMarkdown, coverage.FormatCSV:
+		return true
+	}
+	fmt.Fprintf(c.stderr, "invalid coverage format: %s\n", c.coverageFormat)
+	return false
+}
+
+func (c *cli) validGenerator() bool {
+	switch smc.Generator(c.generator) {
+	case "", smc.GeneratorStatePattern, smc.GeneratorTable, smc.GeneratorSwitch:
+		return true
+	}
+	fmt.Fprintf(c.stderr, "invalid generator: %s\n", c.generator)
+	return false
+}
+
+func (c *cli) validTemplates() bool {
+	if c.templateDir == "" || smc.Generator(c.generator) == smc.GeneratorStatePattern {
+		return true
+	}
+	fmt.Fprintf(c.stderr, "templates require the %s generator\n", smc.GeneratorStatePattern)
+	return false
+}
+
+func (c *cli) validLanguage() bool {
+	switch smc.Language(c.language) {
+	case "", smc.LanguageGo:
+		return true
+	case smc.LanguageJava:
+		if smc.Generator(c.generator) == smc.GeneratorStatePattern && c.templateDir == "" {
+			return true
+		}
+		fmt.Fprintf(c.stderr, "%s supports only the %s generator without templates\n", c.language, smc.GeneratorStatePattern)
+		return false
+	}
+	fmt.Fprintf(c.stderr, "invalid language: %s\n", c.language)
+	return false
+}
+
+func (c *cli) usage() {
+	fmt.Fprintln(c.stderr, "Usage:")
+	fmt.Fprintln(c.stderr, "  smc [flags] [input file]           compile a state machine")
+	fmt.Fprintln(c.stderr, "  smc coverage [flags] [input file]  print the state by event coverage matrix")
+	fmt.Fprintln(c.stderr)
+	fmt.Fprintln(c.stderr, "Reads from stdin when no input file is given.")
+	fmt.Fprintln(c.stderr)
+	c.flags.PrintDefaults()
+}
+
+func (c *cli) compile() int {
+	return c.runCompiler(func(compiler *smc.Compiler) error {
+		compiler.Package = c.pkg
+		if smc.Language(c.language) != smc.LanguageJava {
+			compiler.DefaultPackage = defaultPackage(c.outputFile)
+		}
+		compiler.Generator = smc.Generator(c.generator)
+		compiler.TemplateDir = c.templateDir
+		compiler.Language = smc.Language(c.language)
+		if !c.readsStdin() {
+			compiler.SourceFile = c.inputName()
+		}
+		return compiler.Compile()
+	})
+}
+
+func (c *cli) coverage() int {
+	return c.runCompiler(func(compiler *smc.Compiler) error {
+		return compiler.Coverage(coverage.Format(c.coverageFormat), c.failOnUnhandled)
+	})
+}
+
+func (c *cli) runCompiler(command func(*smc.Compiler) error) int {
+	input, err := c.openInput()
 	if err != nil {
-		for _, e := range compiler.Errors {
-			fmt.Println(e.String())
+		fmt.Fprintln(c.stderr, err)
+		return exitIOError
+	}
+	defer input.Close()
+
+	output := &bytes.Buffer{}
+	compiler := smc.NewCompiler(input, output)
+	compiler.WarningsAsErrors = c.warningsAsErrors
+	compiler.SuppressedWarnings = c.suppressedWarnings
+
+	err = command(compiler)
+	c.printWarnings(compiler.Warnings)
+
+	if err != nil && err != smc.UnhandledEventsError {
+		c.printErrors(compiler.Errors)
+		return exitCode(err)
+	}
+
+	if err := c.writeOutput(output.Bytes()); err != nil {
+		fmt.Fprintln(c.stderr, err)
+		return exitIOError
+	}
+
+	if err == smc.UnhandledEventsError {
+		fmt.Fprintln(c.stderr, err)
+		return exitUnhandled
+	}
+	return exitOK
+}
+
+func (c *cli) printErrors(errors []smc.Error) {
+	for _, e := range errors {
+		fmt.Fprintln(c.stderr, smc.FormatDiagnostic(c.inputName(), "error", e))
+	}
+}
+
+func (c *cli) printWarnings(warnings []smc.Error) {
+	for _, w := range warnings {
+		fmt.Fprintln(c.stderr, smc.FormatDiagnostic(c.inputName(), "warning", w))
+	}
+}
+
+func (c *cli) inputName() string {
+	if c.readsStdin() {
+		return "<stdin>"
+	}
+	return c.flags.Arg(0)
+}
+
+func (c *cli) readsStdin() bool {
+	path := c.flags.Arg(0)
+	return path == "" || path == "-"
+}
+
+func (c *cli) openInput() (io.ReadCloser, error) {
+	if c.readsStdin() {
+		return ioutil.NopCloser(c.stdin), nil
+	}
+	return os.Open(c.flags.Arg(0))
+}
+
+func (c *cli) writeOutput(content []byte) error {
+	if c.outputFile == "" {
+		_, err := c.stdout.Write(content)
+		return err
+	}
+	return ioutil.WriteFile(c.outputFile, content, 0644)
+}
+
+func exitCode(err error) int {
+	switch err {
+	case smc.SyntaxError:
+		return exitSyntaxError
+	case smc.SemanticError:
+		return exitSemanticError
+	case smc.FormatError:
+		return exitFormatError
+	case smc.TemplateError:
+		return exitTemplateError
+	default:
+		return exitIOError
+	}
+}
+
+func defaultPackage(outputFile string) string {
+	if outputFile == "" {
+		return ""
+	}
+
+	path, err := filepath.Abs(outputFile)
+	if err != nil {
+		return ""
+	}
+
+	name := filepath.Base(filepath.Dir(path))
+	if !token.IsIdentifier(name) {
+		return ""
+	}
+	return name
+}
+
+type warningTypes []semantic.ErrorType
+
+func (w *warningTypes) String() string {
+	names := []string{}
+	for _, t := range *w {
+		names = append(names, string(t))
+	}
+	return strings.Join(names, ",")
+}
+
+func (w *warningTypes) Set(value string) error {
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToUpper(strings.Replace(strings.TrimSpace(name), "-", "_", -1))
+		if name == "" {
+			continue
+		}
+		if !semantic.IsWarningType(semantic.ErrorType(name)) {
+			return fmt.Errorf("unknown warning type: %s", name)
 		}
+		*w = append(*w, semantic.ErrorType(name))
 	}
+	return nil
 }
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCLI(t *testing.T) {
+	t.Run("Writes the compiled FSM to stdout", func(t *testing.T) {
+		code, stdout, stderr := runCLI(validFSM)
+		assert.Equal(t, exitOK, code)
+		assert.Contains(t, stdout, "package fsm")
+		assert.Empty(t, stderr)
+	})
+
+	t.Run("Syntax errors", func(t *testing.T) {
+		code, stdout, stderr := runCLI("& a:b {}")
+		assert.Equal(t, exitSyntaxError, code)
+		assert.Empty(t, stdout)
+		assert.Equal(t, "<stdin>:1:1: error: SYNTAX\n", stderr)
+	})
+
+	t.Run("Semantic errors", func(t *testing.T) {
+		code, stdout, stderr := runCLI("a:b {}")
+		assert.Equal(t, exitSemanticError, code)
+		assert.Empty(t, stdout)
+		assert.Contains(t, stderr, "NO_FSM")
+	})
+
+	t.Run("Warnings", func(t *testing.T) {
+		code, stdout, stderr := runCLI(unusedStateFSM)
+		assert.Equal(t, exitOK, code)
+		assert.NotEmpty(t, stdout)
+		assert.Equal(t, "<stdin>:2:2: warning: UNUSED_STATE: other\n", stderr)
+	})
+
+	t.Run("Warnings as errors", func(t *testing.T) {
+		code, stdout, stderr := runCLI(unusedStateFSM, "-Werror")
+		assert.Equal(t, exitSemanticError, code)
+		assert.Empty(t, stdout)
+		assert.Contains(t, stderr, "UNUSED_STATE")
+	})
+
+	t.Run("Suppressed warnings", func(t *testing.T) {
+		code, _, stderr := runCLI(unusedStateFSM, "-Werror", "-Wno", "unused-state")
+		assert.Equal(t, exitOK, code)
+		assert.Empty(t, stderr)
+
+		code, _, stderr = runCLI(unusedStateFSM, "-Werror", "-Wno", "unused-sate")
+		assert.Equal(t, exitUsage, code)
+		assert.Contains(t, stderr, "unknown warning type: UNUSED_SATE")
+	})
+
+	t.Run("Coverage", func(t *testing.T) {
+		code, stdout, _ := runCLI(validFSM, "coverage", "-format", "csv")
+		assert.Equal(t, exitOK, code)
+		assert.Equal(t, "State,event\nstate,handled\n", stdout)
+	})
+
+	t.Run("Coverage with unhandled events", func(t *testing.T) {
+		input := "FSM: f Initial: a { a e b - \n b f a - }"
+
+		code, stdout, _ := runCLI(input, "coverage")
+		assert.Equal(t, exitOK, code)
+		assert.Contains(t, stdout, "unhandled")
+
+		code, stdout, stderr := runCLI(input, "coverage", "-fail-unhandled")
+		assert.Equal(t, exitUnhandled, code)
+		assert.Contains(t, stdout, "unhandled")
+		assert.NotEmpty(t, stderr)
+	})
+
+	t.Run("Coverage with final states", func(t *testing.T) {
+		code, stdout, _ := runCLI("FSM: f Initial: a Final: b { a e b - \n b - - - }", "coverage", "-format", "csv", "-fail-unhandled")
+		assert.Equal(t, exitOK, code)
+		assert.Equal(t, "State,e\na,handled\nb,final\n", stdout)
+	})
+
+	t.Run("Coverage with invalid format", func(t *testing.T) {
+		code, _, _ := runCLI(validFSM, "coverage", "-format", "xml")
+		assert.Equal(t, exitUsage, code)
+	})
+
+	t.Run("Generator", func(t *testing.T) {
+		code, stdout, _ := runCLI(validFSM, "-generator", "table")
+		assert.Equal(t, exitOK, code)
+		assert.Contains(t, stdout, "var transitions = map[state]map[event][]transition{")
+
+		code, stdout, _ = runCLI(validFSM, "-generator", "switch")
+		assert.Equal(t, exitOK, code)
+		assert.Contains(t, stdout, "switch state {")
+
+		code, _, stderr := runCLI(validFSM, "-generator", "goto")
+		assert.Equal(t, exitUsage, code)
+		assert.Contains(t, stderr, "invalid generator: goto")
+	})
+
+	t.Run("Language", func(t *testing.T) {
+		code, stdout, _ := runCLI(validFSM, "-language", "java", "-package", "com.example.fsm")
+		assert.Equal(t, exitOK, code)
+		assert.Contains(t, stdout, "package com.example.fsm;\n\npublic class Fsm {\n")
+
+		code, _, stderr := runCLI(validFSM, "-language", "java", "-generator", "table")
+		assert.Equal(t, exitUsage, code)
+		assert.Contains(t, stderr, "java supports only the statepattern generator without templates")
+
+		code, _, stderr = runCLI(validFSM, "-language", "cobol")
+		assert.Equal(t, exitUsage, code)
+		assert.Contains(t, stderr, "invalid language: cobol")
+	})
+
+	t.Run("Templates", func(t *testing.T) {
+		dir := tempDir(t, "templates")
+		template := "\n// {{.StateName}} is a state.\ntype State{{title .StateName}} struct{ BaseState }\n"
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "state_class.tmpl"), []byte(template), 0644))
+
+		code, stdout, _ := runCLI(validFSM, "-templates", dir)
+		assert.Equal(t, exitOK, code)
+		assert.Contains(t, stdout, "// state is a state.\ntype StateState struct{ BaseState }\n")
+
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "header.tmpl"), []byte("{{.Missing}}"), 0644))
+		code, stdout, stderr := runCLI(validFSM, "-templates", dir)
+		assert.Equal(t, exitTemplateError, code)
+		assert.Empty(t, stdout)
+		assert.Contains(t, stderr, "<stdin>: error: TEMPLATE: ")
+
+		code, stdout, stderr = runCLI(validFSM, "-templates", filepath.Join(dir, "missing"))
+		assert.Equal(t, exitTemplateError, code)
+		assert.Empty(t, stdout)
+		assert.Contains(t, stderr, "<stdin>: error: TEMPLATE: ")
+
+		code, stdout, stderr = runCLI(validFSM, "-templates", filepath.Join(dir, "header.tmpl"))
+		assert.Equal(t, exitTemplateError, code)
+		assert.Empty(t, stdout)
+		assert.Contains(t, stderr, "header.tmpl is not a directory")
+
+		code, _, stderr = runCLI(validFSM, "-templates", dir, "-generator", "table")
+		assert.Equal(t, exitUsage, code)
+		assert.Contains(t, stderr, "templates require the statepattern generator")
+	})
+
+	t.Run("Formatting errors", func(t *testing.T) {
+		code, stdout, stderr := runCLI("FSM: fsm Initial: a Event: e(n type) { a e a - }")
+		assert.Equal(t, exitFormatError, code)
+		assert.Empty(t, stdout)
+		assert.Contains(t, stderr, "<stdin>: error: FORMAT: generated code is invalid: ")
+	})
+
+	t.Run("Missing input file", func(t *testing.T) {
+		code, stdout, stderr := runCLI("", "does_not_exist.txt")
+		assert.Equal(t, exitIOError, code)
+		assert.Empty(t, stdout)
+		assert.NotEmpty(t, stderr)
+	})
+
+	t.Run("Invalid flags", func(t *testing.T) {
+		code, _, _ := runCLI("", "-invalid")
+		assert.Equal(t, exitUsage, code)
+	})
+
+	t.Run("Output file", func(t *testing.T) {
+		dir := tempDir(t, "turnstile")
+		outputFile := filepath.Join(dir, "turnstile_fsm.go")
+
+		code, stdout, _ := runCLI(validFSM, "-o", outputFile)
+		assert.Equal(t, exitOK, code)
+		assert.Empty(t, stdout)
+
+		content, err := ioutil.ReadFile(outputFile)
+		assert.Nil(t, err)
+		assert.Contains(t, string(content), "package turnstile")
+	})
+
+	t.Run("Java output file", func(t *testing.T) {
+		dir := tempDir(t, "turnstile")
+		outputFile := filepath.Join(dir, "Fsm.java")
+
+		code, _, _ := runCLI(validFSM, "-language", "java", "-o", outputFile)
+		assert.Equal(t, exitOK, code)
+
+		content, err := ioutil.ReadFile(outputFile)
+		assert.Nil(t, err)
+		assert.Contains(t, string(content), "package fsm;\n")
+	})
+
+	t.Run("Output file is not written on errors", func(t *testing.T) {
+		dir := tempDir(t, "turnstile")
+		outputFile := filepath.Join(dir, "turnstile_fsm.go")
+
+		code, _, _ := runCLI("a:b {}", "-o", outputFile)
+		assert.Equal(t, exitSemanticError, code)
+
+		_, err := os.Stat(outputFile)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+const (
+	validFSM       = "FSM: fsm Initial: state { state event state action }"
+	unusedStateFSM = "FSM: fsm Initial: state { state event state action \n other event state action }"
+)
+
+func runCLI(input string, args ...string) (int, string, string) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	code := run(args, bytes.NewBufferString(input), stdout, stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func tempDir(t *testing.T, name string) string {
+	t.Helper()
+	parent, err := ioutil.TempDir("", "smc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(parent) })
+
+	dir := filepath.Join(parent, name)
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
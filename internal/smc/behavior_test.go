@@ -0,0 +1,131 @@
+package smc
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneratedBehavior(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go tool")
+	}
+
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+
+	scenarios, _ := filepath.Glob(filepath.Join("testdata", "behavior", "*"))
+
+	templateDir, err := ioutil.TempDir("", "smc-templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(templateDir)
+
+	runVariants(t, goTool, scenarios, templateDir)
+}
+
+// TestGeneratedRaceFreedom drives machines with timers on the system clock,
+// so the timer callbacks run concurrently with the event methods.
+func TestGeneratedRaceFreedom(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go tool")
+	}
+
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+
+	cgo, err := exec.Command(goTool, "env", "CGO_ENABLED").Output()
+	if err != nil || string(bytes.TrimSpace(cgo)) != "1" {
+		t.Skip("race detector requires cgo")
+	}
+
+	scenarios, _ := filepath.Glob(filepath.Join("testdata", "race", "*"))
+
+	templateDir, err := ioutil.TempDir("", "smc-templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(templateDir)
+
+	runVariants(t, goTool, scenarios, templateDir, "-race")
+}
+
+func runVariants(t *testing.T, goTool string, scenarios []string, templateDir string, flags ...string) {
+	variants := []struct {
+		name        string
+		generator   Generator
+		templateDir string
+	}{
+		{string(GeneratorStatePattern), GeneratorStatePattern, ""},
+		{string(GeneratorTable), GeneratorTable, ""},
+		{string(GeneratorSwitch), GeneratorSwitch, ""},
+		{"templates", GeneratorStatePattern, templateDir},
+	}
+
+	for _, variant := range variants {
+		t.Run(variant.name, func(t *testing.T) {
+			for _, scenario := range scenarios {
+				t.Run(filepath.Base(scenario), func(t *testing.T) {
+					expected, err := ioutil.ReadFile(filepath.Join(scenario, "expected.txt"))
+					assert.Nil(t, err)
+					assert.Equal(t, string(expected), runScenario(t, goTool, scenario, variant.generator, variant.templateDir, flags...))
+				})
+			}
+		})
+	}
+}
+
+func runScenario(t *testing.T, goTool, scenario string, generator Generator, templateDir string, flags ...string) string {
+	dir, err := ioutil.TempDir("", "smc-behavior")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	input, err := os.Open(filepath.Join(scenario, "machine.sm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer input.Close()
+
+	output := &bytes.Buffer{}
+	compiler := NewCompiler(input, output)
+	compiler.Package = "main"
+	compiler.Generator = generator
+	compiler.TemplateDir = templateDir
+	if err := compiler.Compile(); err != nil {
+		t.Fatal(err, compiler.Errors)
+	}
+
+	driver, err := ioutil.ReadFile(filepath.Join(scenario, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "fsm.go"), output.Bytes())
+	writeFile(t, filepath.Join(dir, "main.go"), driver)
+
+	args := append([]string{"run"}, flags...)
+	cmd := exec.Command(goTool, append(args, "main.go", "fsm.go")...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	return string(out)
+}
+
+func writeFile(t *testing.T, path string, content []byte) {
+	if err := ioutil.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
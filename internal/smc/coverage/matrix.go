@@ -0,0 +1,97 @@
+package coverage
+
+import "github.com/geisonbiazus/smc/internal/smc/optimizer"
+
+type Matrix struct {
+	Events []string
+	Rows   []Row
+}
+
+type Row struct {
+	State    string
+	Statuses []Status
+}
+
+type Status string
+
+const (
+	StatusHandled   Status = "handled"
+	StatusInherited Status = "inherited"
+	StatusUnhandled Status = "unhandled"
+	StatusFinal     Status = "final"
+	StatusIgnored   Status = "-"
+)
+
+type Builder struct {
+	fsm *optimizer.FSM
+}
+
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+func (b *Builder) Build(fsm *optimizer.FSM) *Matrix {
+	b.fsm = fsm
+	matrix := &Matrix{Events: fsm.Events}
+
+	for _, state := range fsm.States {
+		matrix.Rows = append(matrix.Rows, b.row(state))
+	}
+	return matrix
+}
+
+func (b *Builder) row(state *optimizer.State) Row {
+	row := Row{State: state.Name}
+	for _, event := range b.fsm.Events {
+		if b.dispatched(state, event) {
+			row.Statuses = append(row.Statuses, b.status(state, event))
+		} else {
+			row.Statuses = append(row.Statuses, StatusIgnored)
+		}
+	}
+	return row
+}
+
+func (b *Builder) dispatched(state *optimizer.State, event string) bool {
+	for _, region := range b.fsm.Regions {
+		if region.Name == state.Region {
+			return contains(region.Events, event)
+		}
+	}
+	return true
+}
+
+func contains(list []string, item string) bool {
+	for _, i := range list {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Builder) status(state *optimizer.State, event string) Status {
+	for _, t := range state.Transitions {
+		if t.Event == event {
+			if t.Inherited {
+				return StatusInherited
+			}
+			return StatusHandled
+		}
+	}
+	if contains(b.fsm.FinalStates, state.Name) {
+		return StatusFinal
+	}
+	return StatusUnhandled
+}
+
+func (m *Matrix) HasUnhandled() bool {
+	for _, row := range m.Rows {
+		for _, status := range row.Statuses {
+			if status == StatusUnhandled {
+				return true
+			}
+		}
+	}
+	return false
+}
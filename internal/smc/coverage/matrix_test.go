@@ -0,0 +1,146 @@
+package coverage
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/geisonbiazus/smc/internal/smc/lexer"
+	"github.com/geisonbiazus/smc/internal/smc/optimizer"
+	"github.com/geisonbiazus/smc/internal/smc/parser"
+	"github.com/geisonbiazus/smc/internal/smc/semantic"
+	"github.com/stretchr/testify/assert"
+)
+
+const turnstile = `
+	FSM: TwoCoinTurnstile
+	Initial: Locked
+	{
+	  (Base)  Reset  Locked  lock
+
+	  Locked : Base {
+	    Pass  Alarming   -
+	    Coin  FirstCoin  -
+	  }
+
+	  Alarming : Base  >alarmOn <alarmOff {
+	    - - -
+	  }
+
+	  FirstCoin : Base {
+	    Pass  Alarming  -
+	    Coin  Unlocked  unlock
+	  }
+
+	  Unlocked : Base {
+	    Pass  Locked  lock
+	    Coin  -       thankyou
+	  }
+	}
+`
+
+func TestMatrix(t *testing.T) {
+	t.Run("Build", func(t *testing.T) {
+		assert.Equal(t,
+			&Matrix{
+				Events: []string{"Reset", "Pass", "Coin"},
+				Rows: []Row{
+					{State: "Locked", Statuses: []Status{StatusInherited, StatusHandled, StatusHandled}},
+					{State: "Alarming", Statuses: []Status{StatusInherited, StatusUnhandled, StatusUnhandled}},
+					{State: "FirstCoin", Statuses: []Status{StatusInherited, StatusHandled, StatusHandled}},
+					{State: "Unlocked", Statuses: []Status{StatusInherited, StatusHandled, StatusHandled}},
+				},
+			},
+			buildMatrix(turnstile),
+		)
+	})
+
+	t.Run("Regions", func(t *testing.T) {
+		matrix := buildMatrix("FSM: f Initial: a { a { [r] { b { e c - } \n c { } } \n f a - } }")
+		assert.Equal(t,
+			[]Row{
+				{State: "a", Statuses: []Status{StatusHandled, StatusInherited}},
+				{State: "b", Statuses: []Status{StatusIgnored, StatusHandled}},
+				{State: "c", Statuses: []Status{StatusIgnored, StatusUnhandled}},
+			},
+			matrix.Rows,
+		)
+	})
+
+	t.Run("Final states", func(t *testing.T) {
+		semanticFSM := analyzeFSM("FSM: f Initial: a Final: c { a { e b - \n f c - } \n b e a - \n c - - - }")
+		assert.Empty(t, semanticFSM.Errors)
+
+		matrix := NewBuilder().Build(optimizer.New().Optimize(semanticFSM))
+		assert.Equal(t,
+			[]Row{
+				{State: "a", Statuses: []Status{StatusHandled, StatusHandled}},
+				{State: "b", Statuses: []Status{StatusHandled, StatusUnhandled}},
+				{State: "c", Statuses: []Status{StatusFinal, StatusFinal}},
+			},
+			matrix.Rows,
+		)
+	})
+
+	t.Run("Unhandled events", func(t *testing.T) {
+		assert.True(t, buildMatrix(turnstile).HasUnhandled())
+		assert.False(t, buildMatrix("FSM: f Initial: a { a e b - \n b e a - }").HasUnhandled())
+		assert.False(t, buildMatrix("FSM: f Initial: a Final: b { a e b - \n b - - - }").HasUnhandled())
+	})
+
+	t.Run("Text format", func(t *testing.T) {
+		assertWrittenMatrix(t, FormatText, ""+
+			"State      Reset      Pass       Coin\n"+
+			"Locked     inherited  handled    handled\n"+
+			"Alarming   inherited  unhandled  unhandled\n"+
+			"FirstCoin  inherited  handled    handled\n"+
+			"Unlocked   inherited  handled    handled\n",
+		)
+	})
+
+	t.Run("Markdown format", func(t *testing.T) {
+		assertWrittenMatrix(t, FormatMarkdown, ""+
+			"| State | Reset | Pass | Coin |\n"+
+			"| --- | --- | --- | --- |\n"+
+			"| Locked | inherited | handled | handled |\n"+
+			"| Alarming | inherited | unhandled | unhandled |\n"+
+			"| FirstCoin | inherited | handled | handled |\n"+
+			"| Unlocked | inherited | handled | handled |\n",
+		)
+	})
+
+	t.Run("CSV format", func(t *testing.T) {
+		assertWrittenMatrix(t, FormatCSV, ""+
+			"State,Reset,Pass,Coin\n"+
+			"Locked,inherited,handled,handled\n"+
+			"Alarming,inherited,unhandled,unhandled\n"+
+			"FirstCoin,inherited,handled,handled\n"+
+			"Unlocked,inherited,handled,handled\n",
+		)
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		assert.NotNil(t, buildMatrix(turnstile).Write(&bytes.Buffer{}, Format("xml")))
+	})
+}
+
+func assertWrittenMatrix(t *testing.T, format Format, expected string) {
+	t.Helper()
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, buildMatrix(turnstile).Write(buffer, format))
+	assert.Equal(t, expected, buffer.String())
+}
+
+func buildMatrix(input string) *Matrix {
+	opt := optimizer.New()
+	return NewBuilder().Build(opt.Optimize(analyzeFSM(input)))
+}
+
+func analyzeFSM(input string) *semantic.FSM {
+	builder := parser.NewSyntaxBuilder()
+	psr := parser.NewParser(builder)
+	lxr := lexer.NewLexer(psr)
+	lxr.Lex(bytes.NewBufferString(input))
+
+	analyzer := semantic.NewAnalyzer()
+	return analyzer.Analyze(builder.FSM())
+}
@@ -0,0 +1,76 @@
+package coverage
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+type Format string
+
+const (
+	FormatText     Format = "text"
+	FormatMarkdown Format = "markdown"
+	FormatCSV      Format = "csv"
+)
+
+func (m *Matrix) Write(w io.Writer, format Format) error {
+	switch format {
+	case FormatText:
+		return m.writeText(w)
+	case FormatMarkdown:
+		return m.writeMarkdown(w)
+	case FormatCSV:
+		return m.writeCSV(w)
+	default:
+		return fmt.Errorf("unknown coverage format: %s", format)
+	}
+}
+
+func (m *Matrix) writeText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, record := range m.records() {
+		fmt.Fprintln(tw, strings.Join(record, "\t"))
+	}
+	return tw.Flush()
+}
+
+func (m *Matrix) writeMarkdown(w io.Writer) error {
+	records := m.records()
+	separator := []string{}
+	for range records[0] {
+		separator = append(separator, "---")
+	}
+
+	lines := [][]string{records[0], separator}
+	lines = append(lines, records[1:]...)
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(line, " | ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Matrix) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(m.records()); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func (m *Matrix) records() [][]string {
+	records := [][]string{append([]string{"State"}, m.Events...)}
+	for _, row := range m.Rows {
+		record := []string{row.State}
+		for _, status := range row.Statuses {
+			record = append(record, string(status))
+		}
+		records = append(records, record)
+	}
+	return records
+}
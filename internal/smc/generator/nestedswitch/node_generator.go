@@ -0,0 +1,248 @@
+package nestedswitch
+
+import "github.com/geisonbiazus/smc/internal/smc/optimizer"
+
+type NodeGenerator struct {
+	fsm *optimizer.FSM
+}
+
+func NewNodeGenerator() *NodeGenerator {
+	return &NodeGenerator{}
+}
+
+func (g *NodeGenerator) Generate(fsm *optimizer.FSM) Node {
+	g.fsm = fsm
+	return CompositeNode(
+		[]Node{
+			g.actionsInterfaceNode(),
+			EnumNode{TypeName: "State", Values: g.states(), HasNone: len(fsm.Regions) > 0},
+			EnumNode{TypeName: "Event", Values: g.events()},
+			g.fsmClassNode(),
+			g.dispatchNode(),
+		},
+	)
+}
+
+func (g *NodeGenerator) actionsInterfaceNode() Node {
+	return ActionsInterfaceNode{
+		Actions:          g.fsm.Actions,
+		Guards:           g.fsm.Guards,
+		ActionParameters: parameterMap(g.fsm.ActionParameters),
+	}
+}
+
+func (g *NodeGenerator) states() []string {
+	var states []string
+	for _, state := range g.fsm.States {
+		states = append(states, state.Name)
+	}
+	return states
+}
+
+func (g *NodeGenerator) events() []string {
+	events := append([]string{}, g.fsm.Events...)
+	for _, timer := range g.fsm.Timers {
+		events = append(events, timer.Event)
+	}
+	return events
+}
+
+func (g *NodeGenerator) fsmClassNode() Node {
+	return FSMClassNode{
+		ClassName:       g.fsm.Name,
+		InitialState:    g.fsm.InitialState,
+		FinalStates:     g.fsm.FinalStates,
+		HistoryStates:   g.fsm.HistoryStates,
+		StateHistories:  g.stateHistories(),
+		Regions:         g.regionNodes(),
+		InitialRegions:  g.fsm.InitialRegions,
+		Timers:          g.timerNodes(),
+		InitialTimers:   g.fsm.InitialTimers,
+		EventParameters: parameterMap(g.fsm.EventParameters),
+		EventMethods:    g.eventMethodNodes(),
+	}
+}
+
+func (g *NodeGenerator) stateHistories() []StateHistory {
+	var histories []StateHistory
+	for _, state := range g.fsm.States {
+		if len(state.HistoryStates) > 0 {
+			histories = append(histories, StateHistory{
+				StateName:     state.Name,
+				Region:        state.Region,
+				HistoryStates: state.HistoryStates,
+			})
+		}
+	}
+	return histories
+}
+
+func (g *NodeGenerator) regionNodes() []RegionNode {
+	var nodes []RegionNode
+	for _, region := range g.fsm.Regions {
+		node := RegionNode{
+			Name:          region.Name,
+			InitialState:  region.InitialState,
+			EntryActions:  region.EntryActions,
+			Timers:        region.Timers,
+			InitialTimers: region.InitialTimers,
+		}
+		for _, exit := range region.Exits {
+			node.Exits = append(node.Exits, RegionExit{StateName: exit.State, Actions: exit.Actions})
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+func (g *NodeGenerator) timerNodes() []TimerNode {
+	var nodes []TimerNode
+	for _, timer := range g.fsm.Timers {
+		nodes = append(nodes, TimerNode{Event: timer.Event, Region: timer.Region, Duration: timer.Duration})
+	}
+	return nodes
+}
+
+func (g *NodeGenerator) eventMethodNodes() []Node {
+	nodes := []Node{}
+	for _, event := range g.fsm.Events {
+		nodes = append(nodes, EventMethodNode{
+			ClassName:      g.fsm.Name,
+			EventName:      event,
+			Parameters:     g.eventParameters(event),
+			Regions:        g.eventRegions(event),
+			RecordsHistory: len(g.fsm.HistoryStates) > 0,
+		})
+	}
+	return nodes
+}
+
+func (g *NodeGenerator) eventRegions(event string) []string {
+	var regions []string
+	for _, region := range g.fsm.Regions {
+		for _, e := range region.Events {
+			if e == event {
+				regions = append(regions, region.Name)
+			}
+		}
+	}
+	return regions
+}
+
+func (g *NodeGenerator) dispatchNode() Node {
+	node := DispatchNode{
+		ClassName:     g.fsm.Name,
+		HasParameters: len(g.fsm.EventParameters) > 0,
+		States:        []Node{},
+	}
+	for _, state := range g.fsm.States {
+		if len(handledTransitions(state.Transitions)) > 0 {
+			node.States = append(node.States, g.stateCaseNode(state))
+		}
+	}
+	return node
+}
+
+func (g *NodeGenerator) stateCaseNode(state *optimizer.State) Node {
+	node := StateCaseNode{StateName: state.Name, Events: []Node{}}
+	for _, transitions := range groupByEvent(handledTransitions(state.Transitions)) {
+		event := transitions[0].Event
+		node.Events = append(node.Events, EventCaseNode{
+			EventName:    event,
+			Region:       state.Region,
+			Parameters:   g.eventParameters(event),
+			Alternatives: alternatives(transitions),
+		})
+	}
+	return node
+}
+
+func handledTransitions(transitions []*optimizer.Transition) []*optimizer.Transition {
+	result := []*optimizer.Transition{}
+	for _, transition := range transitions {
+		if !transition.Delegated {
+			result = append(result, transition)
+		}
+	}
+	return result
+}
+
+func groupByEvent(transitions []*optimizer.Transition) [][]*optimizer.Transition {
+	groups := [][]*optimizer.Transition{}
+	index := map[string]int{}
+
+	for _, transition := range transitions {
+		i, ok := index[transition.Event]
+		if !ok {
+			i = len(groups)
+			index[transition.Event] = i
+			groups = append(groups, []*optimizer.Transition{})
+		}
+		groups[i] = append(groups[i], transition)
+	}
+	return groups
+}
+
+func alternatives(transitions []*optimizer.Transition) []Transition {
+	var result []Transition
+	for _, transition := range transitions {
+		result = append(result, Transition{
+			Guard:          transition.Guard,
+			NextState:      transition.NextState,
+			Actions:        transition.Actions,
+			History:        transition.History,
+			HistoryTargets: historyTargets(transition),
+			ExitedRegions:  transition.ExitedRegions,
+			EnteredRegions: transition.EnteredRegions,
+			StoppedTimers:  transition.StoppedTimers,
+			StartedTimers:  transition.StartedTimers,
+			Branches:       alternatives(transition.Branches),
+		})
+		if transition.Guard == "" {
+			break
+		}
+	}
+	return result
+}
+
+func historyTargets(transition *optimizer.Transition) []HistoryTarget {
+	var targets []HistoryTarget
+	for _, target := range transition.HistoryTargets {
+		targets = append(targets, HistoryTarget{
+			Memory: target.Memory,
+			Transition: Transition{
+				NextState:      target.NextState,
+				Actions:        target.Actions,
+				ExitedRegions:  target.ExitedRegions,
+				EnteredRegions: target.EnteredRegions,
+				StoppedTimers:  target.StoppedTimers,
+				StartedTimers:  target.StartedTimers,
+			},
+		})
+	}
+	return targets
+}
+
+func (g *NodeGenerator) eventParameters(event string) []Parameter {
+	return parameterList(g.fsm.EventParameters[event])
+}
+
+func parameterMap(parameters map[string][]optimizer.Parameter) map[string][]Parameter {
+	if parameters == nil {
+		return nil
+	}
+
+	result := map[string][]Parameter{}
+	for name, list := range parameters {
+		result[name] = parameterList(list)
+	}
+	return result
+}
+
+func parameterList(parameters []optimizer.Parameter) []Parameter {
+	var result []Parameter
+	for _, p := range parameters {
+		result = append(result, Parameter{Name: p.Name, Type: p.Type})
+	}
+	return result
+}
@@ -0,0 +1,125 @@
+package nestedswitch
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/geisonbiazus/smc/internal/smc/lexer"
+	"github.com/geisonbiazus/smc/internal/smc/optimizer"
+	"github.com/geisonbiazus/smc/internal/smc/parser"
+	"github.com/geisonbiazus/smc/internal/smc/semantic"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeGenerator(t *testing.T) {
+	t.Run("Single state", func(t *testing.T) {
+		assertGeneratedFSM(t,
+			"FSM: fsm Initial: a { a b a c }",
+			CompositeNode([]Node{
+				ActionsInterfaceNode{
+					Actions: []string{"c"},
+				},
+				EnumNode{TypeName: "State", Values: []string{"a"}},
+				EnumNode{TypeName: "Event", Values: []string{"b"}},
+				FSMClassNode{
+					ClassName:    "fsm",
+					InitialState: "a",
+					EventMethods: []Node{
+						EventMethodNode{ClassName: "fsm", EventName: "b"},
+					},
+				},
+				DispatchNode{
+					ClassName: "fsm",
+					States: []Node{
+						StateCaseNode{
+							StateName: "a",
+							Events: []Node{
+								EventCaseNode{
+									EventName:    "b",
+									Alternatives: []Transition{{NextState: "a", Actions: []string{"c"}}},
+								},
+							},
+						},
+					},
+				},
+			}),
+		)
+	})
+
+	t.Run("Guarded alternatives and parameters", func(t *testing.T) {
+		node := generateFSM("FSM: fsm Initial: a Event: b(n int) { a { b [g] a c \n b - d \n b [h] a - } }")
+		dispatch := node.(CompositeNode)[4].(DispatchNode)
+
+		assert.True(t, dispatch.HasParameters)
+		assert.Equal(t,
+			[]Node{
+				StateCaseNode{
+					StateName: "a",
+					Events: []Node{
+						EventCaseNode{
+							EventName:  "b",
+							Parameters: []Parameter{{Name: "n", Type: "int"}},
+							Alternatives: []Transition{
+								{Guard: "g", NextState: "a", Actions: []string{"c"}},
+								{Actions: []string{"d"}},
+							},
+						},
+					},
+				},
+			},
+			dispatch.States,
+		)
+	})
+
+	t.Run("States without transitions have no case", func(t *testing.T) {
+		node := generateFSM("FSM: fsm Initial: a Final: b { a e b - \n b - - - }")
+		dispatch := node.(CompositeNode)[4].(DispatchNode)
+
+		assert.Len(t, dispatch.States, 1)
+		assert.Equal(t, []string{"b"}, node.(CompositeNode)[3].(FSMClassNode).FinalStates)
+	})
+
+	t.Run("Regions reserve a state for inactive regions", func(t *testing.T) {
+		node := generateFSM("FSM: fsm Initial: a { a { e b - } b { [r] { x { after 1s y - } y { f x - } } e a - } }")
+		fsm := node.(CompositeNode)[3].(FSMClassNode)
+
+		assert.Equal(t, EnumNode{TypeName: "State", Values: []string{"a", "b", "x", "y"}, HasNone: true}, node.(CompositeNode)[1])
+		assert.Equal(t, EnumNode{TypeName: "Event", Values: []string{"e", "f", "xAfter1s"}}, node.(CompositeNode)[2])
+		assert.Equal(t, []TimerNode{{Event: "xAfter1s", Region: "r", Duration: time.Second}}, fsm.Timers)
+		assert.Equal(t,
+			[]Node{
+				EventMethodNode{ClassName: "fsm", EventName: "e"},
+				EventMethodNode{ClassName: "fsm", EventName: "f", Regions: []string{"r"}},
+			},
+			fsm.EventMethods,
+		)
+
+		events := node.(CompositeNode)[4].(DispatchNode).States[1].(StateCaseNode).Events
+		assert.Len(t, events, 1)
+		assert.Equal(t, "e", events[0].(EventCaseNode).EventName)
+	})
+}
+
+func assertGeneratedFSM(t *testing.T, input string, expected Node) {
+	node := generateFSM(input)
+	assert.Equal(t, expected, node)
+}
+
+func generateFSM(input string) Node {
+	builder := parser.NewSyntaxBuilder()
+	psr := parser.NewParser(builder)
+	lxr := lexer.NewLexer(psr)
+	lxr.Lex(bytes.NewBufferString(input))
+
+	parsedFSM := builder.FSM()
+
+	analyzer := semantic.NewAnalyzer()
+	semanticFSM := analyzer.Analyze(parsedFSM)
+
+	opt := optimizer.New()
+	optimizedFSM := opt.Optimize(semanticFSM)
+
+	gen := NewNodeGenerator()
+	return gen.Generate(optimizedFSM)
+}
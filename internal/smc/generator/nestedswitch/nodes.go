@@ -0,0 +1,154 @@
+package nestedswitch
+
+import "time"
+
+type Visitor interface {
+	VisitActionsInterfaceNode(node ActionsInterfaceNode)
+	VisitEnumNode(node EnumNode)
+	VisitFSMClassNode(node FSMClassNode)
+	VisitEventMethodNode(node EventMethodNode)
+	VisitDispatchNode(node DispatchNode)
+	VisitStateCaseNode(node StateCaseNode)
+	VisitEventCaseNode(node EventCaseNode)
+}
+
+type Node interface {
+	Accept(v Visitor)
+}
+
+type CompositeNode []Node
+
+func (n CompositeNode) Accept(v Visitor) {
+	for _, node := range n {
+		node.Accept(v)
+	}
+}
+
+type Parameter struct {
+	Name string
+	Type string
+}
+
+type ActionsInterfaceNode struct {
+	Actions          []string
+	Guards           []string
+	ActionParameters map[string][]Parameter
+}
+
+func (n ActionsInterfaceNode) Accept(v Visitor) {
+	v.VisitActionsInterfaceNode(n)
+}
+
+type EnumNode struct {
+	TypeName string
+	Values   []string
+	HasNone  bool
+}
+
+func (n EnumNode) Accept(v Visitor) {
+	v.VisitEnumNode(n)
+}
+
+type FSMClassNode struct {
+	ClassName       string
+	InitialState    string
+	FinalStates     []string
+	HistoryStates   []string
+	StateHistories  []StateHistory
+	Regions         []RegionNode
+	InitialRegions  []string
+	Timers          []TimerNode
+	InitialTimers   []string
+	EventParameters map[string][]Parameter
+	EventMethods    []Node
+}
+
+func (n FSMClassNode) Accept(v Visitor) {
+	v.VisitFSMClassNode(n)
+}
+
+type StateHistory struct {
+	StateName     string
+	Region        string
+	HistoryStates []string
+}
+
+type RegionNode struct {
+	Name          string
+	InitialState  string
+	EntryActions  []string
+	Exits         []RegionExit
+	Timers        []string
+	InitialTimers []string
+}
+
+type RegionExit struct {
+	StateName string
+	Actions   []string
+}
+
+type TimerNode struct {
+	Event    string
+	Region   string
+	Duration time.Duration
+}
+
+type EventMethodNode struct {
+	ClassName      string
+	EventName      string
+	Parameters     []Parameter
+	Regions        []string
+	RecordsHistory bool
+}
+
+func (n EventMethodNode) Accept(v Visitor) {
+	v.VisitEventMethodNode(n)
+}
+
+type DispatchNode struct {
+	ClassName     string
+	HasParameters bool
+	States        []Node
+}
+
+func (n DispatchNode) Accept(v Visitor) {
+	v.VisitDispatchNode(n)
+}
+
+type StateCaseNode struct {
+	StateName string
+	Events    []Node
+}
+
+func (n StateCaseNode) Accept(v Visitor) {
+	v.VisitStateCaseNode(n)
+}
+
+type EventCaseNode struct {
+	EventName    string
+	Region       string
+	Parameters   []Parameter
+	Alternatives []Transition
+}
+
+func (n EventCaseNode) Accept(v Visitor) {
+	v.VisitEventCaseNode(n)
+}
+
+type Transition struct {
+	Guard          string
+	NextState      string
+	Actions        []string
+	History        string
+	HistoryTargets []HistoryTarget
+	ExitedRegions  []string
+	EnteredRegions []string
+	StoppedTimers  []string
+	StartedTimers  []string
+	Branches       []Transition
+}
+
+type HistoryTarget struct {
+	Memory string
+	Transition
+}
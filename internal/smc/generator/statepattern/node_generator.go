@@ -25,38 +25,136 @@ func (g *NodeGenerator) Generate(fsm *optimizer.FSM) Node {
 
 func (g *NodeGenerator) stateInterfaceNode() Node {
 	return StateInterfaceNode{
-		FSMClassName: g.fsm.Name,
-		Events:       g.fsm.Events,
+		FSMClassName:    g.fsm.Name,
+		Events:          g.stateEvents(),
+		EventParameters: parameterMap(g.fsm.EventParameters),
+		RegionEvents:    g.regionEvents(),
 	}
 }
 
 func (g *NodeGenerator) fsmClassNode() Node {
 	return FSMClassNode{
-		ClassName:    g.fsm.Name,
-		InitialState: g.fsm.InitialState,
-		EventMethods: g.eventMethodNodes(),
+		ClassName:      g.fsm.Name,
+		InitialState:   g.fsm.InitialState,
+		FinalStates:    g.fsm.FinalStates,
+		HistoryStates:  g.fsm.HistoryStates,
+		StateHistories: g.stateHistories(),
+		Regions:        g.regionNodes(),
+		InitialRegions: g.fsm.InitialRegions,
+		Timers:         g.timerNodes(),
+		InitialTimers:  g.fsm.InitialTimers,
+		EventMethods:   g.eventMethodNodes(),
+	}
+}
+
+func (g *NodeGenerator) stateEvents() []string {
+	if len(g.fsm.Timers) == 0 {
+		return g.fsm.Events
+	}
+
+	events := append([]string{}, g.fsm.Events...)
+	for _, timer := range g.fsm.Timers {
+		events = append(events, timer.Event)
+	}
+	return events
+}
+
+func (g *NodeGenerator) timerNodes() []TimerNode {
+	var nodes []TimerNode
+	for _, timer := range g.fsm.Timers {
+		nodes = append(nodes, TimerNode{Event: timer.Event, Region: timer.Region, Duration: timer.Duration})
+	}
+	return nodes
+}
+
+func (g *NodeGenerator) regionNodes() []RegionNode {
+	var nodes []RegionNode
+	for _, region := range g.fsm.Regions {
+		node := RegionNode{
+			Name:          region.Name,
+			InitialState:  region.InitialState,
+			EntryActions:  region.EntryActions,
+			Timers:        region.Timers,
+			InitialTimers: region.InitialTimers,
+		}
+		for _, exit := range region.Exits {
+			node.Exits = append(node.Exits, RegionExit{StateName: exit.State, Actions: exit.Actions})
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+func (g *NodeGenerator) eventRegions(event string) []string {
+	var regions []string
+	for _, region := range g.fsm.Regions {
+		for _, e := range region.Events {
+			if e == event {
+				regions = append(regions, region.Name)
+			}
+		}
+	}
+	return regions
+}
+
+func (g *NodeGenerator) regionEvents() []string {
+	var events []string
+	for _, event := range g.fsm.Events {
+		if g.isRegionEvent(event) {
+			events = append(events, event)
+		}
 	}
+	return events
+}
+
+func (g *NodeGenerator) isRegionEvent(event string) bool {
+	return len(g.eventRegions(event)) > 0
 }
 
 func (g *NodeGenerator) eventMethodNodes() []Node {
 	nodes := []Node{}
 	for _, event := range g.fsm.Events {
-		eventNode := EventMethodNode{ClassName: g.fsm.Name, EventName: event}
+		eventNode := EventMethodNode{
+			ClassName:      g.fsm.Name,
+			EventName:      event,
+			Parameters:     g.eventParameters(event),
+			Regions:        g.eventRegions(event),
+			RecordsHistory: len(g.fsm.HistoryStates) > 0,
+			Synchronized:   len(g.fsm.Timers) > 0,
+		}
 		nodes = append(nodes, eventNode)
 	}
 	return nodes
 }
 
+func (g *NodeGenerator) stateHistories() []StateHistory {
+	var histories []StateHistory
+	for _, state := range g.fsm.States {
+		if len(state.HistoryStates) > 0 {
+			histories = append(histories, StateHistory{
+				StateName:     state.Name,
+				Region:        state.Region,
+				HistoryStates: state.HistoryStates,
+			})
+		}
+	}
+	return histories
+}
+
 func (g *NodeGenerator) actionsInterfaceNode() Node {
 	return ActionsInterfaceNode{
-		Actions: g.fsm.Actions,
+		Actions:          g.fsm.Actions,
+		Guards:           g.fsm.Guards,
+		ActionParameters: parameterMap(g.fsm.ActionParameters),
 	}
 }
 
 func (g *NodeGenerator) baseStateClassNode() Node {
 	return BaseStateClassNode{
-		FSMClassName: g.fsm.Name,
-		Events:       g.fsm.Events,
+		FSMClassName:    g.fsm.Name,
+		Events:          g.stateEvents(),
+		EventParameters: parameterMap(g.fsm.EventParameters),
+		RegionEvents:    g.regionEvents(),
 	}
 }
 
@@ -83,20 +181,143 @@ func (g *NodeGenerator) stateClassNode(state *optimizer.State) Node {
 
 func (g *NodeGenerator) stateEventMethodNodes(state *optimizer.State) []Node {
 	nodes := []Node{}
-	for _, transition := range state.Transitions {
-		nodes = append(nodes, g.stateEventMethodNode(state, transition))
+	for _, transitions := range groupByEvent(handledTransitions(state.Transitions)) {
+		nodes = append(nodes, g.stateEventMethodNodeForEvent(state, transitions))
 	}
 	return nodes
 }
 
+func (g *NodeGenerator) stateEventMethodNodeForEvent(
+	state *optimizer.State, transitions []*optimizer.Transition,
+) Node {
+	if len(transitions) == 1 && transitions[0].Guard == "" {
+		return g.stateEventMethodNode(state, transitions[0])
+	}
+	return g.guardedStateEventMethodNode(state, transitions)
+}
+
+func (g *NodeGenerator) guardedStateEventMethodNode(
+	state *optimizer.State, transitions []*optimizer.Transition,
+) Node {
+	node := GuardedStateEventMethodNode{
+		FSMClassName: g.fsm.Name,
+		StateName:    state.Name,
+		EventName:    transitions[0].Event,
+		Parameters:   g.eventParameters(transitions[0].Event),
+		Region:       state.Region,
+		RegionEvent:  g.isRegionEvent(transitions[0].Event),
+	}
+
+	node.Alternatives = alternatives(transitions)
+	return node
+}
+
+func alternatives(transitions []*optimizer.Transition) []TransitionAlternative {
+	var result []TransitionAlternative
+	for _, transition := range transitions {
+		result = append(result, TransitionAlternative{
+			Guard:          transition.Guard,
+			NextState:      transition.NextState,
+			Actions:        transition.Actions,
+			History:        transition.History,
+			HistoryTargets: historyTargets(transition),
+			ExitedRegions:  transition.ExitedRegions,
+			EnteredRegions: transition.EnteredRegions,
+			StoppedTimers:  transition.StoppedTimers,
+			StartedTimers:  transition.StartedTimers,
+			Branches:       alternatives(transition.Branches),
+		})
+		if transition.Guard == "" {
+			break
+		}
+	}
+	return result
+}
+
+func handledTransitions(transitions []*optimizer.Transition) []*optimizer.Transition {
+	result := []*optimizer.Transition{}
+	for _, transition := range transitions {
+		if !transition.Delegated {
+			result = append(result, transition)
+		}
+	}
+	return result
+}
+
+func groupByEvent(transitions []*optimizer.Transition) [][]*optimizer.Transition {
+	groups := [][]*optimizer.Transition{}
+	index := map[string]int{}
+
+	for _, transition := range transitions {
+		i, ok := index[transition.Event]
+		if !ok {
+			i = len(groups)
+			index[transition.Event] = i
+			groups = append(groups, []*optimizer.Transition{})
+		}
+		groups[i] = append(groups[i], transition)
+	}
+	return groups
+}
+
 func (g *NodeGenerator) stateEventMethodNode(
 	state *optimizer.State, transition *optimizer.Transition,
 ) Node {
 	return StateEventMethodNode{
-		FSMClassName: g.fsm.Name,
-		StateName:    state.Name,
-		EventName:    transition.Event,
-		NextState:    transition.NextState,
-		Actions:      transition.Actions,
+		FSMClassName:   g.fsm.Name,
+		StateName:      state.Name,
+		EventName:      transition.Event,
+		Parameters:     g.eventParameters(transition.Event),
+		Region:         state.Region,
+		RegionEvent:    g.isRegionEvent(transition.Event),
+		NextState:      transition.NextState,
+		Actions:        transition.Actions,
+		History:        transition.History,
+		HistoryTargets: historyTargets(transition),
+		ExitedRegions:  transition.ExitedRegions,
+		EnteredRegions: transition.EnteredRegions,
+		StoppedTimers:  transition.StoppedTimers,
+		StartedTimers:  transition.StartedTimers,
+		Branches:       alternatives(transition.Branches),
+	}
+}
+
+func historyTargets(transition *optimizer.Transition) []HistoryTarget {
+	var targets []HistoryTarget
+	for _, target := range transition.HistoryTargets {
+		targets = append(targets, HistoryTarget{
+			Memory:         target.Memory,
+			NextState:      target.NextState,
+			Actions:        target.Actions,
+			ExitedRegions:  target.ExitedRegions,
+			EnteredRegions: target.EnteredRegions,
+			StoppedTimers:  target.StoppedTimers,
+			StartedTimers:  target.StartedTimers,
+		})
+	}
+	return targets
+}
+
+func (g *NodeGenerator) eventParameters(event string) []Parameter {
+	return parameterList(g.fsm.EventParameters[event])
+}
+
+func parameterMap(parameters map[string][]optimizer.Parameter) map[string][]Parameter {
+	if parameters == nil {
+		return nil
+	}
+
+	result := map[string][]Parameter{}
+	for name, list := range parameters {
+		result[name] = parameterList(list)
+	}
+	return result
+}
+
+func parameterList(parameters []optimizer.Parameter) []Parameter {
+	var result []Parameter
+	for _, p := range parameters {
+		result = append(result, Parameter{Name: p.Name, Type: p.Type})
 	}
+	return result
 }
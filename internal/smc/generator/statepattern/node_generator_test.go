@@ -3,6 +3,7 @@ package statepattern
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	"github.com/geisonbiazus/smc/internal/smc/lexer"
 	"github.com/geisonbiazus/smc/internal/smc/optimizer"
@@ -52,6 +53,449 @@ func TestNodeGenerator(t *testing.T) {
 		)
 	})
 
+	t.Run("Guarded transitions", func(t *testing.T) {
+		assertGeneratedFSM(t,
+			"FSM: fsm Initial: a { (s) b a c \n a:s { b [g] a d \n b [h] - - \n e [g] a - \n e - - \n e [h] a - } }",
+			CompositeNode([]Node{
+				StateInterfaceNode{
+					FSMClassName: "fsm",
+					Events:       []string{"b", "e"},
+				},
+				ActionsInterfaceNode{
+					Actions: []string{"c", "d"},
+					Guards:  []string{"g", "h"},
+				},
+				FSMClassNode{
+					ClassName:    "fsm",
+					InitialState: "a",
+					EventMethods: []Node{
+						EventMethodNode{ClassName: "fsm", EventName: "b"},
+						EventMethodNode{ClassName: "fsm", EventName: "e"},
+					},
+				},
+				BaseStateClassNode{
+					FSMClassName: "fsm",
+					Events:       []string{"b", "e"},
+				},
+				CompositeNode([]Node{
+					StateClassNode{
+						StateName: "a",
+						StateEventMethods: []Node{
+							GuardedStateEventMethodNode{
+								FSMClassName: "fsm",
+								StateName:    "a",
+								EventName:    "b",
+								Alternatives: []TransitionAlternative{
+									{Guard: "g", NextState: "a", Actions: []string{"d"}},
+									{Guard: "h", NextState: "", Actions: []string{}},
+									{Guard: "", NextState: "a", Actions: []string{"c"}},
+								},
+							},
+							GuardedStateEventMethodNode{
+								FSMClassName: "fsm",
+								StateName:    "a",
+								EventName:    "e",
+								Alternatives: []TransitionAlternative{
+									{Guard: "g", NextState: "a", Actions: []string{}},
+									{Guard: "", NextState: "", Actions: []string{}},
+								},
+							},
+						},
+					},
+				}),
+			}),
+		)
+	})
+
+	t.Run("Event parameters", func(t *testing.T) {
+		parameters := []Parameter{{Name: "d", Type: "int"}}
+		assertGeneratedFSM(t,
+			"FSM: fsm Initial: a Event: b(d int) { a { b a c \n e - - } }",
+			CompositeNode([]Node{
+				StateInterfaceNode{
+					FSMClassName:    "fsm",
+					Events:          []string{"b", "e"},
+					EventParameters: map[string][]Parameter{"b": parameters},
+				},
+				ActionsInterfaceNode{
+					Actions:          []string{"c"},
+					ActionParameters: map[string][]Parameter{"c": parameters},
+				},
+				FSMClassNode{
+					ClassName:    "fsm",
+					InitialState: "a",
+					EventMethods: []Node{
+						EventMethodNode{ClassName: "fsm", EventName: "b", Parameters: parameters},
+						EventMethodNode{ClassName: "fsm", EventName: "e"},
+					},
+				},
+				BaseStateClassNode{
+					FSMClassName:    "fsm",
+					Events:          []string{"b", "e"},
+					EventParameters: map[string][]Parameter{"b": parameters},
+				},
+				CompositeNode([]Node{
+					StateClassNode{
+						StateName: "a",
+						StateEventMethods: []Node{
+							StateEventMethodNode{
+								FSMClassName: "fsm",
+								StateName:    "a",
+								EventName:    "b",
+								Parameters:   parameters,
+								NextState:    "a",
+								Actions:      []string{"c"},
+							},
+							StateEventMethodNode{
+								FSMClassName: "fsm",
+								StateName:    "a",
+								EventName:    "e",
+								NextState:    "",
+								Actions:      []string{},
+							},
+						},
+					},
+				}),
+			}),
+		)
+	})
+
+	t.Run("History targets", func(t *testing.T) {
+		assertGeneratedFSM(t,
+			"FSM: fsm Initial: a { a { b { e c - } \n c { e d - } } \n d f a(H) x }",
+			CompositeNode([]Node{
+				StateInterfaceNode{
+					FSMClassName: "fsm",
+					Events:       []string{"e", "f"},
+				},
+				ActionsInterfaceNode{
+					Actions: []string{"x"},
+				},
+				FSMClassNode{
+					ClassName:     "fsm",
+					InitialState:  "b",
+					HistoryStates: []string{"a"},
+					StateHistories: []StateHistory{
+						{StateName: "b", HistoryStates: []string{"a"}},
+						{StateName: "c", HistoryStates: []string{"a"}},
+					},
+					EventMethods: []Node{
+						EventMethodNode{ClassName: "fsm", EventName: "e", RecordsHistory: true},
+						EventMethodNode{ClassName: "fsm", EventName: "f", RecordsHistory: true},
+					},
+				},
+				BaseStateClassNode{
+					FSMClassName: "fsm",
+					Events:       []string{"e", "f"},
+				},
+				CompositeNode([]Node{
+					StateClassNode{
+						StateName: "b",
+						StateEventMethods: []Node{
+							StateEventMethodNode{
+								FSMClassName: "fsm",
+								StateName:    "b",
+								EventName:    "e",
+								NextState:    "c",
+								Actions:      []string{},
+							},
+						},
+					},
+					StateClassNode{
+						StateName: "c",
+						StateEventMethods: []Node{
+							StateEventMethodNode{
+								FSMClassName: "fsm",
+								StateName:    "c",
+								EventName:    "e",
+								NextState:    "d",
+								Actions:      []string{},
+							},
+						},
+					},
+					StateClassNode{
+						StateName: "d",
+						StateEventMethods: []Node{
+							StateEventMethodNode{
+								FSMClassName: "fsm",
+								StateName:    "d",
+								EventName:    "f",
+								NextState:    "b",
+								Actions:      []string{"x"},
+								History:      "a",
+								HistoryTargets: []HistoryTarget{
+									{Memory: "b", NextState: "b", Actions: []string{"x"}},
+									{Memory: "c", NextState: "c", Actions: []string{"x"}},
+								},
+							},
+						},
+					},
+				}),
+			}),
+		)
+	})
+
+	t.Run("Regions", func(t *testing.T) {
+		assertGeneratedFSM(t,
+			"FSM: fsm Initial: a { a { [r] { b >x { e c - } \n c <y { e b - } } \n f d - } \n d f a - }",
+			CompositeNode([]Node{
+				StateInterfaceNode{
+					FSMClassName: "fsm",
+					Events:       []string{"f", "e"},
+					RegionEvents: []string{"e"},
+				},
+				ActionsInterfaceNode{
+					Actions: []string{"x", "y"},
+				},
+				FSMClassNode{
+					ClassName:    "fsm",
+					InitialState: "a",
+					Regions: []RegionNode{
+						{
+							Name:         "r",
+							InitialState: "b",
+							EntryActions: []string{"x"},
+							Exits:        []RegionExit{{StateName: "c", Actions: []string{"y"}}},
+						},
+					},
+					InitialRegions: []string{"r"},
+					EventMethods: []Node{
+						EventMethodNode{ClassName: "fsm", EventName: "f"},
+						EventMethodNode{ClassName: "fsm", EventName: "e", Regions: []string{"r"}},
+					},
+				},
+				BaseStateClassNode{
+					FSMClassName: "fsm",
+					Events:       []string{"f", "e"},
+					RegionEvents: []string{"e"},
+				},
+				CompositeNode([]Node{
+					StateClassNode{
+						StateName: "a",
+						StateEventMethods: []Node{
+							StateEventMethodNode{
+								FSMClassName:  "fsm",
+								StateName:     "a",
+								EventName:     "f",
+								NextState:     "d",
+								Actions:       []string{},
+								ExitedRegions: []string{"r"},
+							},
+						},
+					},
+					StateClassNode{
+						StateName: "b",
+						StateEventMethods: []Node{
+							StateEventMethodNode{
+								FSMClassName: "fsm",
+								StateName:    "b",
+								EventName:    "e",
+								Region:       "r",
+								RegionEvent:  true,
+								NextState:    "c",
+								Actions:      []string{},
+							},
+						},
+					},
+					StateClassNode{
+						StateName: "c",
+						StateEventMethods: []Node{
+							StateEventMethodNode{
+								FSMClassName: "fsm",
+								StateName:    "c",
+								EventName:    "e",
+								Region:       "r",
+								RegionEvent:  true,
+								NextState:    "b",
+								Actions:      []string{"y", "x"},
+							},
+						},
+					},
+					StateClassNode{
+						StateName: "d",
+						StateEventMethods: []Node{
+							StateEventMethodNode{
+								FSMClassName:   "fsm",
+								StateName:      "d",
+								EventName:      "f",
+								NextState:      "a",
+								Actions:        []string{},
+								EnteredRegions: []string{"r"},
+							},
+						},
+					},
+				}),
+			}),
+		)
+	})
+
+	t.Run("Final states", func(t *testing.T) {
+		assertGeneratedFSM(t,
+			"FSM: fsm Initial: a Final: b { a e b - \n b - - - }",
+			CompositeNode([]Node{
+				StateInterfaceNode{
+					FSMClassName: "fsm",
+					Events:       []string{"e"},
+				},
+				ActionsInterfaceNode{},
+				FSMClassNode{
+					ClassName:    "fsm",
+					InitialState: "a",
+					FinalStates:  []string{"b"},
+					EventMethods: []Node{
+						EventMethodNode{ClassName: "fsm", EventName: "e"},
+					},
+				},
+				BaseStateClassNode{
+					FSMClassName: "fsm",
+					Events:       []string{"e"},
+				},
+				CompositeNode([]Node{
+					StateClassNode{
+						StateName: "a",
+						StateEventMethods: []Node{
+							StateEventMethodNode{
+								FSMClassName: "fsm",
+								StateName:    "a",
+								EventName:    "e",
+								NextState:    "b",
+								Actions:      []string{},
+							},
+						},
+					},
+					StateClassNode{
+						StateName:         "b",
+						StateEventMethods: []Node{},
+					},
+				}),
+			}),
+		)
+	})
+
+	t.Run("Timeouts", func(t *testing.T) {
+		assertGeneratedFSM(t,
+			"FSM: fsm Initial: a { a { after 5s b x \n e b - } \n b e a - }",
+			CompositeNode([]Node{
+				StateInterfaceNode{
+					FSMClassName: "fsm",
+					Events:       []string{"e", "aAfter5s"},
+				},
+				ActionsInterfaceNode{
+					Actions: []string{"x"},
+				},
+				FSMClassNode{
+					ClassName:     "fsm",
+					InitialState:  "a",
+					Timers:        []TimerNode{{Event: "aAfter5s", Duration: 5 * time.Second}},
+					InitialTimers: []string{"aAfter5s"},
+					EventMethods: []Node{
+						EventMethodNode{ClassName: "fsm", EventName: "e", Synchronized: true},
+					},
+				},
+				BaseStateClassNode{
+					FSMClassName: "fsm",
+					Events:       []string{"e", "aAfter5s"},
+				},
+				CompositeNode([]Node{
+					StateClassNode{
+						StateName: "a",
+						StateEventMethods: []Node{
+							StateEventMethodNode{
+								FSMClassName:  "fsm",
+								StateName:     "a",
+								EventName:     "aAfter5s",
+								NextState:     "b",
+								Actions:       []string{"x"},
+								StoppedTimers: []string{"aAfter5s"},
+							},
+							StateEventMethodNode{
+								FSMClassName:  "fsm",
+								StateName:     "a",
+								EventName:     "e",
+								NextState:     "b",
+								Actions:       []string{},
+								StoppedTimers: []string{"aAfter5s"},
+							},
+						},
+					},
+					StateClassNode{
+						StateName: "b",
+						StateEventMethods: []Node{
+							StateEventMethodNode{
+								FSMClassName:  "fsm",
+								StateName:     "b",
+								EventName:     "e",
+								NextState:     "a",
+								Actions:       []string{},
+								StartedTimers: []string{"aAfter5s"},
+							},
+						},
+					},
+				}),
+			}),
+		)
+	})
+
+	t.Run("Choices", func(t *testing.T) {
+		assertGeneratedFSM(t,
+			"FSM: fsm Initial: a { a { e c x \n f [g] c - } \n c? { [h] b y \n a - } \n b - - - }",
+			CompositeNode([]Node{
+				StateInterfaceNode{
+					FSMClassName: "fsm",
+					Events:       []string{"e", "f"},
+				},
+				ActionsInterfaceNode{
+					Actions: []string{"x", "y"},
+					Guards:  []string{"g", "h"},
+				},
+				FSMClassNode{
+					ClassName:    "fsm",
+					InitialState: "a",
+					EventMethods: []Node{
+						EventMethodNode{ClassName: "fsm", EventName: "e"},
+						EventMethodNode{ClassName: "fsm", EventName: "f"},
+					},
+				},
+				BaseStateClassNode{
+					FSMClassName: "fsm",
+					Events:       []string{"e", "f"},
+				},
+				CompositeNode([]Node{
+					StateClassNode{
+						StateName: "a",
+						StateEventMethods: []Node{
+							StateEventMethodNode{
+								FSMClassName: "fsm",
+								StateName:    "a",
+								EventName:    "e",
+								Actions:      []string{"x"},
+								Branches: []TransitionAlternative{
+									{Guard: "h", NextState: "b", Actions: []string{"y"}},
+									{NextState: "a", Actions: []string{}},
+								},
+							},
+							GuardedStateEventMethodNode{
+								FSMClassName: "fsm",
+								StateName:    "a",
+								EventName:    "f",
+								Alternatives: []TransitionAlternative{
+									{Guard: "g", Actions: []string{}, Branches: []TransitionAlternative{
+										{Guard: "h", NextState: "b", Actions: []string{"y"}},
+										{NextState: "a", Actions: []string{}},
+									}},
+								},
+							},
+						},
+					},
+					StateClassNode{
+						StateName:         "b",
+						StateEventMethods: []Node{},
+					},
+				}),
+			}),
+		)
+	})
+
 	t.Run("Full FSM", func(t *testing.T) {
 		assertGeneratedFSM(t, `
 			FSM: TwoCoinTurnstile
@@ -1,5 +1,7 @@
 package statepattern
 
+import "time"
+
 type Visitor interface {
 	VisitStateInterfaceNode(node StateInterfaceNode)
 	VisitActionsInterfaceNode(node ActionsInterfaceNode)
@@ -8,6 +10,7 @@ type Visitor interface {
 	VisitBaseStateClassNode(node BaseStateClassNode)
 	VisitStateClassNode(node StateClassNode)
 	VisitStateEventMethodNode(node StateEventMethodNode)
+	VisitGuardedStateEventMethodNode(node GuardedStateEventMethodNode)
 }
 
 type Node interface {
@@ -22,9 +25,16 @@ func (n CompositeNode) Accept(v Visitor) {
 	}
 }
 
+type Parameter struct {
+	Name string
+	Type string
+}
+
 type StateInterfaceNode struct {
-	Events       []string
-	FSMClassName string
+	Events          []string
+	EventParameters map[string][]Parameter
+	RegionEvents    []string
+	FSMClassName    string
 }
 
 func (n StateInterfaceNode) Accept(v Visitor) {
@@ -32,7 +42,9 @@ func (n StateInterfaceNode) Accept(v Visitor) {
 }
 
 type ActionsInterfaceNode struct {
-	Actions []string
+	Actions          []string
+	Guards           []string
+	ActionParameters map[string][]Parameter
 }
 
 func (n ActionsInterfaceNode) Accept(v Visitor) {
@@ -40,10 +52,43 @@ func (n ActionsInterfaceNode) Accept(v Visitor) {
 }
 
 type FSMClassNode struct {
-	InitialState string
-	ClassName    string
-	ActionsClass string
-	EventMethods []Node
+	InitialState   string
+	FinalStates    []string
+	ClassName      string
+	ActionsClass   string
+	HistoryStates  []string
+	StateHistories []StateHistory
+	Regions        []RegionNode
+	InitialRegions []string
+	Timers         []TimerNode
+	InitialTimers  []string
+	EventMethods   []Node
+}
+
+type TimerNode struct {
+	Event    string
+	Region   string
+	Duration time.Duration
+}
+
+type StateHistory struct {
+	StateName     string
+	Region        string
+	HistoryStates []string
+}
+
+type RegionNode struct {
+	Name          string
+	InitialState  string
+	EntryActions  []string
+	Exits         []RegionExit
+	Timers        []string
+	InitialTimers []string
+}
+
+type RegionExit struct {
+	StateName string
+	Actions   []string
 }
 
 func (n FSMClassNode) Accept(v Visitor) {
@@ -51,8 +96,12 @@ func (n FSMClassNode) Accept(v Visitor) {
 }
 
 type EventMethodNode struct {
-	ClassName string
-	EventName string
+	ClassName      string
+	EventName      string
+	Parameters     []Parameter
+	Regions        []string
+	RecordsHistory bool
+	Synchronized   bool
 }
 
 func (n EventMethodNode) Accept(v Visitor) {
@@ -60,8 +109,10 @@ func (n EventMethodNode) Accept(v Visitor) {
 }
 
 type BaseStateClassNode struct {
-	FSMClassName string
-	Events       []string
+	FSMClassName    string
+	Events          []string
+	EventParameters map[string][]Parameter
+	RegionEvents    []string
 }
 
 func (n BaseStateClassNode) Accept(v Visitor) {
@@ -78,13 +129,60 @@ func (n StateClassNode) Accept(v Visitor) {
 }
 
 type StateEventMethodNode struct {
-	StateName    string
-	FSMClassName string
-	EventName    string
-	NextState    string
-	Actions      []string
+	StateName      string
+	FSMClassName   string
+	EventName      string
+	Parameters     []Parameter
+	Region         string
+	RegionEvent    bool
+	NextState      string
+	Actions        []string
+	History        string
+	HistoryTargets []HistoryTarget
+	ExitedRegions  []string
+	EnteredRegions []string
+	StoppedTimers  []string
+	StartedTimers  []string
+	Branches       []TransitionAlternative
 }
 
 func (n StateEventMethodNode) Accept(v Visitor) {
 	v.VisitStateEventMethodNode(n)
 }
+
+type GuardedStateEventMethodNode struct {
+	StateName    string
+	FSMClassName string
+	EventName    string
+	Parameters   []Parameter
+	Region       string
+	RegionEvent  bool
+	Alternatives []TransitionAlternative
+}
+
+type TransitionAlternative struct {
+	Guard          string
+	NextState      string
+	Actions        []string
+	History        string
+	HistoryTargets []HistoryTarget
+	ExitedRegions  []string
+	EnteredRegions []string
+	StoppedTimers  []string
+	StartedTimers  []string
+	Branches       []TransitionAlternative
+}
+
+type HistoryTarget struct {
+	Memory         string
+	NextState      string
+	Actions        []string
+	ExitedRegions  []string
+	EnteredRegions []string
+	StoppedTimers  []string
+	StartedTimers  []string
+}
+
+func (n GuardedStateEventMethodNode) Accept(v Visitor) {
+	v.VisitGuardedStateEventMethodNode(n)
+}
@@ -0,0 +1,178 @@
+package table
+
+import "github.com/geisonbiazus/smc/internal/smc/optimizer"
+
+type Generator struct {
+	fsm *optimizer.FSM
+}
+
+func NewGenerator() *Generator {
+	return &Generator{}
+}
+
+func (g *Generator) Generate(fsm *optimizer.FSM) *Table {
+	g.fsm = fsm
+	return &Table{
+		Name:             fsm.Name,
+		InitialState:     fsm.InitialState,
+		States:           g.states(),
+		Events:           g.events(),
+		EventParameters:  parameterMap(fsm.EventParameters),
+		Actions:          fsm.Actions,
+		ActionParameters: parameterMap(fsm.ActionParameters),
+		Guards:           fsm.Guards,
+		FinalStates:      fsm.FinalStates,
+		HistoryStates:    fsm.HistoryStates,
+		StateHistories:   g.stateHistories(),
+		Regions:          g.regions(),
+		InitialRegions:   fsm.InitialRegions,
+		Timers:           g.timers(),
+		InitialTimers:    fsm.InitialTimers,
+		Rows:             g.rows(),
+	}
+}
+
+func (g *Generator) states() []string {
+	var states []string
+	for _, state := range g.fsm.States {
+		states = append(states, state.Name)
+	}
+	return states
+}
+
+func (g *Generator) events() []string {
+	events := append([]string{}, g.fsm.Events...)
+	for _, timer := range g.fsm.Timers {
+		events = append(events, timer.Event)
+	}
+	return events
+}
+
+func (g *Generator) stateHistories() []StateHistory {
+	var histories []StateHistory
+	for _, state := range g.fsm.States {
+		if len(state.HistoryStates) > 0 {
+			histories = append(histories, StateHistory{
+				StateName:     state.Name,
+				HistoryStates: state.HistoryStates,
+			})
+		}
+	}
+	return histories
+}
+
+func (g *Generator) regions() []Region {
+	var regions []Region
+	for _, region := range g.fsm.Regions {
+		r := Region{
+			Name:          region.Name,
+			InitialState:  region.InitialState,
+			Events:        region.Events,
+			EntryActions:  region.EntryActions,
+			Timers:        region.Timers,
+			InitialTimers: region.InitialTimers,
+		}
+		for _, exit := range region.Exits {
+			r.Exits = append(r.Exits, RegionExit{StateName: exit.State, Actions: exit.Actions})
+		}
+		regions = append(regions, r)
+	}
+	return regions
+}
+
+func (g *Generator) timers() []Timer {
+	var timers []Timer
+	for _, timer := range g.fsm.Timers {
+		timers = append(timers, Timer{Event: timer.Event, Region: timer.Region, Duration: timer.Duration})
+	}
+	return timers
+}
+
+func (g *Generator) rows() []Row {
+	rows := []Row{}
+	for _, state := range g.fsm.States {
+		rows = append(rows, Row{StateName: state.Name, Cells: cells(state.Transitions)})
+	}
+	return rows
+}
+
+func cells(transitions []*optimizer.Transition) []Cell {
+	groups := [][]*optimizer.Transition{}
+	cells := []Cell{}
+	index := map[string]int{}
+
+	for _, transition := range transitions {
+		if transition.Delegated {
+			continue
+		}
+		i, ok := index[transition.Event]
+		if !ok {
+			i = len(groups)
+			index[transition.Event] = i
+			groups = append(groups, []*optimizer.Transition{})
+			cells = append(cells, Cell{Event: transition.Event})
+		}
+		groups[i] = append(groups[i], transition)
+	}
+
+	for i, group := range groups {
+		cells[i].Transitions = alternatives(group)
+	}
+	return cells
+}
+
+func alternatives(transitions []*optimizer.Transition) []Transition {
+	var result []Transition
+	for _, transition := range transitions {
+		result = append(result, Transition{
+			Guard:          transition.Guard,
+			NextState:      transition.NextState,
+			Actions:        transition.Actions,
+			History:        transition.History,
+			HistoryTargets: historyTargets(transition),
+			ExitedRegions:  transition.ExitedRegions,
+			EnteredRegions: transition.EnteredRegions,
+			StoppedTimers:  transition.StoppedTimers,
+			StartedTimers:  transition.StartedTimers,
+			Branches:       alternatives(transition.Branches),
+		})
+		if transition.Guard == "" {
+			break
+		}
+	}
+	return result
+}
+
+func historyTargets(transition *optimizer.Transition) []HistoryTarget {
+	var targets []HistoryTarget
+	for _, target := range transition.HistoryTargets {
+		targets = append(targets, HistoryTarget{
+			Memory: target.Memory,
+			Transition: Transition{
+				NextState:      target.NextState,
+				Actions:        target.Actions,
+				ExitedRegions:  target.ExitedRegions,
+				EnteredRegions: target.EnteredRegions,
+				StoppedTimers:  target.StoppedTimers,
+				StartedTimers:  target.StartedTimers,
+			},
+		})
+	}
+	return targets
+}
+
+func parameterMap(parameters map[string][]optimizer.Parameter) map[string][]Parameter {
+	if parameters == nil {
+		return nil
+	}
+
+	result := map[string][]Parameter{}
+	for name, list := range parameters {
+		var converted []Parameter
+		for _, p := range list {
+			converted = append(converted, Parameter{Name: p.Name, Type: p.Type})
+		}
+		result[name] = converted
+	}
+	return result
+}
@@ -0,0 +1,128 @@
+package table
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/geisonbiazus/smc/internal/smc/lexer"
+	"github.com/geisonbiazus/smc/internal/smc/optimizer"
+	"github.com/geisonbiazus/smc/internal/smc/parser"
+	"github.com/geisonbiazus/smc/internal/smc/semantic"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerator(t *testing.T) {
+	t.Run("Single state", func(t *testing.T) {
+		assertGeneratedTable(t,
+			"FSM: fsm Initial: a { a b a c }",
+			&Table{
+				Name:         "fsm",
+				InitialState: "a",
+				States:       []string{"a"},
+				Events:       []string{"b"},
+				Actions:      []string{"c"},
+				Rows: []Row{
+					{StateName: "a", Cells: []Cell{
+						{Event: "b", Transitions: []Transition{{NextState: "a", Actions: []string{"c"}}}},
+					}},
+				},
+			},
+		)
+	})
+
+	t.Run("Guarded alternatives stop at the first unguarded one", func(t *testing.T) {
+		table := generateTable("FSM: fsm Initial: a { a { b [g] a c \n b - - \n b [h] a - } }")
+		assert.Equal(t,
+			[]Cell{
+				{Event: "b", Transitions: []Transition{
+					{Guard: "g", NextState: "a", Actions: []string{"c"}},
+					{Actions: []string{}},
+				}},
+			},
+			table.Rows[0].Cells,
+		)
+	})
+
+	t.Run("Event parameters", func(t *testing.T) {
+		table := generateTable("FSM: fsm Initial: a Event: b(n int) { a b a c }")
+		assert.Equal(t, map[string][]Parameter{"b": {{Name: "n", Type: "int"}}}, table.EventParameters)
+		assert.Equal(t, map[string][]Parameter{"c": {{Name: "n", Type: "int"}}}, table.ActionParameters)
+	})
+
+	t.Run("History", func(t *testing.T) {
+		table := generateTable("FSM: fsm Initial: a { a { x { e y - } y {} f b - } b { g a(H) - } }")
+		assert.Equal(t, []string{"a"}, table.HistoryStates)
+		assert.Equal(t,
+			[]StateHistory{{StateName: "x", HistoryStates: []string{"a"}}, {StateName: "y", HistoryStates: []string{"a"}}},
+			table.StateHistories,
+		)
+		assert.Equal(t,
+			Transition{
+				NextState: "x",
+				Actions:   []string{},
+				History:   "a",
+				HistoryTargets: []HistoryTarget{
+					{Memory: "x", Transition: Transition{NextState: "x", Actions: []string{}}},
+					{Memory: "y", Transition: Transition{NextState: "y", Actions: []string{}}},
+				},
+			},
+			table.Rows[2].Cells[0].Transitions[0],
+		)
+	})
+
+	t.Run("Regions and timers", func(t *testing.T) {
+		table := generateTable("FSM: fsm Initial: a { a { e b - } b { [r] { x { after 1s y - } y { f x - } } e a - } }")
+		assert.Equal(t, []string{"e", "f", "xAfter1s"}, table.Events)
+		assert.Equal(t,
+			[]Region{{
+				Name: "r", InitialState: "x", Events: []string{"f"}, EntryActions: []string{},
+				Timers: []string{"xAfter1s"}, InitialTimers: []string{"xAfter1s"},
+			}},
+			table.Regions,
+		)
+		assert.Equal(t, []Timer{{Event: "xAfter1s", Region: "r", Duration: time.Second}}, table.Timers)
+		assert.Equal(t,
+			[]Transition{{NextState: "b", Actions: []string{}, EnteredRegions: []string{"r"}}},
+			table.Rows[0].Cells[0].Transitions,
+		)
+		assert.Equal(t, []Cell{{Event: "e", Transitions: []Transition{{NextState: "a", Actions: []string{}, ExitedRegions: []string{"r"}}}}}, table.Rows[1].Cells)
+	})
+
+	t.Run("Choices", func(t *testing.T) {
+		table := generateTable("FSM: fsm Initial: a { a e c d \n c? { [g] a x \n b y } \n b {} }")
+		assert.True(t, table.HasBranches())
+		assert.Equal(t,
+			[]Transition{{
+				Actions: []string{"d"},
+				Branches: []Transition{
+					{Guard: "g", NextState: "a", Actions: []string{"x"}},
+					{NextState: "b", Actions: []string{"y"}},
+				},
+			}},
+			table.Rows[0].Cells[0].Transitions,
+		)
+	})
+}
+
+func assertGeneratedTable(t *testing.T, input string, expected *Table) {
+	assert.Equal(t, expected, generateTable(input))
+}
+
+func generateTable(input string) *Table {
+	builder := parser.NewSyntaxBuilder()
+	psr := parser.NewParser(builder)
+	lxr := lexer.NewLexer(psr)
+	lxr.Lex(bytes.NewBufferString(input))
+
+	parsedFSM := builder.FSM()
+
+	analyzer := semantic.NewAnalyzer()
+	semanticFSM := analyzer.Analyze(parsedFSM)
+
+	opt := optimizer.New()
+	optimizedFSM := opt.Optimize(semanticFSM)
+
+	gen := NewGenerator()
+	return gen.Generate(optimizedFSM)
+}
@@ -0,0 +1,101 @@
+package table
+
+import "time"
+
+type Table struct {
+	Name             string
+	InitialState     string
+	States           []string
+	Events           []string
+	EventParameters  map[string][]Parameter
+	Actions          []string
+	ActionParameters map[string][]Parameter
+	Guards           []string
+	FinalStates      []string
+	HistoryStates    []string
+	StateHistories   []StateHistory
+	Regions          []Region
+	InitialRegions   []string
+	Timers           []Timer
+	InitialTimers    []string
+	Rows             []Row
+}
+
+type Parameter struct {
+	Name string
+	Type string
+}
+
+type StateHistory struct {
+	StateName     string
+	HistoryStates []string
+}
+
+type Region struct {
+	Name          string
+	InitialState  string
+	Events        []string
+	EntryActions  []string
+	Exits         []RegionExit
+	Timers        []string
+	InitialTimers []string
+}
+
+type RegionExit struct {
+	StateName string
+	Actions   []string
+}
+
+type Timer struct {
+	Event    string
+	Region   string
+	Duration time.Duration
+}
+
+type Row struct {
+	StateName string
+	Cells     []Cell
+}
+
+type Cell struct {
+	Event       string
+	Transitions []Transition
+}
+
+type Transition struct {
+	Guard          string
+	NextState      string
+	Actions        []string
+	History        string
+	HistoryTargets []HistoryTarget
+	ExitedRegions  []string
+	EnteredRegions []string
+	StoppedTimers  []string
+	StartedTimers  []string
+	Branches       []Transition
+}
+
+type HistoryTarget struct {
+	Memory string
+	Transition
+}
+
+func (t *Table) HasBranches() bool {
+	for _, row := range t.Rows {
+		for _, cell := range row.Cells {
+			if hasBranches(cell.Transitions) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasBranches(transitions []Transition) bool {
+	for _, t := range transitions {
+		if len(t.Branches) > 0 {
+			return true
+		}
+	}
+	return false
+}
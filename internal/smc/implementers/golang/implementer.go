@@ -7,8 +7,11 @@ import (
 )
 
 type Implementer struct {
-	pkg    string
-	result string
+	pkg              string
+	result           string
+	actionParameters map[string][]statepattern.Parameter
+	finalStates      map[string]bool
+	imports          []string
 }
 
 func NewImplementer(pkg string) *Implementer {
@@ -19,13 +22,25 @@ func NewImplementer(pkg string) *Implementer {
 
 func (i *Implementer) Implement(node statepattern.Node) string {
 	i.result = ""
+	i.actionParameters = nil
+	i.finalStates = nil
+	i.imports = nil
 
-	if i.pkg != "" {
-		i.result += "package " + i.pkg + "\n"
+	node.Accept(i)
+	return header(i.pkg, i.imports) + i.result
+}
+
+func header(pkg string, imports []string) string {
+	header := ""
+	if pkg != "" {
+		header += "package " + pkg + "\n"
 	}
 
-	node.Accept(i)
-	return i.result
+	for _, imp := range imports {
+		header += "\n"
+		header += "import \"" + imp + "\"\n"
+	}
+	return header
 }
 
 func (i *Implementer) VisitStateInterfaceNode(node statepattern.StateInterfaceNode) {
@@ -33,7 +48,11 @@ func (i *Implementer) VisitStateInterfaceNode(node statepattern.StateInterfaceNo
 	i.result += "type State interface {\n"
 
 	for _, event := range node.Events {
-		i.result += "  " + title(event) + "(fsm *" + title(node.FSMClassName) + ")\n"
+		i.result += "  " + title(event) + "(" + stateMethodParameters(node.FSMClassName, node.EventParameters[event]) + ")" + handledType(contains(node.RegionEvents, event)) + "\n"
+	}
+
+	if len(node.RegionEvents) > 0 {
+		i.result += "  stateName() string\n"
 	}
 
 	i.result += "}\n"
@@ -43,8 +62,13 @@ func (i *Implementer) VisitActionsInterfaceNode(node statepattern.ActionsInterfa
 	i.result += "\n"
 	i.result += "type Actions interface {\n"
 
+	i.actionParameters = node.ActionParameters
 	for _, action := range node.Actions {
-		i.result += "  " + title(action) + "()\n"
+		i.result += "  " + title(action) + "(" + parameterList(node.ActionParameters[action]) + ")\n"
+	}
+
+	if len(node.Guards) > 0 {
+		i.result += "  Guard(name string) bool\n"
 	}
 
 	i.result += "  UnhandledTransition(state string, event string)\n"
@@ -54,43 +78,268 @@ func (i *Implementer) VisitActionsInterfaceNode(node statepattern.ActionsInterfa
 func (i *Implementer) VisitFSMClassNode(node statepattern.FSMClassNode) {
 	className := title(node.ClassName)
 
+	i.writeFSMStruct(className, node)
+	i.writeConstructor(className, node)
+
+	if len(node.HistoryStates) > 0 {
+		i.writeRemember(className, node.StateHistories)
+	}
+
+	if len(node.Timers) > 0 {
+		i.writeTimers(className, node.Timers, len(node.HistoryStates) > 0)
+	}
+
+	for _, region := range node.Regions {
+		i.writeRegion(className, region)
+	}
+
+	if len(node.FinalStates) > 0 {
+		i.writeCompletion(className, node.FinalStates, len(node.Timers) > 0)
+	}
+
+	for _, methodNode := range node.EventMethods {
+		methodNode.Accept(i)
+	}
+}
+
+func (i *Implementer) writeFSMStruct(className string, node statepattern.FSMClassNode) {
 	i.result += "\n"
 	i.result += "type " + className + " struct {\n"
 	i.result += "  State State\n"
 	i.result += "  Actions Actions\n"
+	for _, region := range node.Regions {
+		i.result += "  " + regionField(region.Name) + " State\n"
+	}
+	for _, state := range node.HistoryStates {
+		i.result += "  " + historyField(state) + " string\n"
+	}
+	if len(node.Timers) > 0 {
+		i.result += "  Clock Clock\n"
+		i.result += "  timers map[string]*armedTimer\n"
+		i.result += "  mu sync.Mutex\n"
+	}
 	i.result += "}\n"
+}
+
+func (i *Implementer) writeConstructor(className string, node statepattern.FSMClassNode) {
+	hasTimers := len(node.Timers) > 0
+	hasSetup := hasTimers || len(node.HistoryStates) > 0
+
 	i.result += "\n"
-	i.result += "func New" + className + "(actions Actions) *" + className + " {\n"
-	i.result += "  return &" + className + "{\n"
+	if hasTimers {
+		i.result += "func New" + className + "(actions Actions) *" + className + " {\n"
+		i.result += "  return New" + className + "WithClock(actions, systemClock{})\n"
+		i.result += "}\n"
+		i.result += "\n"
+		i.result += "func New" + className + "WithClock(actions Actions, clock Clock) *" + className + " {\n"
+	} else {
+		i.result += "func New" + className + "(actions Actions) *" + className + " {\n"
+	}
+
+	if hasSetup {
+		i.result += "  fsm := &" + className + "{\n"
+	} else {
+		i.result += "  return &" + className + "{\n"
+	}
 	i.result += "    Actions: actions,\n"
 	i.result += "    State:   NewState" + title(node.InitialState) + "(),\n"
+	for _, region := range node.InitialRegions {
+		i.result += "    " + regionField(region) + ": NewState" + title(initialState(node.Regions, region)) + "(),\n"
+	}
+	if hasTimers {
+		i.result += "    Clock:   clock,\n"
+		i.result += "    timers:  map[string]*armedTimer{},\n"
+	}
+	i.result += "  }\n"
+
+	if len(node.InitialTimers) > 0 {
+		i.result += lock("  ", "fsm")
+	}
+	for _, timer := range node.InitialTimers {
+		i.result += "  fsm.start" + title(timer) + "()\n"
+	}
+	if len(node.HistoryStates) > 0 {
+		i.result += "  fsm.remember()\n"
+	}
+	if hasSetup {
+		i.result += "  return fsm\n"
+	}
+	i.result += "}\n"
+}
+
+func (i *Implementer) writeRemember(className string, histories []statepattern.StateHistory) {
+	i.result += "\n"
+	i.result += "func (f *" + className + ") remember() {\n"
+	for _, region := range historyRegions(histories) {
+		i.result += "  switch f." + regionField(region) + ".(type) {\n"
+		for _, history := range histories {
+			if history.Region != region {
+				continue
+			}
+			i.result += "  case State" + title(history.StateName) + ":\n"
+			for _, state := range history.HistoryStates {
+				i.result += "    f." + historyField(state) + " = \"" + history.StateName + "\"\n"
+			}
+		}
+		i.result += "  }\n"
+	}
+	i.result += "}\n"
+}
+
+func (i *Implementer) writeCompletion(className string, finalStates []string, synchronized bool) {
+	i.finalStates = map[string]bool{}
+	for _, state := range finalStates {
+		i.finalStates[state] = true
+	}
+
+	i.result += "\n"
+	i.result += "type Completer interface {\n"
+	i.result += "  Completed()\n"
+	i.result += "}\n"
+	i.result += "\n"
+	i.result += "func (f *" + className + ") IsFinished() bool {\n"
+	if synchronized {
+		i.result += lock("  ", "f")
+	}
+	i.result += "  switch f.State.(type) {\n"
+	i.result += "  case " + stateTypeList(finalStates) + ":\n"
+	i.result += "    return true\n"
+	i.result += "  }\n"
+	i.result += "  return false\n"
+	i.result += "}\n"
+	i.result += "\n"
+	i.result += "func (f *" + className + ") complete() {\n"
+	i.result += "  if c, ok := f.Actions.(Completer); ok {\n"
+	i.result += "    c.Completed()\n"
 	i.result += "  }\n"
 	i.result += "}\n"
+}
 
-	for _, methodNode := range node.EventMethods {
-		methodNode.Accept(i)
+func stateTypeList(states []string) string {
+	list := []string{}
+	for _, state := range states {
+		list = append(list, "State"+title(state))
+	}
+	return strings.Join(list, ", ")
+}
+
+func historyRegions(histories []statepattern.StateHistory) []string {
+	regions := []string{}
+	index := map[string]bool{}
+	for _, history := range histories {
+		if !index[history.Region] {
+			index[history.Region] = true
+			regions = append(regions, history.Region)
+		}
 	}
+	return regions
+}
+
+func (i *Implementer) writeRegion(className string, region statepattern.RegionNode) {
+	field := regionField(region.Name)
+
+	i.result += "\n"
+	i.result += "func (f *" + className + ") enter" + title(region.Name) + "() {\n"
+	i.result += "  f." + field + " = NewState" + title(region.InitialState) + "()\n"
+	for _, action := range region.EntryActions {
+		i.result += "  f.Actions." + title(action) + "()\n"
+	}
+	for _, timer := range region.InitialTimers {
+		i.result += "  f.start" + title(timer) + "()\n"
+	}
+	i.result += "}\n"
+
+	i.result += "\n"
+	i.result += "func (f *" + className + ") exit" + title(region.Name) + "() {\n"
+	for _, timer := range region.Timers {
+		i.result += "  f.stopTimer(\"" + timer + "\")\n"
+	}
+	if len(region.Exits) > 0 {
+		i.result += "  switch f." + field + ".(type) {\n"
+		for _, exit := range region.Exits {
+			i.result += "  case State" + title(exit.StateName) + ":\n"
+			for _, action := range exit.Actions {
+				i.result += "    f.Actions." + title(action) + "()\n"
+			}
+		}
+		i.result += "  }\n"
+	}
+	i.result += "  f." + field + " = nil\n"
+	i.result += "}\n"
+}
+
+func initialState(regions []statepattern.RegionNode, name string) string {
+	for _, region := range regions {
+		if region.Name == name {
+			return region.InitialState
+		}
+	}
+	return ""
 }
 
 func (i *Implementer) VisitEventMethodNode(node statepattern.EventMethodNode) {
 	i.result += "\n"
-	i.result += "func (f *" + title(node.ClassName) + ") " + title(node.EventName) + "() {\n"
-	i.result += "  f.State." + title(node.EventName) + "(f)\n"
+	i.result += "func (f *" + title(node.ClassName) + ") " + title(node.EventName) + "(" + parameterList(node.Parameters) + ") {\n"
+	if node.Synchronized {
+		i.result += lock("  ", "f")
+	}
+	if len(node.Regions) > 0 {
+		i.writeRegionDispatch(node)
+	} else {
+		i.result += "  f.State." + title(node.EventName) + "(" + stateMethodArguments("f", node.Parameters) + ")\n"
+	}
+	if node.RecordsHistory {
+		i.result += "  f.remember()\n"
+	}
 	i.result += "}\n"
 }
 
+func (i *Implementer) writeRegionDispatch(node statepattern.EventMethodNode) {
+	arguments := stateMethodArguments("f", node.Parameters)
+
+	i.result += "  handled := false\n"
+	for _, region := range node.Regions {
+		i.result += "  if f." + regionField(region) + " != nil && f." + regionField(region) + "." + title(node.EventName) + "(" + arguments + ") {\n"
+		i.result += "    handled = true\n"
+		i.result += "  }\n"
+	}
+	i.result += "  if !f.State." + title(node.EventName) + "(" + arguments + ") && !handled {\n"
+	i.result += "    f.Actions.UnhandledTransition(f.State.stateName(), \"" + node.EventName + "\")\n"
+	i.result += "  }\n"
+}
+
 func (i *Implementer) VisitBaseStateClassNode(node statepattern.BaseStateClassNode) {
 	i.result += "\n"
-	i.result += "type BaseState struct {"
+	i.result += "type BaseState struct {\n"
 	i.result += "  StateName string\n"
 	i.result += "}\n"
 
 	for _, event := range node.Events {
 		i.result += "\n"
-		i.result += "func (b BaseState) " + title(event) + "(fsm *" + title(node.FSMClassName) + ") {\n"
-		i.result += "  fsm.Actions.UnhandledTransition(b.StateName, \"" + event + "\")\n"
+		i.result += "func (b BaseState) " + title(event) + "(" + stateMethodParameters(node.FSMClassName, node.EventParameters[event]) + ")" + handledType(contains(node.RegionEvents, event)) + " {\n"
+		if contains(node.RegionEvents, event) {
+			i.result += "  return false\n"
+		} else {
+			i.result += "  fsm.Actions.UnhandledTransition(b.StateName, \"" + event + "\")\n"
+		}
 		i.result += "}\n"
 	}
+
+	if len(node.RegionEvents) > 0 {
+		i.result += "\n"
+		i.result += "func (b BaseState) stateName() string {\n"
+		i.result += "  return b.StateName\n"
+		i.result += "}\n"
+	}
+}
+
+func contains(list []string, item string) bool {
+	for _, i := range list {
+		if i == item {
+			return true
+		}
+	}
+	return false
 }
 
 func (i *Implementer) VisitStateClassNode(node statepattern.StateClassNode) {
@@ -109,19 +358,226 @@ func (i *Implementer) VisitStateClassNode(node statepattern.StateClassNode) {
 }
 func (i *Implementer) VisitStateEventMethodNode(node statepattern.StateEventMethodNode) {
 	i.result += "\n"
-	i.result += "func (s State" + title(node.StateName) + ") " + title(node.EventName) + "(fsm *" + title(node.FSMClassName) + ") {\n"
-
-	if node.NextState != "" {
-		i.result += "  fsm.State = NewState" + title(node.NextState) + "()\n"
+	i.result += "func (s State" + title(node.StateName) + ") " + title(node.EventName) + "(" + stateMethodParameters(node.FSMClassName, node.Parameters) + ")" + handledType(node.RegionEvent) + " {\n"
+	i.writeTarget("  ", target{
+		region:         node.Region,
+		nextState:      node.NextState,
+		actions:        node.Actions,
+		exitedRegions:  node.ExitedRegions,
+		enteredRegions: node.EnteredRegions,
+		stoppedTimers:  node.StoppedTimers,
+		startedTimers:  node.StartedTimers,
+		branches:       node.Branches,
+	}, node.History, node.HistoryTargets, node.Parameters)
+	if node.RegionEvent {
+		i.result += "  return true\n"
 	}
+	i.result += "}\n"
+}
 
-	for _, action := range node.Actions {
-		i.result += "  fsm.Actions." + title(action) + "()\n"
+func (i *Implementer) VisitGuardedStateEventMethodNode(node statepattern.GuardedStateEventMethodNode) {
+	i.result += "\n"
+	i.result += "func (s State" + title(node.StateName) + ") " + title(node.EventName) + "(" + stateMethodParameters(node.FSMClassName, node.Parameters) + ")" + handledType(node.RegionEvent) + " {\n"
+
+	for _, alternative := range node.Alternatives {
+		t := target{
+			region:         node.Region,
+			nextState:      alternative.NextState,
+			actions:        alternative.Actions,
+			exitedRegions:  alternative.ExitedRegions,
+			enteredRegions: alternative.EnteredRegions,
+			stoppedTimers:  alternative.StoppedTimers,
+			startedTimers:  alternative.StartedTimers,
+			branches:       alternative.Branches,
+		}
+
+		if alternative.Guard == "" {
+			i.writeTarget("  ", t, alternative.History, alternative.HistoryTargets, node.Parameters)
+			if node.RegionEvent {
+				i.result += "  return true\n"
+			}
+			i.result += "}\n"
+			return
+		}
+
+		i.result += "  if fsm.Actions.Guard(\"" + alternative.Guard + "\") {\n"
+		i.writeTarget("    ", t, alternative.History, alternative.HistoryTargets, node.Parameters)
+		if node.RegionEvent {
+			i.result += "    return true\n"
+		} else {
+			i.result += "    return\n"
+		}
+		i.result += "  }\n"
 	}
 
+	if node.RegionEvent {
+		i.result += "  return s.BaseState." + title(node.EventName) + "(" + stateMethodArguments("fsm", node.Parameters) + ")\n"
+	} else {
+		i.result += "  s.BaseState." + title(node.EventName) + "(" + stateMethodArguments("fsm", node.Parameters) + ")\n"
+	}
 	i.result += "}\n"
 }
 
+func handledType(regionEvent bool) string {
+	if regionEvent {
+		return " bool"
+	}
+	return ""
+}
+
+type target struct {
+	region         string
+	nextState      string
+	actions        []string
+	exitedRegions  []string
+	enteredRegions []string
+	stoppedTimers  []string
+	startedTimers  []string
+	branches       []statepattern.TransitionAlternative
+}
+
+func (i *Implementer) writeTarget(
+	indent string, t target,
+	history string, targets []statepattern.HistoryTarget, parameters []statepattern.Parameter,
+) {
+	if history == "" {
+		i.writeTransition(indent, t, parameters)
+		return
+	}
+
+	i.result += indent + "switch fsm." + historyField(history) + " {\n"
+	for _, h := range targets {
+		i.result += indent + "case \"" + h.Memory + "\":\n"
+		i.writeTransition(indent+"  ", target{
+			region:         t.region,
+			nextState:      h.NextState,
+			actions:        h.Actions,
+			exitedRegions:  h.ExitedRegions,
+			enteredRegions: h.EnteredRegions,
+			stoppedTimers:  h.StoppedTimers,
+			startedTimers:  h.StartedTimers,
+		}, parameters)
+	}
+	i.result += indent + "default:\n"
+	i.writeTransition(indent+"  ", t, parameters)
+	i.result += indent + "}\n"
+}
+
+func (i *Implementer) writeTransition(indent string, t target, parameters []statepattern.Parameter) {
+	for _, timer := range t.stoppedTimers {
+		i.result += indent + "fsm.stopTimer(\"" + timer + "\")\n"
+	}
+
+	for _, region := range t.exitedRegions {
+		i.result += indent + "fsm.exit" + title(region) + "()\n"
+	}
+
+	if t.nextState != "" {
+		i.result += indent + "fsm." + regionField(t.region) + " = NewState" + title(t.nextState) + "()\n"
+	}
+
+	for _, action := range t.actions {
+		i.result += indent + "fsm.Actions." + title(action) + "(" + i.actionArguments(action, parameters) + ")\n"
+	}
+
+	for _, region := range t.enteredRegions {
+		i.result += indent + "fsm.enter" + title(region) + "()\n"
+	}
+
+	for _, timer := range t.startedTimers {
+		i.result += indent + "fsm.start" + title(timer) + "()\n"
+	}
+
+	if t.region == "" && i.finalStates[t.nextState] {
+		i.result += indent + "fsm.complete()\n"
+	}
+
+	if len(t.branches) > 0 {
+		i.writeBranches(indent, t.region, t.branches, parameters)
+	}
+}
+
+func (i *Implementer) writeBranches(
+	indent, region string, branches []statepattern.TransitionAlternative, parameters []statepattern.Parameter,
+) {
+	for n, branch := range branches {
+		t := target{
+			region:         region,
+			nextState:      branch.NextState,
+			actions:        branch.Actions,
+			exitedRegions:  branch.ExitedRegions,
+			enteredRegions: branch.EnteredRegions,
+			stoppedTimers:  branch.StoppedTimers,
+			startedTimers:  branch.StartedTimers,
+			branches:       branch.Branches,
+		}
+
+		switch {
+		case n == 0 && branch.Guard == "":
+			i.writeTransition(indent, t, parameters)
+			return
+		case n == 0:
+			i.result += indent + "if fsm.Actions.Guard(\"" + branch.Guard + "\") {\n"
+		case branch.Guard == "":
+			i.result += indent + "} else {\n"
+		default:
+			i.result += indent + "} else if fsm.Actions.Guard(\"" + branch.Guard + "\") {\n"
+		}
+		i.writeTransition(indent+"  ", t, parameters)
+
+		if branch.Guard == "" {
+			break
+		}
+	}
+	i.result += indent + "}\n"
+}
+
+func (i *Implementer) actionArguments(action string, parameters []statepattern.Parameter) string {
+	if len(i.actionParameters[action]) == 0 {
+		return ""
+	}
+	return argumentList(parameters)
+}
+
+func stateMethodParameters(fsmClassName string, parameters []statepattern.Parameter) string {
+	result := "fsm *" + title(fsmClassName)
+	if len(parameters) > 0 {
+		result += ", " + parameterList(parameters)
+	}
+	return result
+}
+
+func stateMethodArguments(fsm string, parameters []statepattern.Parameter) string {
+	if len(parameters) > 0 {
+		return fsm + ", " + argumentList(parameters)
+	}
+	return fsm
+}
+
+func parameterList(parameters []statepattern.Parameter) string {
+	list := []string{}
+	for _, p := range parameters {
+		list = append(list, p.Name+" "+p.Type)
+	}
+	return strings.Join(list, ", ")
+}
+
+func argumentList(parameters []statepattern.Parameter) string {
+	list := []string{}
+	for _, p := range parameters {
+		list = append(list, p.Name)
+	}
+	return strings.Join(list, ", ")
+}
+
+func regionField(region string) string {
+	return title(region) + "State"
+}
+
+func historyField(state string) string {
+	return title(state) + "History"
+}
+
 func title(s string) string {
 	return strings.Title(s)
 }
@@ -68,6 +68,730 @@ func TestImplementer(t *testing.T) {
 		)
 	})
 
+	t.Run("Guarded transitions", func(t *testing.T) {
+		assertImplementedFSM(t,
+			"FSM: fsm Initial: a { a { e [g] a x \n e [h] - - \n f [g] - y } }",
+			`package fsm
+
+			type State interface {
+				E(fsm *Fsm)
+				F(fsm *Fsm)
+			}
+
+			type Actions interface {
+				X()
+				Y()
+				Guard(name string) bool
+				UnhandledTransition(state string, event string)
+			}
+
+			type Fsm struct {
+				State   State
+				Actions Actions
+			}
+
+			func NewFsm(actions Actions) *Fsm {
+				return &Fsm{
+					Actions: actions,
+					State:   NewStateA(),
+				}
+			}
+
+			func (f *Fsm) E() {
+				f.State.E(f)
+			}
+
+			func (f *Fsm) F() {
+				f.State.F(f)
+			}
+
+			type BaseState struct {
+				StateName string
+			}
+
+			func (b BaseState) E(fsm *Fsm) {
+				fsm.Actions.UnhandledTransition(b.StateName, "e")
+			}
+
+			func (b BaseState) F(fsm *Fsm) {
+				fsm.Actions.UnhandledTransition(b.StateName, "f")
+			}
+
+			type StateA struct {
+				BaseState
+			}
+
+			func NewStateA() StateA {
+				return StateA{BaseState{StateName: "a"}}
+			}
+
+			func (s StateA) E(fsm *Fsm) {
+				if fsm.Actions.Guard("g") {
+					fsm.State = NewStateA()
+					fsm.Actions.X()
+					return
+				}
+				if fsm.Actions.Guard("h") {
+					return
+				}
+				s.BaseState.E(fsm)
+			}
+
+			func (s StateA) F(fsm *Fsm) {
+				if fsm.Actions.Guard("g") {
+					fsm.Actions.Y()
+					return
+				}
+				s.BaseState.F(fsm)
+			}
+			`,
+		)
+	})
+
+	t.Run("Event parameters", func(t *testing.T) {
+		assertImplementedFSM(t,
+			"FSM: fsm Initial: a Event: e(n int, m string) { a >y { e [g] a x \n e - z \n f a - } }",
+			`package fsm
+
+			type State interface {
+				E(fsm *Fsm, n int, m string)
+				F(fsm *Fsm)
+			}
+
+			type Actions interface {
+				Y()
+				X(n int, m string)
+				Z(n int, m string)
+				Guard(name string) bool
+				UnhandledTransition(state string, event string)
+			}
+
+			type Fsm struct {
+				State   State
+				Actions Actions
+			}
+
+			func NewFsm(actions Actions) *Fsm {
+				return &Fsm{
+					Actions: actions,
+					State:   NewStateA(),
+				}
+			}
+
+			func (f *Fsm) E(n int, m string) {
+				f.State.E(f, n, m)
+			}
+
+			func (f *Fsm) F() {
+				f.State.F(f)
+			}
+
+			type BaseState struct {
+				StateName string
+			}
+
+			func (b BaseState) E(fsm *Fsm, n int, m string) {
+				fsm.Actions.UnhandledTransition(b.StateName, "e")
+			}
+
+			func (b BaseState) F(fsm *Fsm) {
+				fsm.Actions.UnhandledTransition(b.StateName, "f")
+			}
+
+			type StateA struct {
+				BaseState
+			}
+
+			func NewStateA() StateA {
+				return StateA{BaseState{StateName: "a"}}
+			}
+
+			func (s StateA) E(fsm *Fsm, n int, m string) {
+				if fsm.Actions.Guard("g") {
+					fsm.State = NewStateA()
+					fsm.Actions.X(n, m)
+					return
+				}
+				fsm.Actions.Z(n, m)
+			}
+
+			func (s StateA) F(fsm *Fsm) {
+				fsm.State = NewStateA()
+			}
+			`,
+		)
+	})
+
+	t.Run("History targets", func(t *testing.T) {
+		assertImplementedFSM(t,
+			"FSM: fsm Initial: a { a { b { e c - } \n c { e d - } } \n d f a(H) x }",
+			`package fsm
+
+			type State interface {
+				E(fsm *Fsm)
+				F(fsm *Fsm)
+			}
+
+			type Actions interface {
+				X()
+				UnhandledTransition(state string, event string)
+			}
+
+			type Fsm struct {
+				State    State
+				Actions  Actions
+				AHistory string
+			}
+
+			func NewFsm(actions Actions) *Fsm {
+				fsm := &Fsm{
+					Actions: actions,
+					State:   NewStateB(),
+				}
+				fsm.remember()
+				return fsm
+			}
+
+			func (f *Fsm) remember() {
+				switch f.State.(type) {
+				case StateB:
+					f.AHistory = "b"
+				case StateC:
+					f.AHistory = "c"
+				}
+			}
+
+			func (f *Fsm) E() {
+				f.State.E(f)
+				f.remember()
+			}
+
+			func (f *Fsm) F() {
+				f.State.F(f)
+				f.remember()
+			}
+
+			type BaseState struct {
+				StateName string
+			}
+
+			func (b BaseState) E(fsm *Fsm) {
+				fsm.Actions.UnhandledTransition(b.StateName, "e")
+			}
+
+			func (b BaseState) F(fsm *Fsm) {
+				fsm.Actions.UnhandledTransition(b.StateName, "f")
+			}
+
+			type StateB struct {
+				BaseState
+			}
+
+			func NewStateB() StateB {
+				return StateB{BaseState{StateName: "b"}}
+			}
+
+			func (s StateB) E(fsm *Fsm) {
+				fsm.State = NewStateC()
+			}
+
+			type StateC struct {
+				BaseState
+			}
+
+			func NewStateC() StateC {
+				return StateC{BaseState{StateName: "c"}}
+			}
+
+			func (s StateC) E(fsm *Fsm) {
+				fsm.State = NewStateD()
+			}
+
+			type StateD struct {
+				BaseState
+			}
+
+			func NewStateD() StateD {
+				return StateD{BaseState{StateName: "d"}}
+			}
+
+			func (s StateD) F(fsm *Fsm) {
+				switch fsm.AHistory {
+				case "b":
+					fsm.State = NewStateB()
+					fsm.Actions.X()
+				case "c":
+					fsm.State = NewStateC()
+					fsm.Actions.X()
+				default:
+					fsm.State = NewStateB()
+					fsm.Actions.X()
+				}
+			}
+			`,
+		)
+	})
+
+	t.Run("Regions", func(t *testing.T) {
+		assertImplementedFSM(t,
+			"FSM: fsm Initial: a { a { [r] { b >x { e c - } \n c <y { e b - } } \n f d - } \n d f a - }",
+			`package fsm
+
+			type State interface {
+				F(fsm *Fsm)
+				E(fsm *Fsm) bool
+				stateName() string
+			}
+
+			type Actions interface {
+				X()
+				Y()
+				UnhandledTransition(state string, event string)
+			}
+
+			type Fsm struct {
+				State   State
+				Actions Actions
+				RState  State
+			}
+
+			func NewFsm(actions Actions) *Fsm {
+				return &Fsm{
+					Actions: actions,
+					State:   NewStateA(),
+					RState:  NewStateB(),
+				}
+			}
+
+			func (f *Fsm) enterR() {
+				f.RState = NewStateB()
+				f.Actions.X()
+			}
+
+			func (f *Fsm) exitR() {
+				switch f.RState.(type) {
+				case StateC:
+					f.Actions.Y()
+				}
+				f.RState = nil
+			}
+
+			func (f *Fsm) F() {
+				f.State.F(f)
+			}
+
+			func (f *Fsm) E() {
+				handled := false
+				if f.RState != nil && f.RState.E(f) {
+					handled = true
+				}
+				if !f.State.E(f) && !handled {
+					f.Actions.UnhandledTransition(f.State.stateName(), "e")
+				}
+			}
+
+			type BaseState struct {
+				StateName string
+			}
+
+			func (b BaseState) F(fsm *Fsm) {
+				fsm.Actions.UnhandledTransition(b.StateName, "f")
+			}
+
+			func (b BaseState) E(fsm *Fsm) bool {
+				return false
+			}
+
+			func (b BaseState) stateName() string {
+				return b.StateName
+			}
+
+			type StateA struct {
+				BaseState
+			}
+
+			func NewStateA() StateA {
+				return StateA{BaseState{StateName: "a"}}
+			}
+
+			func (s StateA) F(fsm *Fsm) {
+				fsm.exitR()
+				fsm.State = NewStateD()
+			}
+
+			type StateB struct {
+				BaseState
+			}
+
+			func NewStateB() StateB {
+				return StateB{BaseState{StateName: "b"}}
+			}
+
+			func (s StateB) E(fsm *Fsm) bool {
+				fsm.RState = NewStateC()
+				return true
+			}
+
+			type StateC struct {
+				BaseState
+			}
+
+			func NewStateC() StateC {
+				return StateC{BaseState{StateName: "c"}}
+			}
+
+			func (s StateC) E(fsm *Fsm) bool {
+				fsm.RState = NewStateB()
+				fsm.Actions.Y()
+				fsm.Actions.X()
+				return true
+			}
+
+			type StateD struct {
+				BaseState
+			}
+
+			func NewStateD() StateD {
+				return StateD{BaseState{StateName: "d"}}
+			}
+
+			func (s StateD) F(fsm *Fsm) {
+				fsm.State = NewStateA()
+				fsm.enterR()
+			}
+			`,
+		)
+	})
+
+	t.Run("Final states", func(t *testing.T) {
+		assertImplementedFSM(t,
+			"FSM: fsm Initial: a Final: b Final: c { a { e b x \n f c - } \n b - - - \n c - - - }",
+			`package fsm
+
+			type State interface {
+				E(fsm *Fsm)
+				F(fsm *Fsm)
+			}
+
+			type Actions interface {
+				X()
+				UnhandledTransition(state string, event string)
+			}
+
+			type Fsm struct {
+				State   State
+				Actions Actions
+			}
+
+			func NewFsm(actions Actions) *Fsm {
+				return &Fsm{
+					Actions: actions,
+					State:   NewStateA(),
+				}
+			}
+
+			type Completer interface {
+				Completed()
+			}
+
+			func (f *Fsm) IsFinished() bool {
+				switch f.State.(type) {
+				case StateB, StateC:
+					return true
+				}
+				return false
+			}
+
+			func (f *Fsm) complete() {
+				if c, ok := f.Actions.(Completer); ok {
+					c.Completed()
+				}
+			}
+
+			func (f *Fsm) E() {
+				f.State.E(f)
+			}
+
+			func (f *Fsm) F() {
+				f.State.F(f)
+			}
+
+			type BaseState struct {
+				StateName string
+			}
+
+			func (b BaseState) E(fsm *Fsm) {
+				fsm.Actions.UnhandledTransition(b.StateName, "e")
+			}
+
+			func (b BaseState) F(fsm *Fsm) {
+				fsm.Actions.UnhandledTransition(b.StateName, "f")
+			}
+
+			type StateA struct {
+				BaseState
+			}
+
+			func NewStateA() StateA {
+				return StateA{BaseState{StateName: "a"}}
+			}
+
+			func (s StateA) E(fsm *Fsm) {
+				fsm.State = NewStateB()
+				fsm.Actions.X()
+				fsm.complete()
+			}
+
+			func (s StateA) F(fsm *Fsm) {
+				fsm.State = NewStateC()
+				fsm.complete()
+			}
+
+			type StateB struct {
+				BaseState
+			}
+
+			func NewStateB() StateB {
+				return StateB{BaseState{StateName: "b"}}
+			}
+
+			type StateC struct {
+				BaseState
+			}
+
+			func NewStateC() StateC {
+				return StateC{BaseState{StateName: "c"}}
+			}
+			`,
+		)
+	})
+
+	t.Run("Timeouts", func(t *testing.T) {
+		assertImplementedFSM(t,
+			"FSM: fsm Initial: a { a { e b - \n after 5s b x } \n b { after 250ms a - } }",
+			`package fsm
+
+			import "sync"
+
+			import "time"
+
+			type State interface {
+				E(fsm *Fsm)
+				AAfter5s(fsm *Fsm)
+				BAfter250ms(fsm *Fsm)
+			}
+
+			type Actions interface {
+				X()
+				UnhandledTransition(state string, event string)
+			}
+
+			type Fsm struct {
+				State   State
+				Actions Actions
+				Clock   Clock
+				timers  map[string]*armedTimer
+				mu      sync.Mutex
+			}
+
+			func NewFsm(actions Actions) *Fsm {
+				return NewFsmWithClock(actions, systemClock{})
+			}
+
+			func NewFsmWithClock(actions Actions, clock Clock) *Fsm {
+				fsm := &Fsm{
+					Actions: actions,
+					State:   NewStateA(),
+					Clock:   clock,
+					timers:  map[string]*armedTimer{},
+				}
+				fsm.mu.Lock()
+				defer fsm.mu.Unlock()
+				fsm.startAAfter5s()
+				return fsm
+			}
+
+			type Clock interface {
+				AfterFunc(d time.Duration, f func()) Timer
+			}
+
+			type Timer interface {
+				Stop() bool
+			}
+
+			type systemClock struct{}
+
+			func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+				return time.AfterFunc(d, f)
+			}
+
+			type armedTimer struct {
+				Timer
+			}
+
+			func (f *Fsm) startTimer(name string, d time.Duration, fire func()) {
+				t := &armedTimer{}
+				f.timers[name] = t
+				t.Timer = f.Clock.AfterFunc(d, func() {
+					f.mu.Lock()
+					defer f.mu.Unlock()
+					if f.timers[name] == t {
+						delete(f.timers, name)
+						fire()
+					}
+				})
+			}
+
+			func (f *Fsm) stopTimer(name string) {
+				if t, ok := f.timers[name]; ok {
+					delete(f.timers, name)
+					t.Stop()
+				}
+			}
+
+			func (f *Fsm) startAAfter5s() {
+				f.startTimer("aAfter5s", 5*time.Second, func() {
+					f.State.AAfter5s(f)
+				})
+			}
+
+			func (f *Fsm) startBAfter250ms() {
+				f.startTimer("bAfter250ms", 250*time.Millisecond, func() {
+					f.State.BAfter250ms(f)
+				})
+			}
+
+			func (f *Fsm) E() {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				f.State.E(f)
+			}
+
+			type BaseState struct {
+				StateName string
+			}
+
+			func (b BaseState) E(fsm *Fsm) {
+				fsm.Actions.UnhandledTransition(b.StateName, "e")
+			}
+
+			func (b BaseState) AAfter5s(fsm *Fsm) {
+				fsm.Actions.UnhandledTransition(b.StateName, "aAfter5s")
+			}
+
+			func (b BaseState) BAfter250ms(fsm *Fsm) {
+				fsm.Actions.UnhandledTransition(b.StateName, "bAfter250ms")
+			}
+
+			type StateA struct {
+				BaseState
+			}
+
+			func NewStateA() StateA {
+				return StateA{BaseState{StateName: "a"}}
+			}
+
+			func (s StateA) E(fsm *Fsm) {
+				fsm.stopTimer("aAfter5s")
+				fsm.State = NewStateB()
+				fsm.startBAfter250ms()
+			}
+
+			func (s StateA) AAfter5s(fsm *Fsm) {
+				fsm.stopTimer("aAfter5s")
+				fsm.State = NewStateB()
+				fsm.Actions.X()
+				fsm.startBAfter250ms()
+			}
+
+			type StateB struct {
+				BaseState
+			}
+
+			func NewStateB() StateB {
+				return StateB{BaseState{StateName: "b"}}
+			}
+
+			func (s StateB) BAfter250ms(fsm *Fsm) {
+				fsm.stopTimer("bAfter250ms")
+				fsm.State = NewStateA()
+				fsm.startAAfter5s()
+			}
+			`,
+		)
+	})
+
+	t.Run("Choices", func(t *testing.T) {
+		assertImplementedFSM(t,
+			"FSM: fsm Initial: a { a <x { e c y } \n c? { [g] b - \n a z } \n b - - - }",
+			`package fsm
+
+			type State interface {
+				E(fsm *Fsm)
+			}
+
+			type Actions interface {
+				X()
+				Y()
+				Z()
+				Guard(name string) bool
+				UnhandledTransition(state string, event string)
+			}
+
+			type Fsm struct {
+				State   State
+				Actions Actions
+			}
+
+			func NewFsm(actions Actions) *Fsm {
+				return &Fsm{
+					Actions: actions,
+					State:   NewStateA(),
+				}
+			}
+
+			func (f *Fsm) E() {
+				f.State.E(f)
+			}
+
+			type BaseState struct {
+				StateName string
+			}
+
+			func (b BaseState) E(fsm *Fsm) {
+				fsm.Actions.UnhandledTransition(b.StateName, "e")
+			}
+
+			type StateA struct {
+				BaseState
+			}
+
+			func NewStateA() StateA {
+				return StateA{BaseState{StateName: "a"}}
+			}
+
+			func (s StateA) E(fsm *Fsm) {
+				fsm.Actions.Y()
+				if fsm.Actions.Guard("g") {
+					fsm.State = NewStateB()
+					fsm.Actions.X()
+				} else {
+					fsm.State = NewStateA()
+					fsm.Actions.Z()
+				}
+			}
+
+			type StateB struct {
+				BaseState
+			}
+
+			func NewStateB() StateB {
+				return StateB{BaseState{StateName: "b"}}
+			}
+			`,
+		)
+	})
+
 	t.Run("Complex FSM", func(t *testing.T) {
 		assertImplementedFSM(t, `
  			FSM: TwoCoinTurnstile
@@ -249,6 +973,11 @@ func implementFSM(input string) string {
 }
 
 func generateFSM(input string) statepattern.Node {
+	gen := statepattern.NewNodeGenerator()
+	return gen.Generate(optimizeFSM(input))
+}
+
+func optimizeFSM(input string) *optimizer.FSM {
 	builder := parser.NewSyntaxBuilder()
 	psr := parser.NewParser(builder)
 	lxr := lexer.NewLexer(psr)
@@ -260,10 +989,7 @@ func generateFSM(input string) statepattern.Node {
 	semanticFSM := analyzer.Analyze(parsedFSM)
 
 	opt := optimizer.New()
-	optimizedFSM := opt.Optimize(semanticFSM)
-
-	gen := statepattern.NewNodeGenerator()
-	return gen.Generate(optimizedFSM)
+	return opt.Optimize(semanticFSM)
 }
 
 var whitespaceRegex = regexp.MustCompile("\\s+")
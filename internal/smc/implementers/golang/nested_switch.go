@@ -0,0 +1,526 @@
+package golang
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/geisonbiazus/smc/internal/smc/generator/nestedswitch"
+)
+
+type SwitchImplementer struct {
+	pkg              string
+	result           string
+	className        string
+	actionParameters map[string][]nestedswitch.Parameter
+	hasParameters    bool
+	finalStates      map[string]bool
+	synchronized     bool
+	imports          []string
+}
+
+func NewSwitchImplementer(pkg string) *SwitchImplementer {
+	return &SwitchImplementer{
+		pkg: pkg,
+	}
+}
+
+func (i *SwitchImplementer) Implement(node nestedswitch.Node) string {
+	i.result = ""
+	i.className = ""
+	i.actionParameters = nil
+	i.hasParameters = false
+	i.finalStates = nil
+	i.synchronized = false
+	i.imports = nil
+
+	node.Accept(i)
+	return header(i.pkg, i.imports) + i.result
+}
+
+func (i *SwitchImplementer) VisitActionsInterfaceNode(node nestedswitch.ActionsInterfaceNode) {
+	i.result += "\n"
+	i.result += "type Actions interface {\n"
+
+	i.actionParameters = node.ActionParameters
+	for _, action := range node.Actions {
+		i.result += "  " + title(action) + "(" + switchParameterList(node.ActionParameters[action]) + ")\n"
+	}
+
+	if len(node.Guards) > 0 {
+		i.result += "  Guard(name string) bool\n"
+	}
+
+	i.result += "  UnhandledTransition(state string, event string)\n"
+	i.result += "}\n"
+}
+
+func (i *SwitchImplementer) VisitEnumNode(node nestedswitch.EnumNode) {
+	names := []string{}
+	values := []string{}
+	if node.HasNone {
+		names = append(names, "")
+		values = append(values, "no"+node.TypeName)
+	}
+	for _, value := range node.Values {
+		names = append(names, value)
+		values = append(values, node.TypeName+title(value))
+	}
+
+	i.result += "\n"
+	i.result += "type " + node.TypeName + " int\n"
+	i.result += "\n"
+	i.result += "const (\n"
+	for n, value := range values {
+		if n == 0 {
+			i.result += "  " + value + " " + node.TypeName + " = iota\n"
+		} else {
+			i.result += "  " + value + "\n"
+		}
+	}
+	i.result += ")\n"
+	i.result += "\n"
+	i.result += "var " + strings.ToLower(node.TypeName) + "Names = [...]string{" + quotedList(names) + "}\n"
+	i.result += "\n"
+	i.result += "func (v " + node.TypeName + ") String() string {\n"
+	i.result += "  return " + strings.ToLower(node.TypeName) + "Names[v]\n"
+	i.result += "}\n"
+}
+
+func (i *SwitchImplementer) VisitFSMClassNode(node nestedswitch.FSMClassNode) {
+	i.className = title(node.ClassName)
+	i.hasParameters = len(node.EventParameters) > 0
+	i.synchronized = len(node.Timers) > 0
+
+	i.writeFSMStruct(node)
+	if i.hasParameters {
+		i.writeEventParameters(node.EventParameters)
+	}
+	i.writeConstructor(node)
+
+	if len(node.HistoryStates) > 0 {
+		i.writeRemember(node.StateHistories)
+	}
+
+	if len(node.Timers) > 0 {
+		i.writeTimers(node.Timers, len(node.HistoryStates) > 0)
+	}
+
+	for _, region := range node.Regions {
+		i.writeRegion(region)
+	}
+
+	if len(node.FinalStates) > 0 {
+		i.writeCompletion(node.FinalStates)
+	}
+
+	for _, methodNode := range node.EventMethods {
+		methodNode.Accept(i)
+	}
+}
+
+func (i *SwitchImplementer) writeFSMStruct(node nestedswitch.FSMClassNode) {
+	i.result += "\n"
+	i.result += "type " + i.className + " struct {\n"
+	i.result += "  Actions Actions\n"
+	i.result += "  state State\n"
+	for _, region := range node.Regions {
+		i.result += "  " + switchRegionField(region.Name) + " State\n"
+	}
+	for _, state := range node.HistoryStates {
+		i.result += "  " + switchHistoryField(state) + " State\n"
+	}
+	if len(node.Timers) > 0 {
+		i.result += "  Clock Clock\n"
+		i.result += "  timers map[string]*armedTimer\n"
+		i.result += "  mu sync.Mutex\n"
+	}
+	i.result += "}\n"
+}
+
+func (i *SwitchImplementer) writeEventParameters(parameters map[string][]nestedswitch.Parameter) {
+	i.result += "\n"
+	i.result += "type eventParameters struct {\n"
+	for _, event := range sortedKeys(parameters) {
+		for _, p := range parameters[event] {
+			i.result += "  " + parameterField(event, p) + " " + p.Type + "\n"
+		}
+	}
+	i.result += "}\n"
+}
+
+func (i *SwitchImplementer) writeConstructor(node nestedswitch.FSMClassNode) {
+	hasTimers := len(node.Timers) > 0
+	hasSetup := hasTimers || len(node.HistoryStates) > 0
+
+	i.result += "\n"
+	if hasTimers {
+		i.result += "func New" + i.className + "(actions Actions) *" + i.className + " {\n"
+		i.result += "  return New" + i.className + "WithClock(actions, systemClock{})\n"
+		i.result += "}\n"
+		i.result += "\n"
+		i.result += "func New" + i.className + "WithClock(actions Actions, clock Clock) *" + i.className + " {\n"
+	} else {
+		i.result += "func New" + i.className + "(actions Actions) *" + i.className + " {\n"
+	}
+
+	if hasSetup {
+		i.result += "  f := &" + i.className + "{\n"
+	} else {
+		i.result += "  return &" + i.className + "{\n"
+	}
+	i.result += "    Actions: actions,\n"
+	i.result += "    state: State" + title(node.InitialState) + ",\n"
+	for _, name := range node.InitialRegions {
+		for _, region := range node.Regions {
+			if region.Name == name {
+				i.result += "    " + switchRegionField(name) + ": State" + title(region.InitialState) + ",\n"
+			}
+		}
+	}
+	if hasTimers {
+		i.result += "    Clock: clock,\n"
+		i.result += "    timers: map[string]*armedTimer{},\n"
+	}
+	i.result += "  }\n"
+
+	if len(node.InitialTimers) > 0 {
+		i.result += lock("  ", "f")
+	}
+	for _, timer := range node.InitialTimers {
+		i.result += "  f.start" + title(timer) + "()\n"
+	}
+	if len(node.HistoryStates) > 0 {
+		i.result += "  f.remember()\n"
+	}
+	if hasSetup {
+		i.result += "  return f\n"
+	}
+	i.result += "}\n"
+}
+
+func (i *SwitchImplementer) writeRemember(histories []nestedswitch.StateHistory) {
+	regions := []string{}
+	index := map[string]bool{}
+	for _, history := range histories {
+		if !index[history.Region] {
+			index[history.Region] = true
+			regions = append(regions, history.Region)
+		}
+	}
+
+	i.result += "\n"
+	i.result += "func (f *" + i.className + ") remember() {\n"
+	for _, region := range regions {
+		i.result += "  switch f." + switchRegionField(region) + " {\n"
+		for _, history := range histories {
+			if history.Region != region {
+				continue
+			}
+			i.result += "  case State" + title(history.StateName) + ":\n"
+			for _, state := range history.HistoryStates {
+				i.result += "    f." + switchHistoryField(state) + " = State" + title(history.StateName) + "\n"
+			}
+		}
+		i.result += "  }\n"
+	}
+	i.result += "}\n"
+}
+
+func (i *SwitchImplementer) writeTimers(timers []nestedswitch.TimerNode, recordsHistory bool) {
+	i.imports = append(i.imports, "sync", "time")
+	i.result += clockTypes()
+	i.result += namedTimerMethods(i.className)
+
+	for _, timer := range timers {
+		i.result += "\n"
+		i.result += "func (f *" + i.className + ") start" + title(timer.Event) + "() {\n"
+		i.result += "  f.startTimer(\"" + timer.Event + "\", " + goDuration(timer.Duration) + ", func() {\n"
+		i.writeDispatch("    ", "f."+switchRegionField(timer.Region), timer.Event, "eventParameters{}")
+		if recordsHistory {
+			i.result += "    f.remember()\n"
+		}
+		i.result += "  })\n"
+		i.result += "}\n"
+	}
+}
+
+func (i *SwitchImplementer) writeRegion(region nestedswitch.RegionNode) {
+	field := switchRegionField(region.Name)
+
+	i.result += "\n"
+	i.result += "func (f *" + i.className + ") enter" + title(region.Name) + "() {\n"
+	i.result += "  f." + field + " = State" + title(region.InitialState) + "\n"
+	for _, action := range region.EntryActions {
+		i.result += "  f.Actions." + title(action) + "()\n"
+	}
+	for _, timer := range region.InitialTimers {
+		i.result += "  f.start" + title(timer) + "()\n"
+	}
+	i.result += "}\n"
+
+	i.result += "\n"
+	i.result += "func (f *" + i.className + ") exit" + title(region.Name) + "() {\n"
+	for _, timer := range region.Timers {
+		i.result += "  f.stopTimer(\"" + timer + "\")\n"
+	}
+	if len(region.Exits) > 0 {
+		i.result += "  switch f." + field + " {\n"
+		for _, exit := range region.Exits {
+			i.result += "  case State" + title(exit.StateName) + ":\n"
+			for _, action := range exit.Actions {
+				i.result += "    f.Actions." + title(action) + "()\n"
+			}
+		}
+		i.result += "  }\n"
+	}
+	i.result += "  f." + field + " = noState\n"
+	i.result += "}\n"
+}
+
+func (i *SwitchImplementer) writeCompletion(finalStates []string) {
+	i.finalStates = map[string]bool{}
+	for _, state := range finalStates {
+		i.finalStates[state] = true
+	}
+
+	i.result += "\n"
+	i.result += "type Completer interface {\n"
+	i.result += "  Completed()\n"
+	i.result += "}\n"
+	i.result += "\n"
+	i.result += "func (f *" + i.className + ") IsFinished() bool {\n"
+	if i.synchronized {
+		i.result += lock("  ", "f")
+	}
+	i.result += "  switch f.state {\n"
+	i.result += "  case " + strings.Join(prefixed("State", finalStates), ", ") + ":\n"
+	i.result += "    return true\n"
+	i.result += "  }\n"
+	i.result += "  return false\n"
+	i.result += "}\n"
+	i.result += "\n"
+	i.result += "func (f *" + i.className + ") complete() {\n"
+	i.result += "  if c, ok := f.Actions.(Completer); ok {\n"
+	i.result += "    c.Completed()\n"
+	i.result += "  }\n"
+	i.result += "}\n"
+}
+
+func (i *SwitchImplementer) VisitEventMethodNode(node nestedswitch.EventMethodNode) {
+	parameters := "eventParameters{" + eventParameterFields(node.EventName, node.Parameters) + "}"
+
+	i.result += "\n"
+	i.result += "func (f *" + i.className + ") " + title(node.EventName) + "(" + switchParameterList(node.Parameters) + ") {\n"
+	if i.synchronized {
+		i.result += lock("  ", "f")
+	}
+	if len(node.Regions) > 0 {
+		i.writeRegionDispatch(node, parameters)
+	} else {
+		i.writeDispatch("  ", "f.state", node.EventName, parameters)
+	}
+	if node.RecordsHistory {
+		i.result += "  f.remember()\n"
+	}
+	i.result += "}\n"
+}
+
+func (i *SwitchImplementer) writeRegionDispatch(node nestedswitch.EventMethodNode, parameters string) {
+	i.result += "  handled := false\n"
+	for _, region := range node.Regions {
+		field := "f." + switchRegionField(region)
+		i.result += "  if " + field + " != noState && f.dispatch(" + i.dispatchArguments(field, node.EventName, parameters) + ") {\n"
+		i.result += "    handled = true\n"
+		i.result += "  }\n"
+	}
+	i.result += "  if !f.dispatch(" + i.dispatchArguments("f.state", node.EventName, parameters) + ") && !handled {\n"
+	i.result += "    f.Actions.UnhandledTransition(f.state.String(), Event" + title(node.EventName) + ".String())\n"
+	i.result += "  }\n"
+}
+
+func (i *SwitchImplementer) writeDispatch(indent, state, event, parameters string) {
+	i.result += indent + "if !f.dispatch(" + i.dispatchArguments(state, event, parameters) + ") {\n"
+	i.result += indent + "  f.Actions.UnhandledTransition(" + state + ".String(), Event" + title(event) + ".String())\n"
+	i.result += indent + "}\n"
+}
+
+func (i *SwitchImplementer) dispatchArguments(state, event, parameters string) string {
+	arguments := state + ", Event" + title(event)
+	if i.hasParameters {
+		arguments += ", " + parameters
+	}
+	return arguments
+}
+
+func (i *SwitchImplementer) VisitDispatchNode(node nestedswitch.DispatchNode) {
+	parameters := "state State, event Event"
+	if node.HasParameters {
+		parameters += ", p eventParameters"
+	}
+
+	i.result += "\n"
+	i.result += "func (f *" + i.className + ") dispatch(" + parameters + ") bool {\n"
+	i.result += "  switch state {\n"
+	for _, stateNode := range node.States {
+		stateNode.Accept(i)
+	}
+	i.result += "  }\n"
+	i.result += "  return false\n"
+	i.result += "}\n"
+}
+
+func (i *SwitchImplementer) VisitStateCaseNode(node nestedswitch.StateCaseNode) {
+	i.result += "  case State" + title(node.StateName) + ":\n"
+	i.result += "    switch event {\n"
+	for _, eventNode := range node.Events {
+		eventNode.Accept(i)
+	}
+	i.result += "    }\n"
+}
+
+func (i *SwitchImplementer) VisitEventCaseNode(node nestedswitch.EventCaseNode) {
+	i.result += "    case Event" + title(node.EventName) + ":\n"
+	for _, alternative := range node.Alternatives {
+		if alternative.Guard == "" {
+			i.writeTarget("      ", node, alternative)
+			i.result += "      return true\n"
+			return
+		}
+
+		i.result += "      if f.Actions.Guard(\"" + alternative.Guard + "\") {\n"
+		i.writeTarget("        ", node, alternative)
+		i.result += "        return true\n"
+		i.result += "      }\n"
+	}
+}
+
+func (i *SwitchImplementer) writeTarget(indent string, node nestedswitch.EventCaseNode, t nestedswitch.Transition) {
+	if t.History == "" {
+		i.writeTransition(indent, node, t)
+		return
+	}
+
+	i.result += indent + "switch f." + switchHistoryField(t.History) + " {\n"
+	for _, h := range t.HistoryTargets {
+		i.result += indent + "case State" + title(h.Memory) + ":\n"
+		i.writeTransition(indent+"  ", node, h.Transition)
+	}
+	i.result += indent + "default:\n"
+	i.writeTransition(indent+"  ", node, t)
+	i.result += indent + "}\n"
+}
+
+func (i *SwitchImplementer) writeTransition(indent string, node nestedswitch.EventCaseNode, t nestedswitch.Transition) {
+	for _, timer := range t.StoppedTimers {
+		i.result += indent + "f.stopTimer(\"" + timer + "\")\n"
+	}
+
+	for _, region := range t.ExitedRegions {
+		i.result += indent + "f.exit" + title(region) + "()\n"
+	}
+
+	if t.NextState != "" {
+		i.result += indent + "f." + switchRegionField(node.Region) + " = State" + title(t.NextState) + "\n"
+	}
+
+	for _, action := range t.Actions {
+		i.result += indent + "f.Actions." + title(action) + "(" + i.actionArguments(action, node) + ")\n"
+	}
+
+	for _, region := range t.EnteredRegions {
+		i.result += indent + "f.enter" + title(region) + "()\n"
+	}
+
+	for _, timer := range t.StartedTimers {
+		i.result += indent + "f.start" + title(timer) + "()\n"
+	}
+
+	if node.Region == "" && i.finalStates[t.NextState] {
+		i.result += indent + "f.complete()\n"
+	}
+
+	if len(t.Branches) > 0 {
+		i.writeBranches(indent, node, t.Branches)
+	}
+}
+
+func (i *SwitchImplementer) writeBranches(indent string, node nestedswitch.EventCaseNode, branches []nestedswitch.Transition) {
+	for n, branch := range branches {
+		switch {
+		case n == 0 && branch.Guard == "":
+			i.writeTransition(indent, node, branch)
+			return
+		case n == 0:
+			i.result += indent + "if f.Actions.Guard(\"" + branch.Guard + "\") {\n"
+		case branch.Guard == "":
+			i.result += indent + "} else {\n"
+		default:
+			i.result += indent + "} else if f.Actions.Guard(\"" + branch.Guard + "\") {\n"
+		}
+		i.writeTransition(indent+"  ", node, branch)
+
+		if branch.Guard == "" {
+			break
+		}
+	}
+	i.result += indent + "}\n"
+}
+
+func (i *SwitchImplementer) actionArguments(action string, node nestedswitch.EventCaseNode) string {
+	if len(i.actionParameters[action]) == 0 {
+		return ""
+	}
+
+	list := []string{}
+	for _, p := range node.Parameters {
+		list = append(list, "p."+parameterField(node.EventName, p))
+	}
+	return strings.Join(list, ", ")
+}
+
+func eventParameterFields(event string, parameters []nestedswitch.Parameter) string {
+	list := []string{}
+	for _, p := range parameters {
+		list = append(list, parameterField(event, p)+": "+p.Name)
+	}
+	return strings.Join(list, ", ")
+}
+
+func parameterField(event string, p nestedswitch.Parameter) string {
+	return lowerFirst(event) + title(p.Name)
+}
+
+func switchParameterList(parameters []nestedswitch.Parameter) string {
+	list := []string{}
+	for _, p := range parameters {
+		list = append(list, p.Name+" "+p.Type)
+	}
+	return strings.Join(list, ", ")
+}
+
+func switchRegionField(region string) string {
+	if region == "" {
+		return "state"
+	}
+	return lowerFirst(region) + "State"
+}
+
+func switchHistoryField(state string) string {
+	return lowerFirst(state) + "History"
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func sortedKeys(parameters map[string][]nestedswitch.Parameter) []string {
+	keys := []string{}
+	for key := range parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,158 @@
+package golang
+
+import (
+	"testing"
+
+	"github.com/geisonbiazus/smc/internal/smc/generator/nestedswitch"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSwitchImplementer(t *testing.T) {
+	t.Run("Guards and event parameters", func(t *testing.T) {
+		assertImplementedSwitch(t,
+			"FSM: fsm Initial: locked Event: coin(n int) { locked { coin [ok] unlocked unlock \n coin - refund } unlocked pass locked - }",
+			`package fsm
+
+			type Actions interface {
+				Unlock(n int)
+				Refund(n int)
+				Guard(name string) bool
+				UnhandledTransition(state string, event string)
+			}
+
+			type State int
+
+			const (
+				StateLocked State = iota
+				StateUnlocked
+			)
+
+			var stateNames = [...]string{"locked", "unlocked"}
+
+			func (v State) String() string {
+				return stateNames[v]
+			}
+
+			type Event int
+
+			const (
+				EventCoin Event = iota
+				EventPass
+			)
+
+			var eventNames = [...]string{"coin", "pass"}
+
+			func (v Event) String() string {
+				return eventNames[v]
+			}
+
+			type Fsm struct {
+				Actions Actions
+				state State
+			}
+
+			type eventParameters struct {
+				coinN int
+			}
+
+			func NewFsm(actions Actions) *Fsm {
+				return &Fsm{
+					Actions: actions,
+					state: StateLocked,
+				}
+			}
+
+			func (f *Fsm) Coin(n int) {
+				if !f.dispatch(f.state, EventCoin, eventParameters{coinN: n}) {
+					f.Actions.UnhandledTransition(f.state.String(), EventCoin.String())
+				}
+			}
+
+			func (f *Fsm) Pass() {
+				if !f.dispatch(f.state, EventPass, eventParameters{}) {
+					f.Actions.UnhandledTransition(f.state.String(), EventPass.String())
+				}
+			}
+
+			func (f *Fsm) dispatch(state State, event Event, p eventParameters) bool {
+				switch state {
+				case StateLocked:
+					switch event {
+					case EventCoin:
+						if f.Actions.Guard("ok") {
+							f.state = StateUnlocked
+							f.Actions.Unlock(p.coinN)
+							return true
+						}
+						f.Actions.Refund(p.coinN)
+						return true
+					}
+				case StateUnlocked:
+					switch event {
+					case EventPass:
+						f.state = StateLocked
+						return true
+					}
+				}
+				return false
+			}
+			`,
+		)
+	})
+
+	t.Run("Regions", func(t *testing.T) {
+		result := removeSpacing(implementSwitch("FSM: fsm Initial: a { a { e b - } b { [r] { x >on <off { f y - } y { f x - } } e a - } }"))
+		assert.Contains(t, result, removeSpacing(`
+			const (
+				noState State = iota
+				StateA
+		`))
+		assert.Contains(t, result, removeSpacing(`
+			func (f *Fsm) F() {
+				handled := false
+				if f.rState != noState && f.dispatch(f.rState, EventF) {
+					handled = true
+				}
+				if !f.dispatch(f.state, EventF) && !handled {
+					f.Actions.UnhandledTransition(f.state.String(), EventF.String())
+				}
+			}
+		`))
+		assert.Contains(t, result, removeSpacing(`
+			func (f *Fsm) exitR() {
+				switch f.rState {
+				case StateX:
+					f.Actions.Off()
+				}
+				f.rState = noState
+			}
+		`))
+	})
+
+	t.Run("Timers", func(t *testing.T) {
+		result := removeSpacing(implementSwitch("FSM: fsm Initial: a { a { e b - \n after 5s b - } \n b - - - }"))
+		assert.Contains(t, result, removeSpacing(`
+			func (f *Fsm) E() {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				if !f.dispatch(f.state, EventE) {
+		`))
+		assert.Contains(t, result, removeSpacing(`
+			t.Timer = f.Clock.AfterFunc(d, func() {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+		`))
+	})
+}
+
+func assertImplementedSwitch(t *testing.T, input, expected string) {
+	t.Helper()
+	assert.Equal(t, removeSpacing(expected), removeSpacing(implementSwitch(input)))
+}
+
+func implementSwitch(input string) string {
+	implementer := NewSwitchImplementer("fsm")
+	optimizedFSM := optimizeFSM(input)
+
+	return implementer.Implement(nestedswitch.NewNodeGenerator().Generate(optimizedFSM))
+}
@@ -0,0 +1,621 @@
+package golang
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/geisonbiazus/smc/internal/smc/generator/table"
+)
+
+type TableImplementer struct {
+	pkg     string
+	result  string
+	table   *table.Table
+	imports []string
+}
+
+func NewTableImplementer(pkg string) *TableImplementer {
+	return &TableImplementer{
+		pkg: pkg,
+	}
+}
+
+func (i *TableImplementer) Implement(t *table.Table) string {
+	i.result = ""
+	i.table = t
+	i.imports = nil
+
+	i.writeActionsInterface()
+	i.writeEnums()
+	i.writeTransitionType()
+	i.writeTransitionTable()
+	i.writeFSMStruct()
+	i.writeConstructor()
+	i.writeEventMethods()
+	i.writeEngine()
+	if len(t.HistoryStates) > 0 {
+		i.writeHistory()
+	}
+	if len(t.Regions) > 0 {
+		i.writeRegions()
+	}
+	if len(t.Timers) > 0 {
+		i.writeTimers()
+	}
+	if len(t.FinalStates) > 0 {
+		i.writeCompletion()
+	}
+	i.writePerform()
+	return header(i.pkg, i.imports) + i.result
+}
+
+func (i *TableImplementer) className() string {
+	return title(i.table.Name)
+}
+
+func (i *TableImplementer) hasGuards() bool {
+	return len(i.table.Guards) > 0
+}
+
+func (i *TableImplementer) writeActionsInterface() {
+	i.result += "\n"
+	i.result += "type Actions interface {\n"
+	for _, action := range i.table.Actions {
+		i.result += "  " + title(action) + "(" + tableParameterList(i.table.ActionParameters[action]) + ")\n"
+	}
+	if i.hasGuards() {
+		i.result += "  Guard(name string) bool\n"
+	}
+	i.result += "  UnhandledTransition(state string, event string)\n"
+	i.result += "}\n"
+}
+
+func (i *TableImplementer) writeEnums() {
+	i.writeEnum("state", append([]string{"noState"}, prefixed("state", i.table.States)...))
+	i.result += "\n"
+	i.result += "var stateNames = [...]string{" + quotedList(append([]string{""}, i.table.States...)) + "}\n"
+
+	i.writeEnum("event", prefixed("event", i.table.Events))
+	i.result += "\n"
+	i.result += "var eventNames = [...]string{" + quotedList(i.table.Events) + "}\n"
+
+	i.writeEnum("action", prefixed("action", i.table.Actions))
+
+	regions := []string{"mainRegion"}
+	for _, region := range i.table.Regions {
+		regions = append(regions, "region"+title(region.Name))
+	}
+	i.writeEnum("region", regions)
+
+	if len(i.table.HistoryStates) > 0 {
+		i.writeEnum("history", prefixed("history", i.table.HistoryStates))
+	}
+
+	if len(i.table.Timers) > 0 {
+		var timers []string
+		for _, timer := range i.table.Timers {
+			timers = append(timers, timer.Event)
+		}
+		i.writeEnum("timer", prefixed("timer", timers))
+	}
+}
+
+func (i *TableImplementer) writeEnum(typeName string, names []string) {
+	i.result += "\n"
+	i.result += "type " + typeName + " int\n"
+	i.result += "\n"
+	i.result += "const (\n"
+	for n, name := range names {
+		if n == 0 {
+			i.result += "  " + name + " " + typeName + " = iota\n"
+		} else {
+			i.result += "  " + name + "\n"
+		}
+	}
+	i.result += ")\n"
+}
+
+func (i *TableImplementer) writeTransitionType() {
+	i.result += "\n"
+	i.result += "type transition struct {\n"
+	if i.hasGuards() {
+		i.result += "  guard string\n"
+	}
+	i.result += "  nextState state\n"
+	i.result += "  actions []action\n"
+	if len(i.table.HistoryStates) > 0 {
+		i.result += "  history history\n"
+		i.result += "  historyTargets map[state]transition\n"
+	}
+	if len(i.table.Regions) > 0 {
+		i.result += "  exitedRegions []region\n"
+		i.result += "  enteredRegions []region\n"
+	}
+	if len(i.table.Timers) > 0 {
+		i.result += "  stoppedTimers []timer\n"
+		i.result += "  startedTimers []timer\n"
+	}
+	if i.table.HasBranches() {
+		i.result += "  branches []transition\n"
+	}
+	i.result += "}\n"
+}
+
+func (i *TableImplementer) writeTransitionTable() {
+	i.result += "\n"
+	i.result += "var transitions = map[state]map[event][]transition{\n"
+	for _, row := range i.table.Rows {
+		if len(row.Cells) == 0 {
+			continue
+		}
+		i.result += "  state" + title(row.StateName) + ": {\n"
+		for _, cell := range row.Cells {
+			i.result += "    event" + title(cell.Event) + ": {" + transitionList(cell.Transitions) + "},\n"
+		}
+		i.result += "  },\n"
+	}
+	i.result += "}\n"
+}
+
+func transitionList(transitions []table.Transition) string {
+	list := []string{}
+	for _, t := range transitions {
+		list = append(list, transitionLiteral(t))
+	}
+	return strings.Join(list, ", ")
+}
+
+func transitionLiteral(t table.Transition) string {
+	fields := []string{}
+	if t.Guard != "" {
+		fields = append(fields, "guard: \""+t.Guard+"\"")
+	}
+	if t.NextState != "" {
+		fields = append(fields, "nextState: state"+title(t.NextState))
+	}
+	if len(t.Actions) > 0 {
+		fields = append(fields, "actions: []action{"+strings.Join(prefixed("action", t.Actions), ", ")+"}")
+	}
+	if t.History != "" {
+		fields = append(fields, "history: history"+title(t.History))
+		fields = append(fields, "historyTargets: map[state]transition{"+historyTargetList(t.HistoryTargets)+"}")
+	}
+	if len(t.ExitedRegions) > 0 {
+		fields = append(fields, "exitedRegions: []region{"+strings.Join(prefixed("region", t.ExitedRegions), ", ")+"}")
+	}
+	if len(t.EnteredRegions) > 0 {
+		fields = append(fields, "enteredRegions: []region{"+strings.Join(prefixed("region", t.EnteredRegions), ", ")+"}")
+	}
+	if len(t.StoppedTimers) > 0 {
+		fields = append(fields, "stoppedTimers: []timer{"+strings.Join(prefixed("timer", t.StoppedTimers), ", ")+"}")
+	}
+	if len(t.StartedTimers) > 0 {
+		fields = append(fields, "startedTimers: []timer{"+strings.Join(prefixed("timer", t.StartedTimers), ", ")+"}")
+	}
+	if len(t.Branches) > 0 {
+		fields = append(fields, "branches: []transition{"+transitionList(t.Branches)+"}")
+	}
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+func historyTargetList(targets []table.HistoryTarget) string {
+	list := []string{}
+	for _, target := range targets {
+		list = append(list, "state"+title(target.Memory)+": "+transitionLiteral(target.Transition))
+	}
+	return strings.Join(list, ", ")
+}
+
+func (i *TableImplementer) writeFSMStruct() {
+	i.result += "\n"
+	i.result += "type " + i.className() + " struct {\n"
+	i.result += "  Actions Actions\n"
+	i.result += "  states [" + strconv.Itoa(len(i.table.Regions)+1) + "]state\n"
+	if len(i.table.HistoryStates) > 0 {
+		i.result += "  memory [" + strconv.Itoa(len(i.table.HistoryStates)) + "]state\n"
+	}
+	if len(i.table.Timers) > 0 {
+		i.result += "  Clock Clock\n"
+		i.result += "  timers map[timer]*armedTimer\n"
+		i.result += "  mu sync.Mutex\n"
+	}
+	i.result += "}\n"
+}
+
+func (i *TableImplementer) writeConstructor() {
+	className := i.className()
+	hasTimers := len(i.table.Timers) > 0
+
+	i.result += "\n"
+	if hasTimers {
+		i.result += "func New" + className + "(actions Actions) *" + className + " {\n"
+		i.result += "  return New" + className + "WithClock(actions, systemClock{})\n"
+		i.result += "}\n"
+		i.result += "\n"
+		i.result += "func New" + className + "WithClock(actions Actions, clock Clock) *" + className + " {\n"
+		i.result += "  f := &" + className + "{Actions: actions, Clock: clock, timers: map[timer]*armedTimer{}}\n"
+	} else {
+		i.result += "func New" + className + "(actions Actions) *" + className + " {\n"
+		i.result += "  f := &" + className + "{Actions: actions}\n"
+	}
+
+	i.result += "  f.states[mainRegion] = state" + title(i.table.InitialState) + "\n"
+	for _, name := range i.table.InitialRegions {
+		for _, region := range i.table.Regions {
+			if region.Name == name {
+				i.result += "  f.states[region" + title(name) + "] = state" + title(region.InitialState) + "\n"
+			}
+		}
+	}
+	if len(i.table.InitialTimers) > 0 {
+		i.result += lock("  ", "f")
+	}
+	for _, timer := range i.table.InitialTimers {
+		i.result += "  f.startTimer(timer" + title(timer) + ")\n"
+	}
+	if len(i.table.HistoryStates) > 0 {
+		i.result += "  f.remember()\n"
+	}
+	i.result += "  return f\n"
+	i.result += "}\n"
+}
+
+func (i *TableImplementer) writeEventMethods() {
+	for _, event := range i.table.Events {
+		if i.isTimerEvent(event) {
+			continue
+		}
+
+		parameters := i.table.EventParameters[event]
+		arguments := "event" + title(event)
+		if len(parameters) > 0 {
+			arguments += ", " + tableArgumentList(parameters)
+		}
+
+		i.result += "\n"
+		i.result += "func (f *" + i.className() + ") " + title(event) + "(" + tableParameterList(parameters) + ") {\n"
+		i.result += "  f.handle(" + arguments + ")\n"
+		i.result += "}\n"
+	}
+}
+
+func (i *TableImplementer) isTimerEvent(event string) bool {
+	for _, timer := range i.table.Timers {
+		if timer.Event == event {
+			return true
+		}
+	}
+	return false
+}
+
+func (i *TableImplementer) writeEngine() {
+	className := i.className()
+
+	i.result += "\n"
+	i.result += "func (f *" + className + ") handle(e event, args ...interface{}) {\n"
+	if len(i.table.Timers) > 0 {
+		i.result += lock("  ", "f")
+	}
+	if len(i.table.Regions) > 0 {
+		i.result += "  handled := false\n"
+		i.result += "  for _, r := range eventRegions[e] {\n"
+		i.result += "    if f.states[r] != noState && f.dispatch(r, e, args) {\n"
+		i.result += "      handled = true\n"
+		i.result += "    }\n"
+		i.result += "  }\n"
+		i.result += "  if !f.dispatch(mainRegion, e, args) && !handled {\n"
+	} else {
+		i.result += "  if !f.dispatch(mainRegion, e, args) {\n"
+	}
+	i.result += "    f.unhandled(mainRegion, e)\n"
+	i.result += "  }\n"
+	if len(i.table.HistoryStates) > 0 {
+		i.result += "  f.remember()\n"
+	}
+	i.result += "}\n"
+
+	i.result += "\n"
+	i.result += "func (f *" + className + ") dispatch(r region, e event, args []interface{}) bool {\n"
+	i.result += "  if t, ok := f.choose(transitions[f.states[r]][e]); ok {\n"
+	i.result += "    f.take(r, t, args)\n"
+	i.result += "    return true\n"
+	i.result += "  }\n"
+	i.result += "  return false\n"
+	i.result += "}\n"
+
+	i.result += "\n"
+	i.result += "func (f *" + className + ") unhandled(r region, e event) {\n"
+	i.result += "  f.Actions.UnhandledTransition(stateNames[f.states[r]], eventNames[e])\n"
+	i.result += "}\n"
+
+	i.result += "\n"
+	i.result += "func (f *" + className + ") choose(alternatives []transition) (transition, bool) {\n"
+	if i.hasGuards() {
+		i.result += "  for _, t := range alternatives {\n"
+		i.result += "    if t.guard == \"\" || f.Actions.Guard(t.guard) {\n"
+		i.result += "      return t, true\n"
+		i.result += "    }\n"
+		i.result += "  }\n"
+		i.result += "  return transition{}, false\n"
+	} else {
+		i.result += "  if len(alternatives) == 0 {\n"
+		i.result += "    return transition{}, false\n"
+		i.result += "  }\n"
+		i.result += "  return alternatives[0], true\n"
+	}
+	i.result += "}\n"
+
+	i.writeTake()
+}
+
+func (i *TableImplementer) writeTake() {
+	i.result += "\n"
+	i.result += "func (f *" + i.className() + ") take(r region, t transition, args []interface{}) {\n"
+	if len(i.table.HistoryStates) > 0 {
+		i.result += "  if target, ok := t.historyTargets[f.memory[t.history]]; ok {\n"
+		i.result += "    t = target\n"
+		i.result += "  }\n"
+	}
+	if len(i.table.Timers) > 0 {
+		i.result += "  for _, tm := range t.stoppedTimers {\n"
+		i.result += "    f.stopTimer(tm)\n"
+		i.result += "  }\n"
+	}
+	if len(i.table.Regions) > 0 {
+		i.result += "  for _, exited := range t.exitedRegions {\n"
+		i.result += "    f.exit(exited)\n"
+		i.result += "  }\n"
+	}
+	i.result += "  if t.nextState != noState {\n"
+	i.result += "    f.states[r] = t.nextState\n"
+	i.result += "  }\n"
+	i.result += "  for _, a := range t.actions {\n"
+	i.result += "    f.perform(a, args)\n"
+	i.result += "  }\n"
+	if len(i.table.Regions) > 0 {
+		i.result += "  for _, entered := range t.enteredRegions {\n"
+		i.result += "    f.enter(entered)\n"
+		i.result += "  }\n"
+	}
+	if len(i.table.Timers) > 0 {
+		i.result += "  for _, tm := range t.startedTimers {\n"
+		i.result += "    f.startTimer(tm)\n"
+		i.result += "  }\n"
+	}
+	if len(i.table.FinalStates) > 0 {
+		i.result += "  if r == mainRegion && finalStates[t.nextState] {\n"
+		i.result += "    f.complete()\n"
+		i.result += "  }\n"
+	}
+	if i.table.HasBranches() {
+		i.result += "  if branch, ok := f.choose(t.branches); ok {\n"
+		i.result += "    f.take(r, branch, args)\n"
+		i.result += "  }\n"
+	}
+	i.result += "}\n"
+}
+
+func (i *TableImplementer) writeHistory() {
+	i.result += "\n"
+	i.result += "var stateHistories = map[state][]history{\n"
+	for _, h := range i.table.StateHistories {
+		i.result += "  state" + title(h.StateName) + ": {" + strings.Join(prefixed("history", h.HistoryStates), ", ") + "},\n"
+	}
+	i.result += "}\n"
+	i.result += "\n"
+	i.result += "func (f *" + i.className() + ") remember() {\n"
+	i.result += "  for _, s := range f.states {\n"
+	i.result += "    for _, h := range stateHistories[s] {\n"
+	i.result += "      f.memory[h] = s\n"
+	i.result += "    }\n"
+	i.result += "  }\n"
+	i.result += "}\n"
+}
+
+func (i *TableImplementer) writeRegions() {
+	hasTimers := len(i.table.Timers) > 0
+
+	i.result += "\n"
+	i.result += "type regionSpec struct {\n"
+	i.result += "  initialState state\n"
+	i.result += "  entryActions []action\n"
+	i.result += "  exitActions map[state][]action\n"
+	if hasTimers {
+		i.result += "  timers []timer\n"
+		i.result += "  initialTimers []timer\n"
+	}
+	i.result += "}\n"
+	i.result += "\n"
+	i.result += "var regionSpecs = [...]regionSpec{\n"
+	for _, region := range i.table.Regions {
+		fields := []string{"initialState: state" + title(region.InitialState)}
+		if len(region.EntryActions) > 0 {
+			fields = append(fields, "entryActions: []action{"+strings.Join(prefixed("action", region.EntryActions), ", ")+"}")
+		}
+		if len(region.Exits) > 0 {
+			exits := []string{}
+			for _, exit := range region.Exits {
+				exits = append(exits, "state"+title(exit.StateName)+": {"+strings.Join(prefixed("action", exit.Actions), ", ")+"}")
+			}
+			fields = append(fields, "exitActions: map[state][]action{"+strings.Join(exits, ", ")+"}")
+		}
+		if len(region.Timers) > 0 {
+			fields = append(fields, "timers: []timer{"+strings.Join(prefixed("timer", region.Timers), ", ")+"}")
+		}
+		if len(region.InitialTimers) > 0 {
+			fields = append(fields, "initialTimers: []timer{"+strings.Join(prefixed("timer", region.InitialTimers), ", ")+"}")
+		}
+		i.result += "  region" + title(region.Name) + ": {" + strings.Join(fields, ", ") + "},\n"
+	}
+	i.result += "}\n"
+
+	i.result += "\n"
+	i.result += "var eventRegions = map[event][]region{\n"
+	for _, event := range i.table.Events {
+		var regions []string
+		for _, region := range i.table.Regions {
+			for _, e := range region.Events {
+				if e == event {
+					regions = append(regions, region.Name)
+				}
+			}
+		}
+		if len(regions) > 0 {
+			i.result += "  event" + title(event) + ": {" + strings.Join(prefixed("region", regions), ", ") + "},\n"
+		}
+	}
+	i.result += "}\n"
+
+	i.result += "\n"
+	i.result += "func (f *" + i.className() + ") enter(r region) {\n"
+	i.result += "  f.states[r] = regionSpecs[r].initialState\n"
+	i.result += "  for _, a := range regionSpecs[r].entryActions {\n"
+	i.result += "    f.perform(a, nil)\n"
+	i.result += "  }\n"
+	if hasTimers {
+		i.result += "  for _, tm := range regionSpecs[r].initialTimers {\n"
+		i.result += "    f.startTimer(tm)\n"
+		i.result += "  }\n"
+	}
+	i.result += "}\n"
+	i.result += "\n"
+	i.result += "func (f *" + i.className() + ") exit(r region) {\n"
+	if hasTimers {
+		i.result += "  for _, tm := range regionSpecs[r].timers {\n"
+		i.result += "    f.stopTimer(tm)\n"
+		i.result += "  }\n"
+	}
+	i.result += "  for _, a := range regionSpecs[r].exitActions[f.states[r]] {\n"
+	i.result += "    f.perform(a, nil)\n"
+	i.result += "  }\n"
+	i.result += "  f.states[r] = noState\n"
+	i.result += "}\n"
+}
+
+func (i *TableImplementer) writeTimers() {
+	i.imports = append(i.imports, "sync", "time")
+	i.result += clockTypes()
+
+	i.result += "\n"
+	i.result += "type timerSpec struct {\n"
+	i.result += "  event event\n"
+	i.result += "  region region\n"
+	i.result += "  duration time.Duration\n"
+	i.result += "}\n"
+	i.result += "\n"
+	i.result += "var timerSpecs = [...]timerSpec{\n"
+	for _, timer := range i.table.Timers {
+		region := "mainRegion"
+		if timer.Region != "" {
+			region = "region" + title(timer.Region)
+		}
+		i.result += "  timer" + title(timer.Event) + ": {event: event" + title(timer.Event) + ", region: " + region + ", duration: " + goDuration(timer.Duration) + "},\n"
+	}
+	i.result += "}\n"
+
+	i.result += "\n"
+	i.result += "func (f *" + i.className() + ") startTimer(tm timer) {\n"
+	i.result += "  spec := timerSpecs[tm]\n"
+	i.result += "  armed := &armedTimer{}\n"
+	i.result += "  f.timers[tm] = armed\n"
+	i.result += "  armed.Timer = f.Clock.AfterFunc(spec.duration, func() {\n"
+	i.result += lock("    ", "f")
+	i.result += "    if f.timers[tm] == armed {\n"
+	i.result += "      delete(f.timers, tm)\n"
+	i.result += "      if !f.dispatch(spec.region, spec.event, nil) {\n"
+	i.result += "        f.unhandled(spec.region, spec.event)\n"
+	i.result += "      }\n"
+	if len(i.table.HistoryStates) > 0 {
+		i.result += "      f.remember()\n"
+	}
+	i.result += "    }\n"
+	i.result += "  })\n"
+	i.result += "}\n"
+	i.result += "\n"
+	i.result += "func (f *" + i.className() + ") stopTimer(tm timer) {\n"
+	i.result += "  if armed, ok := f.timers[tm]; ok {\n"
+	i.result += "    delete(f.timers, tm)\n"
+	i.result += "    armed.Stop()\n"
+	i.result += "  }\n"
+	i.result += "}\n"
+}
+
+func (i *TableImplementer) writeCompletion() {
+	className := i.className()
+
+	i.result += "\n"
+	i.result += "var finalStates = map[state]bool{\n"
+	for _, state := range i.table.FinalStates {
+		i.result += "  state" + title(state) + ": true,\n"
+	}
+	i.result += "}\n"
+	i.result += "\n"
+	i.result += "type Completer interface {\n"
+	i.result += "  Completed()\n"
+	i.result += "}\n"
+	i.result += "\n"
+	i.result += "func (f *" + className + ") IsFinished() bool {\n"
+	if len(i.table.Timers) > 0 {
+		i.result += lock("  ", "f")
+	}
+	i.result += "  return finalStates[f.states[mainRegion]]\n"
+	i.result += "}\n"
+	i.result += "\n"
+	i.result += "func (f *" + className + ") complete() {\n"
+	i.result += "  if c, ok := f.Actions.(Completer); ok {\n"
+	i.result += "    c.Completed()\n"
+	i.result += "  }\n"
+	i.result += "}\n"
+}
+
+func (i *TableImplementer) writePerform() {
+	i.result += "\n"
+	i.result += "func (f *" + i.className() + ") perform(a action, args []interface{}) {\n"
+	i.result += "  switch a {\n"
+	for _, action := range i.table.Actions {
+		i.result += "  case action" + title(action) + ":\n"
+		i.result += "    f.Actions." + title(action) + "(" + assertedArguments(i.table.ActionParameters[action]) + ")\n"
+	}
+	i.result += "  }\n"
+	i.result += "}\n"
+}
+
+func assertedArguments(parameters []table.Parameter) string {
+	list := []string{}
+	for n, p := range parameters {
+		list = append(list, "args["+strconv.Itoa(n)+"].("+p.Type+")")
+	}
+	return strings.Join(list, ", ")
+}
+
+func tableParameterList(parameters []table.Parameter) string {
+	list := []string{}
+	for _, p := range parameters {
+		list = append(list, p.Name+" "+p.Type)
+	}
+	return strings.Join(list, ", ")
+}
+
+func tableArgumentList(parameters []table.Parameter) string {
+	list := []string{}
+	for _, p := range parameters {
+		list = append(list, p.Name)
+	}
+	return strings.Join(list, ", ")
+}
+
+func prefixed(prefix string, names []string) []string {
+	list := []string{}
+	for _, name := range names {
+		list = append(list, prefix+title(name))
+	}
+	return list
+}
+
+func quotedList(names []string) string {
+	list := []string{}
+	for _, name := range names {
+		list = append(list, "\""+name+"\"")
+	}
+	return strings.Join(list, ", ")
+}
@@ -0,0 +1,184 @@
+package golang
+
+import (
+	"testing"
+
+	"github.com/geisonbiazus/smc/internal/smc/generator/table"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableImplementer(t *testing.T) {
+	t.Run("Guards and event parameters", func(t *testing.T) {
+		assertImplementedTable(t,
+			"FSM: fsm Initial: locked Event: coin(n int) { locked { coin [ok] unlocked unlock \n coin - refund } unlocked pass locked - }",
+			`package fsm
+
+			type Actions interface {
+				Unlock(n int)
+				Refund(n int)
+				Guard(name string) bool
+				UnhandledTransition(state string, event string)
+			}
+
+			type state int
+
+			const (
+				noState state = iota
+				stateLocked
+				stateUnlocked
+			)
+
+			var stateNames = [...]string{"", "locked", "unlocked"}
+
+			type event int
+
+			const (
+				eventCoin event = iota
+				eventPass
+			)
+
+			var eventNames = [...]string{"coin", "pass"}
+
+			type action int
+
+			const (
+				actionUnlock action = iota
+				actionRefund
+			)
+
+			type region int
+
+			const (
+				mainRegion region = iota
+			)
+
+			type transition struct {
+				guard string
+				nextState state
+				actions []action
+			}
+
+			var transitions = map[state]map[event][]transition{
+				stateLocked: {
+					eventCoin: {{guard: "ok", nextState: stateUnlocked, actions: []action{actionUnlock}}, {actions: []action{actionRefund}}},
+				},
+				stateUnlocked: {
+					eventPass: {{nextState: stateLocked}},
+				},
+			}
+
+			type Fsm struct {
+				Actions Actions
+				states [1]state
+			}
+
+			func NewFsm(actions Actions) *Fsm {
+				f := &Fsm{Actions: actions}
+				f.states[mainRegion] = stateLocked
+				return f
+			}
+
+			func (f *Fsm) Coin(n int) {
+				f.handle(eventCoin, n)
+			}
+
+			func (f *Fsm) Pass() {
+				f.handle(eventPass)
+			}
+
+			func (f *Fsm) handle(e event, args ...interface{}) {
+				if !f.dispatch(mainRegion, e, args) {
+					f.unhandled(mainRegion, e)
+				}
+			}
+
+			func (f *Fsm) dispatch(r region, e event, args []interface{}) bool {
+				if t, ok := f.choose(transitions[f.states[r]][e]); ok {
+					f.take(r, t, args)
+					return true
+				}
+				return false
+			}
+
+			func (f *Fsm) unhandled(r region, e event) {
+				f.Actions.UnhandledTransition(stateNames[f.states[r]], eventNames[e])
+			}
+
+			func (f *Fsm) choose(alternatives []transition) (transition, bool) {
+				for _, t := range alternatives {
+					if t.guard == "" || f.Actions.Guard(t.guard) {
+						return t, true
+					}
+				}
+				return transition{}, false
+			}
+
+			func (f *Fsm) take(r region, t transition, args []interface{}) {
+				if t.nextState != noState {
+					f.states[r] = t.nextState
+				}
+				for _, a := range t.actions {
+					f.perform(a, args)
+				}
+			}
+
+			func (f *Fsm) perform(a action, args []interface{}) {
+				switch a {
+				case actionUnlock:
+					f.Actions.Unlock(args[0].(int))
+				case actionRefund:
+					f.Actions.Refund(args[0].(int))
+				}
+			}
+			`,
+		)
+	})
+
+	t.Run("Final states", func(t *testing.T) {
+		result := removeSpacing(implementTable("FSM: fsm Initial: a Final: b { a e b - \n b - - - }"))
+		assert.Contains(t, result, removeSpacing(`
+			var finalStates = map[state]bool{
+				stateB: true,
+			}
+		`))
+		assert.Contains(t, result, removeSpacing(`
+			if r == mainRegion && finalStates[t.nextState] {
+				f.complete()
+			}
+		`))
+	})
+
+	t.Run("Timers", func(t *testing.T) {
+		result := removeSpacing(implementTable("FSM: fsm Initial: a { a { e b - \n after 5s b - } \n b - - - }"))
+		assert.Contains(t, result, removeSpacing(`
+			func (f *Fsm) handle(e event, args ...interface{}) {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+		`))
+		assert.Contains(t, result, removeSpacing(`
+			armed.Timer = f.Clock.AfterFunc(spec.duration, func() {
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				if f.timers[tm] == armed {
+		`))
+		assert.Contains(t, result, removeSpacing(`
+			f.states[mainRegion] = stateA
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.startTimer(timerAAfter5s)
+			return f
+		`))
+	})
+}
+
+func assertImplementedTable(t *testing.T, input, expected string) {
+	t.Helper()
+	assert.Equal(t, removeSpacing(expected), removeSpacing(implementTable(input)))
+}
+
+func implementTable(input string) string {
+	implementer := NewTableImplementer("fsm")
+	optimizedFSM := optimizeFSM(input)
+
+	return implementer.Implement(table.NewGenerator().Generate(optimizedFSM))
+}
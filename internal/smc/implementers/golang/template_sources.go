@@ -0,0 +1,361 @@
+package golang
+
+var builtinTemplates = map[string]string{
+	"header.tmpl": `{{if .Package}}package {{.Package}}
+{{end}}{{range .Imports}}
+import "{{.}}"
+{{end}}`,
+
+	"state_interface.tmpl": `
+type State interface {
+{{- range .Events}}
+  {{title .}}({{stateMethodParameters $.FSMClassName (index $.EventParameters .)}}){{if contains $.RegionEvents .}} bool{{end}}
+{{- end}}
+{{- if .RegionEvents}}
+  stateName() string
+{{- end}}
+}
+`,
+
+	"actions_interface.tmpl": `
+type Actions interface {
+{{- range .Actions}}
+  {{title .}}({{parameterList (index $.ActionParameters .)}})
+{{- end}}
+{{- if .Guards}}
+  Guard(name string) bool
+{{- end}}
+  UnhandledTransition(state string, event string)
+}
+`,
+
+	"fsm_class.tmpl": `
+type {{className}} struct {
+  State State
+  Actions Actions
+{{- range .Regions}}
+  {{regionField .Name}} State
+{{- end}}
+{{- range .HistoryStates}}
+  {{historyField .}} string
+{{- end}}
+{{- if .Timers}}
+  Clock Clock
+  timers map[string]*armedTimer
+  mu sync.Mutex
+{{- end}}
+}
+{{template "constructor" .}}
+{{- if .HistoryStates}}{{template "remember" .StateHistories}}{{end}}
+{{- if .Timers}}{{template "timers" .}}{{end}}
+{{- range .Regions}}{{template "region" .}}{{end}}
+{{- if .FinalStates}}{{template "completion" .}}{{end}}
+{{- range .EventMethods}}{{render .}}{{end}}
+
+{{- define "constructor"}}
+{{- $setup := or .Timers .HistoryStates}}
+{{- if .Timers}}
+func New{{className}}(actions Actions) *{{className}} {
+  return New{{className}}WithClock(actions, systemClock{})
+}
+
+func New{{className}}WithClock(actions Actions, clock Clock) *{{className}} {
+{{- else}}
+func New{{className}}(actions Actions) *{{className}} {
+{{- end}}
+{{- if $setup}}
+  fsm := &{{className}}{
+{{- else}}
+  return &{{className}}{
+{{- end}}
+    Actions: actions,
+    State:   NewState{{title .InitialState}}(),
+{{- range .InitialRegions}}
+    {{regionField .}}: NewState{{title (initialState $.Regions .)}}(),
+{{- end}}
+{{- if .Timers}}
+    Clock:   clock,
+    timers:  map[string]*armedTimer{},
+{{- end}}
+  }
+{{- if .InitialTimers}}
+  fsm.mu.Lock()
+  defer fsm.mu.Unlock()
+{{- end}}
+{{- range .InitialTimers}}
+  fsm.start{{title .}}()
+{{- end}}
+{{- if .HistoryStates}}
+  fsm.remember()
+{{- end}}
+{{- if $setup}}
+  return fsm
+{{- end}}
+}
+{{end}}
+
+{{- define "remember"}}
+func (f *{{className}}) remember() {
+{{- range $region := historyRegions .}}
+  switch f.{{regionField $region}}.(type) {
+{{- range $}}{{if eq .Region $region}}
+  case State{{title .StateName}}:
+{{- $name := .StateName}}{{range .HistoryStates}}
+    f.{{historyField .}} = "{{$name}}"
+{{- end}}{{end}}{{end}}
+  }
+{{- end}}
+}
+{{end}}
+
+{{- define "timers"}}{{import "sync"}}{{import "time"}}
+type Clock interface {
+  AfterFunc(d time.Duration, f func()) Timer
+}
+
+type Timer interface {
+  Stop() bool
+}
+
+type systemClock struct{}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+  return time.AfterFunc(d, f)
+}
+
+type armedTimer struct {
+  Timer
+}
+
+func (f *{{className}}) startTimer(name string, d time.Duration, fire func()) {
+  t := &armedTimer{}
+  f.timers[name] = t
+  t.Timer = f.Clock.AfterFunc(d, func() {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    if f.timers[name] == t {
+      delete(f.timers, name)
+      fire()
+    }
+  })
+}
+
+func (f *{{className}}) stopTimer(name string) {
+  if t, ok := f.timers[name]; ok {
+    delete(f.timers, name)
+    t.Stop()
+  }
+}
+{{- range .Timers}}
+
+func (f *{{className}}) start{{title .Event}}() {
+  f.startTimer("{{.Event}}", {{goDuration .Duration}}, func() {
+    f.{{regionField .Region}}.{{title .Event}}(f)
+{{- if $.HistoryStates}}
+    f.remember()
+{{- end}}
+  })
+}
+{{- end}}
+{{end}}
+
+{{- define "region"}}
+func (f *{{className}}) enter{{title .Name}}() {
+  f.{{regionField .Name}} = NewState{{title .InitialState}}()
+{{- range .EntryActions}}
+  f.Actions.{{title .}}()
+{{- end}}
+{{- range .InitialTimers}}
+  f.start{{title .}}()
+{{- end}}
+}
+
+func (f *{{className}}) exit{{title .Name}}() {
+{{- range .Timers}}
+  f.stopTimer("{{.}}")
+{{- end}}
+{{- if .Exits}}
+  switch f.{{regionField .Name}}.(type) {
+{{- range .Exits}}
+  case State{{title .StateName}}:
+{{- range .Actions}}
+    f.Actions.{{title .}}()
+{{- end}}
+{{- end}}
+  }
+{{- end}}
+  f.{{regionField .Name}} = nil
+}
+{{end}}
+
+{{- define "completion"}}
+type Completer interface {
+  Completed()
+}
+
+func (f *{{className}}) IsFinished() bool {
+{{- if .Timers}}
+  f.mu.Lock()
+  defer f.mu.Unlock()
+{{- end}}
+  switch f.State.(type) {
+  case {{stateTypeList .FinalStates}}:
+    return true
+  }
+  return false
+}
+
+func (f *{{className}}) complete() {
+  if c, ok := f.Actions.(Completer); ok {
+    c.Completed()
+  }
+}
+{{end}}`,
+
+	"event_method.tmpl": `
+func (f *{{title .ClassName}}) {{title .EventName}}({{parameterList .Parameters}}) {
+{{- if .Synchronized}}
+  f.mu.Lock()
+  defer f.mu.Unlock()
+{{- end}}
+{{- if .Regions}}
+  handled := false
+{{- range .Regions}}
+  if f.{{regionField .}} != nil && f.{{regionField .}}.{{title $.EventName}}({{stateMethodArguments "f" $.Parameters}}) {
+    handled = true
+  }
+{{- end}}
+  if !f.State.{{title .EventName}}({{stateMethodArguments "f" .Parameters}}) && !handled {
+    f.Actions.UnhandledTransition(f.State.stateName(), "{{.EventName}}")
+  }
+{{- else}}
+  f.State.{{title .EventName}}({{stateMethodArguments "f" .Parameters}})
+{{- end}}
+{{- if .RecordsHistory}}
+  f.remember()
+{{- end}}
+}
+`,
+
+	"base_state.tmpl": `
+type BaseState struct {
+  StateName string
+}
+{{range .Events}}
+{{- if contains $.RegionEvents .}}
+func (b BaseState) {{title .}}({{stateMethodParameters $.FSMClassName (index $.EventParameters .)}}) bool {
+  return false
+}
+{{else}}
+func (b BaseState) {{title .}}({{stateMethodParameters $.FSMClassName (index $.EventParameters .)}}) {
+  fsm.Actions.UnhandledTransition(b.StateName, "{{.}}")
+}
+{{end}}
+{{- end}}
+{{- if .RegionEvents}}
+func (b BaseState) stateName() string {
+  return b.StateName
+}
+{{end}}`,
+
+	"state_class.tmpl": `
+type State{{title .StateName}} struct {
+  BaseState
+}
+
+func NewState{{title .StateName}}() State{{title .StateName}} {
+  return State{{title .StateName}}{BaseState{StateName: "{{.StateName}}"}}
+}
+{{range .StateEventMethods}}{{render .}}{{end}}`,
+
+	"state_event_method.tmpl": `
+func (s State{{title .StateName}}) {{title .EventName}}({{stateMethodParameters .FSMClassName .Parameters}}){{if .RegionEvent}} bool{{end}} {
+{{- template "target" (target .)}}
+{{- if .RegionEvent}}
+  return true
+{{- end}}
+}
+`,
+
+	"guarded_state_event_method.tmpl": `
+func (s State{{title .StateName}}) {{title .EventName}}({{stateMethodParameters .FSMClassName .Parameters}}){{if .RegionEvent}} bool{{end}} {
+{{- range .Alternatives}}
+{{- if .Guard}}
+  if fsm.Actions.Guard("{{.Guard}}") {
+{{- template "target" (alternativeTarget $ .)}}
+    return{{if $.RegionEvent}} true{{end}}
+  }
+{{- else}}
+{{- template "target" (alternativeTarget $ .)}}
+{{- if $.RegionEvent}}
+  return true
+{{- end}}
+{{- end}}
+{{- end}}
+{{- if not (hasDefault .Alternatives)}}
+  {{if .RegionEvent}}return {{end}}s.BaseState.{{title .EventName}}({{stateMethodArguments "fsm" .Parameters}})
+{{- end}}
+}
+`,
+
+	"transition.tmpl": `
+{{- define "target"}}
+{{- if .History}}
+  switch fsm.{{historyField .History}} {
+{{- $target := .}}{{range .HistoryTargets}}
+  case "{{.Memory}}":
+{{- template "transition" (historyTarget $target .)}}
+{{- end}}
+  default:
+{{- template "transition" .}}
+  }
+{{- else}}
+{{- template "transition" .}}
+{{- end}}
+{{- end}}
+
+{{- define "transition"}}
+{{- range .StoppedTimers}}
+  fsm.stopTimer("{{.}}")
+{{- end}}
+{{- range .ExitedRegions}}
+  fsm.exit{{title .}}()
+{{- end}}
+{{- if .NextState}}
+  fsm.{{regionField .Region}} = NewState{{title .NextState}}()
+{{- end}}
+{{- range .Actions}}
+  fsm.Actions.{{title .}}({{actionArguments . $.Parameters}})
+{{- end}}
+{{- range .EnteredRegions}}
+  fsm.enter{{title .}}()
+{{- end}}
+{{- range .StartedTimers}}
+  fsm.start{{title .}}()
+{{- end}}
+{{- if and (eq .Region "") (isFinal .NextState)}}
+  fsm.complete()
+{{- end}}
+{{- if .Branches}}{{template "branches" .}}{{end}}
+{{- end}}
+
+{{- define "branches"}}
+{{- $target := .}}
+{{- $first := index .Branches 0}}
+{{- if eq $first.Guard ""}}
+{{- template "transition" (branchTarget $target $first)}}
+{{- else}}
+{{- range $n, $branch := .Branches}}
+{{- if eq $n 0}}
+  if fsm.Actions.Guard("{{$branch.Guard}}") {
+{{- else if eq $branch.Guard ""}}
+  } else {
+{{- else}}
+  } else if fsm.Actions.Guard("{{$branch.Guard}}") {
+{{- end}}
+{{- template "transition" (branchTarget $target $branch)}}
+{{- end}}
+  }
+{{- end}}
+{{- end}}`,
+}
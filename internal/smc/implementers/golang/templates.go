@@ -0,0 +1,270 @@
+package golang
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/geisonbiazus/smc/internal/smc/generator/statepattern"
+)
+
+type TemplateImplementer struct {
+	pkg              string
+	templates        *template.Template
+	result           string
+	className        string
+	actionParameters map[string][]statepattern.Parameter
+	finalStates      map[string]bool
+	imports          []string
+	err              error
+}
+
+type Target struct {
+	Region         string
+	NextState      string
+	Actions        []string
+	ExitedRegions  []string
+	EnteredRegions []string
+	StoppedTimers  []string
+	StartedTimers  []string
+	Branches       []statepattern.TransitionAlternative
+	History        string
+	HistoryTargets []statepattern.HistoryTarget
+	Parameters     []statepattern.Parameter
+}
+
+func NewTemplateImplementer(pkg, dir string) (*TemplateImplementer, error) {
+	i := &TemplateImplementer{pkg: pkg}
+	templates := template.New("smc").Funcs(i.funcs())
+
+	for name, source := range builtinTemplates {
+		if _, err := templates.New(name).Parse(source); err != nil {
+			return nil, err
+		}
+	}
+
+	if dir != "" {
+		if err := loadTemplates(templates, dir); err != nil {
+			return nil, err
+		}
+	}
+
+	i.templates = templates
+	return i, nil
+}
+
+func loadTemplates(templates *template.Template, dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		source, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if _, err := templates.New(filepath.Base(path)).Parse(string(source)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *TemplateImplementer) Implement(node statepattern.Node) (string, error) {
+	i.result = ""
+	i.className = ""
+	i.actionParameters = nil
+	i.finalStates = nil
+	i.imports = nil
+	i.err = nil
+
+	node.Accept(i)
+	if i.err != nil {
+		return "", i.err
+	}
+
+	header := i.execute("header.tmpl", struct {
+		Package string
+		Imports []string
+	}{i.pkg, i.imports})
+	return header + i.result, i.err
+}
+
+func (i *TemplateImplementer) VisitStateInterfaceNode(node statepattern.StateInterfaceNode) {
+	i.result += i.execute("state_interface.tmpl", node)
+}
+
+func (i *TemplateImplementer) VisitActionsInterfaceNode(node statepattern.ActionsInterfaceNode) {
+	i.actionParameters = node.ActionParameters
+	i.result += i.execute("actions_interface.tmpl", node)
+}
+
+func (i *TemplateImplementer) VisitFSMClassNode(node statepattern.FSMClassNode) {
+	i.className = title(node.ClassName)
+	i.finalStates = map[string]bool{}
+	for _, state := range node.FinalStates {
+		i.finalStates[state] = true
+	}
+	i.result += i.execute("fsm_class.tmpl", node)
+}
+
+func (i *TemplateImplementer) VisitEventMethodNode(node statepattern.EventMethodNode) {
+	i.result += i.execute("event_method.tmpl", node)
+}
+
+func (i *TemplateImplementer) VisitBaseStateClassNode(node statepattern.BaseStateClassNode) {
+	i.result += i.execute("base_state.tmpl", node)
+}
+
+func (i *TemplateImplementer) VisitStateClassNode(node statepattern.StateClassNode) {
+	i.result += i.execute("state_class.tmpl", node)
+}
+
+func (i *TemplateImplementer) VisitStateEventMethodNode(node statepattern.StateEventMethodNode) {
+	i.result += i.execute("state_event_method.tmpl", node)
+}
+
+func (i *TemplateImplementer) VisitGuardedStateEventMethodNode(node statepattern.GuardedStateEventMethodNode) {
+	i.result += i.execute("guarded_state_event_method.tmpl", node)
+}
+
+func (i *TemplateImplementer) execute(name string, data interface{}) string {
+	if i.err != nil {
+		return ""
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := i.templates.ExecuteTemplate(buffer, name, data); err != nil {
+		i.err = err
+		return ""
+	}
+	return buffer.String()
+}
+
+func (i *TemplateImplementer) render(node statepattern.Node) string {
+	result := i.result
+	i.result = ""
+	node.Accept(i)
+	rendered := i.result
+	i.result = result
+	return rendered
+}
+
+func (i *TemplateImplementer) funcs() template.FuncMap {
+	return template.FuncMap{
+		"render":                i.render,
+		"className":             func() string { return i.className },
+		"import":                i.addImport,
+		"isFinal":               func(state string) bool { return i.finalStates[state] },
+		"actionArguments":       i.actionArguments,
+		"contains":              contains,
+		"title":                 title,
+		"parameterList":         parameterList,
+		"argumentList":          argumentList,
+		"stateMethodParameters": stateMethodParameters,
+		"stateMethodArguments":  stateMethodArguments,
+		"regionField":           regionField,
+		"historyField":          historyField,
+		"stateTypeList":         stateTypeList,
+		"historyRegions":        historyRegions,
+		"initialState":          initialState,
+		"goDuration":            goDuration,
+		"hasDefault":            hasDefault,
+		"target":                methodTarget,
+		"alternativeTarget":     alternativeTarget,
+		"historyTarget":         historyTarget,
+		"branchTarget":          branchTarget,
+	}
+}
+
+func (i *TemplateImplementer) addImport(path string) string {
+	for _, imp := range i.imports {
+		if imp == path {
+			return ""
+		}
+	}
+	i.imports = append(i.imports, path)
+	return ""
+}
+
+func (i *TemplateImplementer) actionArguments(action string, parameters []statepattern.Parameter) string {
+	if len(i.actionParameters[action]) == 0 {
+		return ""
+	}
+	return argumentList(parameters)
+}
+
+func hasDefault(alternatives []statepattern.TransitionAlternative) bool {
+	return len(alternatives) > 0 && alternatives[len(alternatives)-1].Guard == ""
+}
+
+func methodTarget(node statepattern.StateEventMethodNode) Target {
+	return Target{
+		Region:         node.Region,
+		NextState:      node.NextState,
+		Actions:        node.Actions,
+		ExitedRegions:  node.ExitedRegions,
+		EnteredRegions: node.EnteredRegions,
+		StoppedTimers:  node.StoppedTimers,
+		StartedTimers:  node.StartedTimers,
+		Branches:       node.Branches,
+		History:        node.History,
+		HistoryTargets: node.HistoryTargets,
+		Parameters:     node.Parameters,
+	}
+}
+
+func alternativeTarget(node statepattern.GuardedStateEventMethodNode, alternative statepattern.TransitionAlternative) Target {
+	return Target{
+		Region:         node.Region,
+		NextState:      alternative.NextState,
+		Actions:        alternative.Actions,
+		ExitedRegions:  alternative.ExitedRegions,
+		EnteredRegions: alternative.EnteredRegions,
+		StoppedTimers:  alternative.StoppedTimers,
+		StartedTimers:  alternative.StartedTimers,
+		Branches:       alternative.Branches,
+		History:        alternative.History,
+		HistoryTargets: alternative.HistoryTargets,
+		Parameters:     node.Parameters,
+	}
+}
+
+func historyTarget(t Target, history statepattern.HistoryTarget) Target {
+	return Target{
+		Region:         t.Region,
+		NextState:      history.NextState,
+		Actions:        history.Actions,
+		ExitedRegions:  history.ExitedRegions,
+		EnteredRegions: history.EnteredRegions,
+		StoppedTimers:  history.StoppedTimers,
+		StartedTimers:  history.StartedTimers,
+		Parameters:     t.Parameters,
+	}
+}
+
+func branchTarget(t Target, branch statepattern.TransitionAlternative) Target {
+	return Target{
+		Region:         t.Region,
+		NextState:      branch.NextState,
+		Actions:        branch.Actions,
+		ExitedRegions:  branch.ExitedRegions,
+		EnteredRegions: branch.EnteredRegions,
+		StoppedTimers:  branch.StoppedTimers,
+		StartedTimers:  branch.StartedTimers,
+		Branches:       branch.Branches,
+		Parameters:     t.Parameters,
+	}
+}
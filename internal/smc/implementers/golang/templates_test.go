@@ -0,0 +1,131 @@
+package golang
+
+import (
+	"bytes"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/geisonbiazus/smc/internal/smc/lexer"
+	"github.com/geisonbiazus/smc/internal/smc/parser"
+	"github.com/geisonbiazus/smc/internal/smc/semantic"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateImplementer(t *testing.T) {
+	t.Run("Built-in templates match the Go implementer", func(t *testing.T) {
+		inputs := []string{
+			"FSM: fsm Initial: state { state event state action }",
+			"FSM: fsm Initial: a { a { e [g] a x \n e [h] - - \n f [g] - y } }",
+			"FSM: fsm Initial: a Event: e(n int, m string) { a >y { e [g] a x \n e - z \n f a - } }",
+			"FSM: fsm Initial: a { a { b { e c - } \n c { e d - } } \n d f a(H) x }",
+			"FSM: fsm Initial: a { a { [r] { b >x { e c - } \n c <y { e b - } } \n f d - } \n d f a - }",
+			"FSM: fsm Initial: a Final: b Final: c { a { e b x \n f c - } \n b - - - \n c - - - }",
+			"FSM: fsm Initial: a { a { e b - \n after 5s b x } \n b { after 250ms a - } }",
+			"FSM: fsm Initial: a { a <x { e c y } \n c? { [g] b - \n [h] a - \n a z } \n b - - - }",
+			"FSM: fsm Initial: a { a { b { e c - } \n c { after 1s b - } } \n d f a(H) x }",
+			"FSM: fsm Initial: a Event: e(n int) { a { [r] { b { e [g] c x \n f [h] c - } \n c { e b - } } \n e [k] d - } \n d f a - }",
+		}
+		scenarios, _ := filepath.Glob(filepath.Join("..", "..", "testdata", "behavior", "*", "machine.sm"))
+		for _, scenario := range scenarios {
+			input, err := ioutil.ReadFile(scenario)
+			assert.Nil(t, err)
+			inputs = append(inputs, string(input))
+		}
+
+		for _, input := range inputs {
+			assert.Empty(t, analyzeFSM(input).Errors, input)
+
+			implementer, err := NewTemplateImplementer("fsm", "")
+			assert.Nil(t, err)
+
+			result, err := implementer.Implement(generateFSM(input))
+			assert.Nil(t, err)
+			assert.Equal(t, formatted(t, implementFSM(input)), formatted(t, result), input)
+		}
+	})
+
+	t.Run("Templates in a directory override the built-in ones", func(t *testing.T) {
+		dir := templateDir(t, map[string]string{
+			"state_class.tmpl": "\n// {{.StateName}} is a state.\ntype State{{title .StateName}} struct{}\n",
+		})
+		defer os.RemoveAll(dir)
+
+		implementer, err := NewTemplateImplementer("fsm", dir)
+		assert.Nil(t, err)
+
+		result, err := implementer.Implement(generateFSM("FSM: fsm Initial: a { a e a - }"))
+		assert.Nil(t, err)
+		assert.Contains(t, result, "// a is a state.\ntype StateA struct{}\n")
+		assert.Contains(t, result, "type BaseState struct {")
+	})
+
+	t.Run("Invalid templates", func(t *testing.T) {
+		dir := templateDir(t, map[string]string{"header.tmpl": "{{if .Package}}"})
+		defer os.RemoveAll(dir)
+
+		_, err := NewTemplateImplementer("fsm", dir)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Missing template directory", func(t *testing.T) {
+		dir := templateDir(t, map[string]string{"header.tmpl": "{{.Package}}"})
+		defer os.RemoveAll(dir)
+
+		_, err := NewTemplateImplementer("fsm", filepath.Join(dir, "missing"))
+		assert.True(t, os.IsNotExist(err))
+
+		_, err = NewTemplateImplementer("fsm", filepath.Join(dir, "header.tmpl"))
+		assert.EqualError(t, err, filepath.Join(dir, "header.tmpl")+" is not a directory")
+	})
+
+	t.Run("Template execution errors", func(t *testing.T) {
+		dir := templateDir(t, map[string]string{"base_state.tmpl": "{{.Unknown}}"})
+		defer os.RemoveAll(dir)
+
+		implementer, err := NewTemplateImplementer("fsm", dir)
+		assert.Nil(t, err)
+
+		result, err := implementer.Implement(generateFSM("FSM: fsm Initial: a { a e a - }"))
+		assert.NotNil(t, err)
+		assert.Equal(t, "", result)
+	})
+}
+
+func analyzeFSM(input string) *semantic.FSM {
+	builder := parser.NewSyntaxBuilder()
+	psr := parser.NewParser(builder)
+	lxr := lexer.NewLexer(psr)
+	lxr.Lex(bytes.NewBufferString(input))
+
+	analyzer := semantic.NewAnalyzer()
+	return analyzer.Analyze(builder.FSM())
+}
+
+func formatted(t *testing.T, code string) string {
+	t.Helper()
+	source, err := format.Source([]byte(code))
+	if err != nil {
+		t.Fatalf("%v\n%s", err, code)
+	}
+	return string(source)
+}
+
+func templateDir(t *testing.T, templates map[string]string) string {
+	dir, err := ioutil.TempDir("", "smc-templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, source := range templates {
+		writeTemplate(t, filepath.Join(dir, name), source)
+	}
+	return dir
+}
+
+func writeTemplate(t *testing.T, path, source string) {
+	if err := ioutil.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,99 @@
+package golang
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/geisonbiazus/smc/internal/smc/generator/statepattern"
+)
+
+func (i *Implementer) writeTimers(className string, timers []statepattern.TimerNode, recordsHistory bool) {
+	i.imports = append(i.imports, "sync", "time")
+	i.result += clockTypes()
+	i.result += namedTimerMethods(className)
+
+	for _, timer := range timers {
+		i.writeTimer(className, timer, recordsHistory)
+	}
+}
+
+func (i *Implementer) writeTimer(className string, timer statepattern.TimerNode, recordsHistory bool) {
+	i.result += "\n"
+	i.result += "func (f *" + className + ") start" + title(timer.Event) + "() {\n"
+	i.result += "  f.startTimer(\"" + timer.Event + "\", " + goDuration(timer.Duration) + ", func() {\n"
+	i.result += "    f." + regionField(timer.Region) + "." + title(timer.Event) + "(f)\n"
+	if recordsHistory {
+		i.result += "    f.remember()\n"
+	}
+	i.result += "  })\n"
+	i.result += "}\n"
+}
+
+func clockTypes() string {
+	result := "\n"
+	result += "type Clock interface {\n"
+	result += "  AfterFunc(d time.Duration, f func()) Timer\n"
+	result += "}\n"
+	result += "\n"
+	result += "type Timer interface {\n"
+	result += "  Stop() bool\n"
+	result += "}\n"
+	result += "\n"
+	result += "type systemClock struct{}\n"
+	result += "\n"
+	result += "func (systemClock) AfterFunc(d time.Duration, f func()) Timer {\n"
+	result += "  return time.AfterFunc(d, f)\n"
+	result += "}\n"
+	result += "\n"
+	result += "type armedTimer struct {\n"
+	result += "  Timer\n"
+	result += "}\n"
+	return result
+}
+
+func namedTimerMethods(className string) string {
+	result := "\n"
+	result += "func (f *" + className + ") startTimer(name string, d time.Duration, fire func()) {\n"
+	result += "  t := &armedTimer{}\n"
+	result += "  f.timers[name] = t\n"
+	result += "  t.Timer = f.Clock.AfterFunc(d, func() {\n"
+	result += lock("    ", "f")
+	result += "    if f.timers[name] == t {\n"
+	result += "      delete(f.timers, name)\n"
+	result += "      fire()\n"
+	result += "    }\n"
+	result += "  })\n"
+	result += "}\n"
+	result += "\n"
+	result += "func (f *" + className + ") stopTimer(name string) {\n"
+	result += "  if t, ok := f.timers[name]; ok {\n"
+	result += "    delete(f.timers, name)\n"
+	result += "    t.Stop()\n"
+	result += "  }\n"
+	result += "}\n"
+	return result
+}
+
+func lock(indent, fsm string) string {
+	return indent + fsm + ".mu.Lock()\n" + indent + "defer " + fsm + ".mu.Unlock()\n"
+}
+
+var durationUnits = []struct {
+	unit time.Duration
+	name string
+}{
+	{time.Hour, "time.Hour"},
+	{time.Minute, "time.Minute"},
+	{time.Second, "time.Second"},
+	{time.Millisecond, "time.Millisecond"},
+	{time.Microsecond, "time.Microsecond"},
+}
+
+func goDuration(d time.Duration) string {
+	for _, u := range durationUnits {
+		if d%u.unit == 0 {
+			return strconv.FormatInt(int64(d/u.unit), 10) + "*" + u.name
+		}
+	}
+	return "time.Duration(" + strconv.FormatInt(int64(d), 10) + ")"
+}
@@ -0,0 +1,159 @@
+package java
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/geisonbiazus/smc/internal/smc/optimizer"
+)
+
+type Error struct {
+	Type    ErrorType
+	Element string
+}
+
+func (e Error) String() string {
+	return fmt.Sprintf("Type: %s - Element: %s", e.Type, e.Element)
+}
+
+func (e Error) Location() (line, pos int) {
+	return 0, 0
+}
+
+func (e Error) Message() string {
+	return fmt.Sprintf("%s: %s", e.Type, e.Element)
+}
+
+type ErrorType string
+
+const (
+	ErrorReservedWord    ErrorType = "JAVA_RESERVED_WORD"
+	ErrorUnsupportedType ErrorType = "JAVA_UNSUPPORTED_TYPE"
+	ErrorGeneratedName   ErrorType = "JAVA_GENERATED_NAME"
+)
+
+// Check reports the events, actions and parameters of the FSM that cannot be
+// written as Java: names that become reserved words, parameter names taken by
+// the generated code and parameter types without a Java equivalent.
+func Check(fsm *optimizer.FSM) []Error {
+	errors := []Error{}
+	if nestedTypes[title(fsm.Name)] {
+		errors = append(errors, Error{ErrorGeneratedName, fsm.Name})
+	}
+	for _, event := range fsm.Events {
+		if reservedWords[method(event)] {
+			errors = append(errors, Error{ErrorReservedWord, event})
+		}
+	}
+	for _, action := range fsm.Actions {
+		if reservedWords[method(action)] {
+			errors = append(errors, Error{ErrorReservedWord, action})
+		}
+	}
+	// Actions take the parameters of their events, so checking the events
+	// covers them.
+	return append(errors, checkParameters(fsm.EventParameters, generatedNames(fsm))...)
+}
+
+// generatedNames are the fields and locals the event methods refer to and the
+// fsm parameter of the state methods.
+func generatedNames(fsm *optimizer.FSM) map[string]bool {
+	names := map[string]bool{"fsm": true, "actions": true, "state": true, "handled": true}
+	for _, region := range fsm.Regions {
+		names[regionField(region.Name)] = true
+	}
+	return names
+}
+
+func checkParameters(parameters map[string][]optimizer.Parameter, generated map[string]bool) []Error {
+	names := []string{}
+	for name := range parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	errors := []Error{}
+	for _, name := range names {
+		for _, p := range parameters[name] {
+			if reservedWords[p.Name] {
+				errors = append(errors, Error{ErrorReservedWord, name + ":" + p.Name})
+			}
+			if generated[p.Name] {
+				errors = append(errors, Error{ErrorGeneratedName, name + ":" + p.Name})
+			}
+			if _, ok := javaType(p.Type); !ok {
+				errors = append(errors, Error{ErrorUnsupportedType, name + ":" + p.Name + " " + p.Type})
+			}
+		}
+	}
+	return errors
+}
+
+// javaType maps a Go parameter type to Java. Go builtins become their Java
+// counterparts, slices and arrays become Java arrays and any other name is
+// taken as a Java type. Pointers, maps and builtins without a Java
+// counterpart, such as the unsigned integers, have no mapping.
+func javaType(goType string) (string, bool) {
+	switch {
+	case strings.HasPrefix(goType, "map["), strings.HasPrefix(goType, "*"):
+		return goType, false
+	case strings.HasPrefix(goType, "["):
+		element, ok := javaType(goType[strings.Index(goType, "]")+1:])
+		return element + "[]", ok
+	}
+	if builtin, ok := builtinTypes[goType]; ok {
+		return builtin, builtin != ""
+	}
+	return goType, true
+}
+
+var builtinTypes = map[string]string{
+	"bool":       "boolean",
+	"string":     "String",
+	"int":        "int",
+	"int8":       "byte",
+	"int16":      "short",
+	"int32":      "int",
+	"int64":      "long",
+	"byte":       "byte",
+	"rune":       "int",
+	"float32":    "float",
+	"float64":    "double",
+	"any":        "Object",
+	"uint":       "",
+	"uint8":      "",
+	"uint16":     "",
+	"uint32":     "",
+	"uint64":     "",
+	"uintptr":    "",
+	"complex64":  "",
+	"complex128": "",
+	"error":      "",
+}
+
+// nestedTypes are the member types of the FSM class, which cannot share its
+// name.
+var nestedTypes = map[string]bool{
+	"Actions":     true,
+	"State":       true,
+	"Completer":   true,
+	"Clock":       true,
+	"Timer":       true,
+	"SystemClock": true,
+	"ArmedTimer":  true,
+}
+
+var reservedWords = map[string]bool{
+	"abstract": true, "assert": true, "boolean": true, "break": true, "byte": true,
+	"case": true, "catch": true, "char": true, "class": true, "const": true,
+	"continue": true, "default": true, "do": true, "double": true, "else": true,
+	"enum": true, "extends": true, "false": true, "final": true, "finally": true,
+	"float": true, "for": true, "goto": true, "if": true, "implements": true,
+	"import": true, "instanceof": true, "int": true, "interface": true, "long": true,
+	"native": true, "new": true, "null": true, "package": true, "private": true,
+	"protected": true, "public": true, "return": true, "short": true, "static": true,
+	"strictfp": true, "super": true, "switch": true, "synchronized": true, "this": true,
+	"throw": true, "throws": true, "transient": true, "true": true, "try": true,
+	"void": true, "volatile": true, "while": true, "_": true,
+}
@@ -0,0 +1,75 @@
+package java
+
+import (
+	"testing"
+
+	"github.com/geisonbiazus/smc/internal/smc/optimizer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	t.Run("Valid FSM", func(t *testing.T) {
+		assert.Empty(t, checkFSM("FSM: fsm Initial: a Event: e(n int, m String) Action: x(n int, m String) { a e a x }"))
+	})
+
+	t.Run("Reserved words", func(t *testing.T) {
+		assert.Equal(t, []Error{
+			{ErrorReservedWord, "New"},
+			{ErrorReservedWord, "class"},
+			{ErrorReservedWord, "e:int"},
+		}, checkFSM("FSM: fsm Initial: a Event: e(int int) { a { New a - \n e a class } }"))
+	})
+
+	t.Run("Unsupported types", func(t *testing.T) {
+		assert.Equal(t, []Error{
+			{ErrorUnsupportedType, "e:n uint"},
+			{ErrorUnsupportedType, "e:p *int"},
+			{ErrorUnsupportedType, "f:m map[string]int"},
+		}, checkFSM("FSM: fsm Initial: a Event: e(n uint, p *int) Event: f(m map[string]int) { a { e a x \n f a - } }"))
+	})
+
+	t.Run("Generated names", func(t *testing.T) {
+		assert.Equal(t, []Error{
+			{ErrorGeneratedName, "e:actions"},
+			{ErrorGeneratedName, "e:state"},
+			{ErrorGeneratedName, "e:rState"},
+		}, checkFSM("FSM: fsm Initial: a Event: e(actions int, state int, rState int) { a { [r] { b e b - } \n e a - } }"))
+		assert.Equal(t, []Error{
+			{ErrorGeneratedName, "e:fsm"},
+		}, Check(&optimizer.FSM{Name: "fsm", EventParameters: map[string][]optimizer.Parameter{
+			"e": {{Name: "fsm", Type: "int"}},
+		}}))
+		assert.Equal(t, []Error{
+			{ErrorGeneratedName, "clock"},
+		}, checkFSM("FSM: clock Initial: a { a e a - }"))
+	})
+}
+
+func TestJavaType(t *testing.T) {
+	for goType, expected := range map[string]string{
+		"string":            "String",
+		"bool":              "boolean",
+		"int":               "int",
+		"int64":             "long",
+		"rune":              "int",
+		"float64":           "double",
+		"[]byte":            "byte[]",
+		"[4][]int16":        "short[][]",
+		"String":            "String",
+		"Money":             "Money",
+		"java.time.Instant": "java.time.Instant",
+	} {
+		actual, ok := javaType(goType)
+		assert.True(t, ok, goType)
+		assert.Equal(t, expected, actual, goType)
+	}
+
+	for _, goType := range []string{"uint64", "error", "complex128", "*int", "map[string]int", "[]uint"} {
+		_, ok := javaType(goType)
+		assert.False(t, ok, goType)
+	}
+}
+
+func checkFSM(input string) []Error {
+	return Check(optimizeFSM(input))
+}
@@ -0,0 +1,577 @@
+package java
+
+import (
+	"strings"
+
+	"github.com/geisonbiazus/smc/internal/smc/generator/statepattern"
+)
+
+type Implementer struct {
+	pkg              string
+	result           string
+	className        string
+	actionParameters map[string][]statepattern.Parameter
+	finalStates      map[string]bool
+	synchronized     bool
+}
+
+func NewImplementer(pkg string) *Implementer {
+	return &Implementer{
+		pkg: pkg,
+	}
+}
+
+func (i *Implementer) Implement(node statepattern.Node) string {
+	i.result = ""
+	i.className = ""
+	i.actionParameters = nil
+	i.finalStates = nil
+	i.synchronized = false
+
+	node.Accept(i)
+	return i.header() +
+		"public class " + i.className + " {\n" +
+		strings.TrimPrefix(i.result, "\n") +
+		"}\n"
+}
+
+func (i *Implementer) header() string {
+	if i.pkg == "" {
+		return ""
+	}
+	return "package " + i.pkg + ";\n\n"
+}
+
+// The state interface is folded into the abstract State class written for
+// the BaseStateClassNode.
+func (i *Implementer) VisitStateInterfaceNode(node statepattern.StateInterfaceNode) {
+}
+
+func (i *Implementer) VisitActionsInterfaceNode(node statepattern.ActionsInterfaceNode) {
+	i.result += "\n"
+	i.result += "    public interface Actions {\n"
+
+	i.actionParameters = node.ActionParameters
+	for _, action := range node.Actions {
+		i.result += "        void " + method(action) + "(" + parameterList(node.ActionParameters[action]) + ");\n"
+	}
+
+	if len(node.Guards) > 0 {
+		i.result += "        boolean guard(String name);\n"
+	}
+
+	i.result += "        void unhandledTransition(String state, String event);\n"
+	i.result += "    }\n"
+}
+
+func (i *Implementer) VisitFSMClassNode(node statepattern.FSMClassNode) {
+	i.className = title(node.ClassName)
+	i.synchronized = len(node.Timers) > 0
+
+	i.writeFields(node)
+	i.writeConstructors(node)
+	i.writeStateName()
+
+	if len(node.HistoryStates) > 0 {
+		i.writeRemember(node.StateHistories)
+	}
+
+	if len(node.Timers) > 0 {
+		i.writeTimers(node.Timers, len(node.HistoryStates) > 0)
+	}
+
+	for _, region := range node.Regions {
+		i.writeRegion(region)
+	}
+
+	if len(node.FinalStates) > 0 {
+		i.writeCompletion(node.FinalStates)
+	}
+
+	for _, methodNode := range node.EventMethods {
+		methodNode.Accept(i)
+	}
+}
+
+func (i *Implementer) writeFields(node statepattern.FSMClassNode) {
+	i.result += "\n"
+	i.result += "    private final Actions actions;\n"
+	if len(node.Timers) > 0 {
+		i.result += "    private final Clock clock;\n"
+		i.result += "    private final java.util.Map<String, ArmedTimer> timers = new java.util.HashMap<>();\n"
+	}
+	i.result += "    private State state;\n"
+	for _, region := range node.Regions {
+		i.result += "    private State " + regionField(region.Name) + ";\n"
+	}
+	for _, state := range node.HistoryStates {
+		i.result += "    private String " + historyField(state) + " = \"\";\n"
+	}
+}
+
+func (i *Implementer) writeConstructors(node statepattern.FSMClassNode) {
+	hasTimers := len(node.Timers) > 0
+
+	i.result += "\n"
+	if hasTimers {
+		i.result += "    public " + i.className + "(Actions actions) {\n"
+		i.result += "        this(actions, new SystemClock());\n"
+		i.result += "    }\n"
+		i.result += "\n"
+		i.result += "    public " + i.className + "(Actions actions, Clock clock) {\n"
+	} else {
+		i.result += "    public " + i.className + "(Actions actions) {\n"
+	}
+
+	i.result += "        this.actions = actions;\n"
+	if hasTimers {
+		i.result += "        this.clock = clock;\n"
+	}
+	i.result += "        this.state = new " + stateClass(node.InitialState) + "();\n"
+	for _, region := range node.InitialRegions {
+		i.result += "        this." + regionField(region) + " = new " + stateClass(initialState(node.Regions, region)) + "();\n"
+	}
+	indent := "        "
+	if len(node.InitialTimers) > 0 {
+		i.result += indent + "synchronized (this) {\n"
+		indent += "    "
+	}
+	for _, timer := range node.InitialTimers {
+		i.result += indent + "start" + title(timer) + "();\n"
+	}
+	if len(node.HistoryStates) > 0 {
+		i.result += indent + "remember();\n"
+	}
+	if len(node.InitialTimers) > 0 {
+		i.result += "        }\n"
+	}
+	i.result += "    }\n"
+}
+
+func (i *Implementer) writeStateName() {
+	i.result += "\n"
+	i.result += "    public " + i.modifiers() + "String getStateName() {\n"
+	i.result += "        return state.stateName;\n"
+	i.result += "    }\n"
+}
+
+// Timers fire on the clock's thread, so the public methods of a machine
+// with timers share the instance lock with the timer callbacks.
+func (i *Implementer) modifiers() string {
+	if i.synchronized {
+		return "synchronized "
+	}
+	return ""
+}
+
+func (i *Implementer) writeRemember(histories []statepattern.StateHistory) {
+	i.result += "\n"
+	i.result += "    private void remember() {\n"
+	for _, region := range historyRegions(histories) {
+		keyword := "if"
+		for _, history := range histories {
+			if history.Region != region {
+				continue
+			}
+			i.result += "        " + keyword + " (" + regionField(region) + " instanceof " + stateClass(history.StateName) + ") {\n"
+			for _, state := range history.HistoryStates {
+				i.result += "            " + historyField(state) + " = \"" + history.StateName + "\";\n"
+			}
+			keyword = "} else if"
+		}
+		i.result += "        }\n"
+	}
+	i.result += "    }\n"
+}
+
+func historyRegions(histories []statepattern.StateHistory) []string {
+	regions := []string{}
+	index := map[string]bool{}
+	for _, history := range histories {
+		if !index[history.Region] {
+			index[history.Region] = true
+			regions = append(regions, history.Region)
+		}
+	}
+	return regions
+}
+
+func (i *Implementer) writeRegion(region statepattern.RegionNode) {
+	field := regionField(region.Name)
+
+	i.result += "\n"
+	i.result += "    private void enter" + title(region.Name) + "() {\n"
+	i.result += "        " + field + " = new " + stateClass(region.InitialState) + "();\n"
+	for _, action := range region.EntryActions {
+		i.result += "        actions." + method(action) + "();\n"
+	}
+	for _, timer := range region.InitialTimers {
+		i.result += "        start" + title(timer) + "();\n"
+	}
+	i.result += "    }\n"
+
+	i.result += "\n"
+	i.result += "    private void exit" + title(region.Name) + "() {\n"
+	for _, timer := range region.Timers {
+		i.result += "        stopTimer(\"" + timer + "\");\n"
+	}
+	keyword := "if"
+	for _, exit := range region.Exits {
+		if len(exit.Actions) == 0 {
+			continue
+		}
+		i.result += "        " + keyword + " (" + field + " instanceof " + stateClass(exit.StateName) + ") {\n"
+		for _, action := range exit.Actions {
+			i.result += "            actions." + method(action) + "();\n"
+		}
+		keyword = "} else if"
+	}
+	if keyword != "if" {
+		i.result += "        }\n"
+	}
+	i.result += "        " + field + " = null;\n"
+	i.result += "    }\n"
+}
+
+func initialState(regions []statepattern.RegionNode, name string) string {
+	for _, region := range regions {
+		if region.Name == name {
+			return region.InitialState
+		}
+	}
+	return ""
+}
+
+func (i *Implementer) writeCompletion(finalStates []string) {
+	i.finalStates = map[string]bool{}
+	checks := []string{}
+	for _, state := range finalStates {
+		i.finalStates[state] = true
+		checks = append(checks, "state instanceof "+stateClass(state))
+	}
+
+	i.result += "\n"
+	i.result += "    public interface Completer {\n"
+	i.result += "        void completed();\n"
+	i.result += "    }\n"
+	i.result += "\n"
+	i.result += "    public " + i.modifiers() + "boolean isFinished() {\n"
+	i.result += "        return " + strings.Join(checks, " || ") + ";\n"
+	i.result += "    }\n"
+	i.result += "\n"
+	i.result += "    private void complete() {\n"
+	i.result += "        if (actions instanceof Completer) {\n"
+	i.result += "            ((Completer) actions).completed();\n"
+	i.result += "        }\n"
+	i.result += "    }\n"
+}
+
+func (i *Implementer) VisitEventMethodNode(node statepattern.EventMethodNode) {
+	arguments := stateMethodArguments("this", node.Parameters)
+
+	i.result += "\n"
+	i.result += "    public " + i.modifiers() + "void " + method(node.EventName) + "(" + parameterList(node.Parameters) + ") {\n"
+	if len(node.Regions) > 0 {
+		i.result += "        boolean handled = false;\n"
+		for _, region := range node.Regions {
+			i.result += "        if (" + regionField(region) + " != null && " + regionField(region) + "." + method(node.EventName) + "(" + arguments + ")) {\n"
+			i.result += "            handled = true;\n"
+			i.result += "        }\n"
+		}
+		i.result += "        if (!state." + method(node.EventName) + "(" + arguments + ") && !handled) {\n"
+		i.result += "            actions.unhandledTransition(state.stateName, \"" + node.EventName + "\");\n"
+		i.result += "        }\n"
+	} else {
+		i.result += "        state." + method(node.EventName) + "(" + arguments + ");\n"
+	}
+	if node.RecordsHistory {
+		i.result += "        remember();\n"
+	}
+	i.result += "    }\n"
+}
+
+func (i *Implementer) VisitBaseStateClassNode(node statepattern.BaseStateClassNode) {
+	i.result += "\n"
+	i.result += "    private abstract static class State {\n"
+	i.result += "        private final String stateName;\n"
+	i.result += "\n"
+	i.result += "        State(String stateName) {\n"
+	i.result += "            this.stateName = stateName;\n"
+	i.result += "        }\n"
+
+	for _, event := range node.Events {
+		i.result += "\n"
+		if contains(node.RegionEvents, event) {
+			i.result += "        boolean " + method(event) + "(" + stateMethodParameters(node.FSMClassName, node.EventParameters[event]) + ") {\n"
+			i.result += "            return false;\n"
+		} else {
+			i.result += "        void " + method(event) + "(" + stateMethodParameters(node.FSMClassName, node.EventParameters[event]) + ") {\n"
+			i.result += "            fsm.actions.unhandledTransition(stateName, \"" + event + "\");\n"
+		}
+		i.result += "        }\n"
+	}
+	i.result += "    }\n"
+}
+
+func (i *Implementer) VisitStateClassNode(node statepattern.StateClassNode) {
+	i.result += "\n"
+	i.result += "    private static final class " + stateClass(node.StateName) + " extends State {\n"
+	i.result += "        " + stateClass(node.StateName) + "() {\n"
+	i.result += "            super(\"" + node.StateName + "\");\n"
+	i.result += "        }\n"
+
+	for _, method := range node.StateEventMethods {
+		method.Accept(i)
+	}
+	i.result += "    }\n"
+}
+
+func (i *Implementer) VisitStateEventMethodNode(node statepattern.StateEventMethodNode) {
+	i.writeMethodHeader(node.FSMClassName, node.EventName, node.Parameters, node.RegionEvent)
+	i.writeTarget("            ", target{
+		region:         node.Region,
+		nextState:      node.NextState,
+		actions:        node.Actions,
+		exitedRegions:  node.ExitedRegions,
+		enteredRegions: node.EnteredRegions,
+		stoppedTimers:  node.StoppedTimers,
+		startedTimers:  node.StartedTimers,
+		branches:       node.Branches,
+	}, node.History, node.HistoryTargets, node.Parameters)
+	if node.RegionEvent {
+		i.result += "            return true;\n"
+	}
+	i.result += "        }\n"
+}
+
+func (i *Implementer) VisitGuardedStateEventMethodNode(node statepattern.GuardedStateEventMethodNode) {
+	i.writeMethodHeader(node.FSMClassName, node.EventName, node.Parameters, node.RegionEvent)
+
+	for _, alternative := range node.Alternatives {
+		t := target{
+			region:         node.Region,
+			nextState:      alternative.NextState,
+			actions:        alternative.Actions,
+			exitedRegions:  alternative.ExitedRegions,
+			enteredRegions: alternative.EnteredRegions,
+			stoppedTimers:  alternative.StoppedTimers,
+			startedTimers:  alternative.StartedTimers,
+			branches:       alternative.Branches,
+		}
+
+		if alternative.Guard == "" {
+			i.writeTarget("            ", t, alternative.History, alternative.HistoryTargets, node.Parameters)
+			if node.RegionEvent {
+				i.result += "            return true;\n"
+			}
+			i.result += "        }\n"
+			return
+		}
+
+		i.result += "            if (fsm.actions.guard(\"" + alternative.Guard + "\")) {\n"
+		i.writeTarget("                ", t, alternative.History, alternative.HistoryTargets, node.Parameters)
+		if node.RegionEvent {
+			i.result += "                return true;\n"
+		} else {
+			i.result += "                return;\n"
+		}
+		i.result += "            }\n"
+	}
+
+	if node.RegionEvent {
+		i.result += "            return super." + method(node.EventName) + "(" + stateMethodArguments("fsm", node.Parameters) + ");\n"
+	} else {
+		i.result += "            super." + method(node.EventName) + "(" + stateMethodArguments("fsm", node.Parameters) + ");\n"
+	}
+	i.result += "        }\n"
+}
+
+func (i *Implementer) writeMethodHeader(fsmClassName, event string, parameters []statepattern.Parameter, regionEvent bool) {
+	returnType := "void"
+	if regionEvent {
+		returnType = "boolean"
+	}
+
+	i.result += "\n"
+	i.result += "        @Override\n"
+	i.result += "        " + returnType + " " + method(event) + "(" + stateMethodParameters(fsmClassName, parameters) + ") {\n"
+}
+
+func contains(list []string, item string) bool {
+	for _, i := range list {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+type target struct {
+	region         string
+	nextState      string
+	actions        []string
+	exitedRegions  []string
+	enteredRegions []string
+	stoppedTimers  []string
+	startedTimers  []string
+	branches       []statepattern.TransitionAlternative
+}
+
+func (i *Implementer) writeTarget(
+	indent string, t target,
+	history string, targets []statepattern.HistoryTarget, parameters []statepattern.Parameter,
+) {
+	if history == "" {
+		i.writeTransition(indent, t, parameters)
+		return
+	}
+
+	i.result += indent + "switch (fsm." + historyField(history) + ") {\n"
+	for _, h := range targets {
+		i.result += indent + "    case \"" + h.Memory + "\":\n"
+		i.writeTransition(indent+"        ", target{
+			region:         t.region,
+			nextState:      h.NextState,
+			actions:        h.Actions,
+			exitedRegions:  h.ExitedRegions,
+			enteredRegions: h.EnteredRegions,
+			stoppedTimers:  h.StoppedTimers,
+			startedTimers:  h.StartedTimers,
+		}, parameters)
+		i.result += indent + "        break;\n"
+	}
+	i.result += indent + "    default:\n"
+	i.writeTransition(indent+"        ", t, parameters)
+	i.result += indent + "}\n"
+}
+
+func (i *Implementer) writeTransition(indent string, t target, parameters []statepattern.Parameter) {
+	for _, timer := range t.stoppedTimers {
+		i.result += indent + "fsm.stopTimer(\"" + timer + "\");\n"
+	}
+
+	for _, region := range t.exitedRegions {
+		i.result += indent + "fsm.exit" + title(region) + "();\n"
+	}
+
+	if t.nextState != "" {
+		i.result += indent + "fsm." + regionField(t.region) + " = new " + stateClass(t.nextState) + "();\n"
+	}
+
+	for _, action := range t.actions {
+		i.result += indent + "fsm.actions." + method(action) + "(" + i.actionArguments(action, parameters) + ");\n"
+	}
+
+	for _, region := range t.enteredRegions {
+		i.result += indent + "fsm.enter" + title(region) + "();\n"
+	}
+
+	for _, timer := range t.startedTimers {
+		i.result += indent + "fsm.start" + title(timer) + "();\n"
+	}
+
+	if t.region == "" && i.finalStates[t.nextState] {
+		i.result += indent + "fsm.complete();\n"
+	}
+
+	if len(t.branches) > 0 {
+		i.writeBranches(indent, t.region, t.branches, parameters)
+	}
+}
+
+func (i *Implementer) writeBranches(
+	indent, region string, branches []statepattern.TransitionAlternative, parameters []statepattern.Parameter,
+) {
+	for n, branch := range branches {
+		t := target{
+			region:         region,
+			nextState:      branch.NextState,
+			actions:        branch.Actions,
+			exitedRegions:  branch.ExitedRegions,
+			enteredRegions: branch.EnteredRegions,
+			stoppedTimers:  branch.StoppedTimers,
+			startedTimers:  branch.StartedTimers,
+			branches:       branch.Branches,
+		}
+
+		switch {
+		case n == 0 && branch.Guard == "":
+			i.writeTransition(indent, t, parameters)
+			return
+		case n == 0:
+			i.result += indent + "if (fsm.actions.guard(\"" + branch.Guard + "\")) {\n"
+		case branch.Guard == "":
+			i.result += indent + "} else {\n"
+		default:
+			i.result += indent + "} else if (fsm.actions.guard(\"" + branch.Guard + "\")) {\n"
+		}
+		i.writeTransition(indent+"    ", t, parameters)
+
+		if branch.Guard == "" {
+			break
+		}
+	}
+	i.result += indent + "}\n"
+}
+
+func (i *Implementer) actionArguments(action string, parameters []statepattern.Parameter) string {
+	if len(i.actionParameters[action]) == 0 {
+		return ""
+	}
+	return argumentList(parameters)
+}
+
+func stateMethodParameters(fsmClassName string, parameters []statepattern.Parameter) string {
+	result := title(fsmClassName) + " fsm"
+	if len(parameters) > 0 {
+		result += ", " + parameterList(parameters)
+	}
+	return result
+}
+
+func stateMethodArguments(fsm string, parameters []statepattern.Parameter) string {
+	if len(parameters) > 0 {
+		return fsm + ", " + argumentList(parameters)
+	}
+	return fsm
+}
+
+func parameterList(parameters []statepattern.Parameter) string {
+	list := []string{}
+	for _, p := range parameters {
+		javaType, _ := javaType(p.Type)
+		list = append(list, javaType+" "+p.Name)
+	}
+	return strings.Join(list, ", ")
+}
+
+func argumentList(parameters []statepattern.Parameter) string {
+	list := []string{}
+	for _, p := range parameters {
+		list = append(list, p.Name)
+	}
+	return strings.Join(list, ", ")
+}
+
+func stateClass(state string) string {
+	return "State" + title(state)
+}
+
+func regionField(region string) string {
+	return method(title(region) + "State")
+}
+
+func historyField(state string) string {
+	return method(title(state) + "History")
+}
+
+func method(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+func title(s string) string {
+	return strings.Title(s)
+}
@@ -0,0 +1,230 @@
+package java
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/geisonbiazus/smc/internal/smc/generator/statepattern"
+	"github.com/geisonbiazus/smc/internal/smc/lexer"
+	"github.com/geisonbiazus/smc/internal/smc/optimizer"
+	"github.com/geisonbiazus/smc/internal/smc/parser"
+	"github.com/geisonbiazus/smc/internal/smc/semantic"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImplementer(t *testing.T) {
+	t.Run("Simple FSM", func(t *testing.T) {
+		assert.Equal(t, `package fsm;
+
+public class Fsm {
+    public interface Actions {
+        void action();
+        void unhandledTransition(String state, String event);
+    }
+
+    private final Actions actions;
+    private State state;
+
+    public Fsm(Actions actions) {
+        this.actions = actions;
+        this.state = new StateState();
+    }
+
+    public String getStateName() {
+        return state.stateName;
+    }
+
+    public void event() {
+        state.event(this);
+    }
+
+    private abstract static class State {
+        private final String stateName;
+
+        State(String stateName) {
+            this.stateName = stateName;
+        }
+
+        void event(Fsm fsm) {
+            fsm.actions.unhandledTransition(stateName, "event");
+        }
+    }
+
+    private static final class StateState extends State {
+        StateState() {
+            super("state");
+        }
+
+        @Override
+        void event(Fsm fsm) {
+            fsm.state = new StateState();
+            fsm.actions.action();
+        }
+    }
+}
+`, implementFSM("FSM: fsm Initial: state { state event state action }"))
+	})
+
+	t.Run("Guards and event parameters", func(t *testing.T) {
+		result := implementFSM("FSM: fsm Initial: a Event: e(n int, m String) Action: x(n int, m String) { a { e [g] a x \n e - z \n f [g] - - } }")
+
+		assert.Contains(t, result, "        void x(int n, String m);\n        void z(int n, String m);\n        boolean guard(String name);\n")
+		assert.Contains(t, result, "    public void e(int n, String m) {\n        state.e(this, n, m);\n    }\n")
+		assert.Contains(t, result, `
+        @Override
+        void e(Fsm fsm, int n, String m) {
+            if (fsm.actions.guard("g")) {
+                fsm.state = new StateA();
+                fsm.actions.x(n, m);
+                return;
+            }
+            fsm.actions.z(n, m);
+        }
+`)
+		assert.Contains(t, result, `
+        @Override
+        void f(Fsm fsm) {
+            if (fsm.actions.guard("g")) {
+                return;
+            }
+            super.f(fsm);
+        }
+`)
+	})
+
+	t.Run("Go parameter types", func(t *testing.T) {
+		result := implementFSM("FSM: fsm Initial: a Event: e(label string, ok bool, n int64, xs []float64, at Instant) { a e a - }")
+
+		assert.Contains(t, result, "    public void e(String label, boolean ok, long n, double[] xs, Instant at) {\n")
+		assert.Contains(t, result, "        void e(Fsm fsm, String label, boolean ok, long n, double[] xs, Instant at) {\n")
+	})
+
+	t.Run("History and final states", func(t *testing.T) {
+		result := implementFSM("FSM: fsm Initial: a Final: d { a { b { e c - } \n c { e d - } } \n d f a(H) x }")
+
+		assert.Contains(t, result, "    private String aHistory = \"\";\n")
+		assert.Contains(t, result, `
+    private void remember() {
+        if (state instanceof StateB) {
+            aHistory = "b";
+        } else if (state instanceof StateC) {
+            aHistory = "c";
+        }
+    }
+`)
+		assert.Contains(t, result, "        return state instanceof StateD;\n")
+		assert.Contains(t, result, "            fsm.state = new StateD();\n            fsm.complete();\n")
+		assert.Contains(t, result, `
+            switch (fsm.aHistory) {
+                case "b":
+                    fsm.state = new StateB();
+                    fsm.actions.x();
+                    break;
+                case "c":
+                    fsm.state = new StateC();
+                    fsm.actions.x();
+                    break;
+                default:
+                    fsm.state = new StateB();
+                    fsm.actions.x();
+            }
+`)
+	})
+
+	t.Run("Regions and timers", func(t *testing.T) {
+		result := implementFSM("FSM: fsm Initial: a { a { [r] { b >x { e c - } \n c <y { after 1500us b - } } \n f d - } \n d f a - }")
+
+		assert.Contains(t, result, "    public Fsm(Actions actions) {\n        this(actions, new SystemClock());\n    }\n")
+		assert.Contains(t, result, "        this.rState = new StateB();\n")
+		assert.Contains(t, result, `
+    public synchronized void e() {
+        boolean handled = false;
+        if (rState != null && rState.e(this)) {
+            handled = true;
+        }
+        if (!state.e(this) && !handled) {
+            actions.unhandledTransition(state.stateName, "e");
+        }
+    }
+`)
+		assert.Contains(t, result, "        boolean e(Fsm fsm) {\n            return false;\n        }\n")
+		assert.Contains(t, result, "        @Override\n        boolean e(Fsm fsm) {\n            fsm.rState = new StateC();\n            fsm.startCAfter1500us();\n            return true;\n        }\n")
+		assert.Contains(t, result, `
+    private void startCAfter1500us() {
+        startTimer("cAfter1500us", 2L, () -> {
+            rState.cAfter1500us(this);
+        });
+    }
+`)
+		assert.Contains(t, result, `
+    private void exitR() {
+        stopTimer("cAfter1500us");
+        if (rState instanceof StateC) {
+            actions.y();
+        }
+        rState = null;
+    }
+`)
+	})
+
+	t.Run("Synchronized timers", func(t *testing.T) {
+		result := implementFSM("FSM: fsm Initial: a Final: b { a { e b - \n after 5s b - } \n b - - - }")
+
+		assert.Contains(t, result, "        this.state = new StateA();\n        synchronized (this) {\n            startAAfter5s();\n        }\n")
+		assert.Contains(t, result, "    public synchronized String getStateName() {\n")
+		assert.Contains(t, result, "    public synchronized boolean isFinished() {\n")
+		assert.Contains(t, result, "    public synchronized void e() {\n")
+		assert.Contains(t, result, `
+        armed.timer = clock.schedule(delayMillis, () -> {
+            synchronized (this) {
+                if (timers.get(name) == armed) {
+                    timers.remove(name);
+                    fire.run();
+                }
+            }
+        });
+`)
+	})
+
+	t.Run("Choices", func(t *testing.T) {
+		result := implementFSM("FSM: fsm Initial: a { a <x { e c y } \n c? { [g] b - \n [h] a - \n a z } \n b - - - }")
+
+		assert.Contains(t, result, `
+            fsm.actions.y();
+            if (fsm.actions.guard("g")) {
+                fsm.state = new StateB();
+                fsm.actions.x();
+            } else if (fsm.actions.guard("h")) {
+                fsm.state = new StateA();
+            } else {
+                fsm.state = new StateA();
+                fsm.actions.z();
+            }
+`)
+	})
+}
+
+func implementFSM(input string) string {
+	implementer := NewImplementer("fsm")
+	return implementer.Implement(generateFSM(input))
+}
+
+func generateFSM(input string) statepattern.Node {
+	gen := statepattern.NewNodeGenerator()
+	return gen.Generate(optimizeFSM(input))
+}
+
+func optimizeFSM(input string) *optimizer.FSM {
+	builder := parser.NewSyntaxBuilder()
+	psr := parser.NewParser(builder)
+	lxr := lexer.NewLexer(psr)
+	lxr.Lex(bytes.NewBufferString(input))
+
+	parsedFSM := builder.FSM()
+
+	analyzer := semantic.NewAnalyzer()
+	semanticFSM := analyzer.Analyze(parsedFSM)
+
+	opt := optimizer.New()
+	return opt.Optimize(semanticFSM)
+}
@@ -0,0 +1,93 @@
+package java
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/geisonbiazus/smc/internal/smc/generator/statepattern"
+)
+
+func (i *Implementer) writeTimers(timers []statepattern.TimerNode, recordsHistory bool) {
+	i.result += clockTypes()
+	i.result += timerMethods()
+
+	for _, timer := range timers {
+		i.writeTimer(timer, recordsHistory)
+	}
+}
+
+func (i *Implementer) writeTimer(timer statepattern.TimerNode, recordsHistory bool) {
+	i.result += "\n"
+	i.result += "    private void start" + title(timer.Event) + "() {\n"
+	i.result += "        startTimer(\"" + timer.Event + "\", " + javaMillis(timer.Duration) + ", () -> {\n"
+	i.result += "            " + regionField(timer.Region) + "." + method(timer.Event) + "(this);\n"
+	if recordsHistory {
+		i.result += "            remember();\n"
+	}
+	i.result += "        });\n"
+	i.result += "    }\n"
+}
+
+func clockTypes() string {
+	result := "\n"
+	result += "    public interface Clock {\n"
+	result += "        Timer schedule(long delayMillis, Runnable task);\n"
+	result += "    }\n"
+	result += "\n"
+	result += "    public interface Timer {\n"
+	result += "        void cancel();\n"
+	result += "    }\n"
+	result += "\n"
+	result += "    private static final class SystemClock implements Clock {\n"
+	result += "        private static final java.util.Timer TIMER = new java.util.Timer(true);\n"
+	result += "\n"
+	result += "        @Override\n"
+	result += "        public Timer schedule(long delayMillis, Runnable task) {\n"
+	result += "            java.util.TimerTask timerTask = new java.util.TimerTask() {\n"
+	result += "                @Override\n"
+	result += "                public void run() {\n"
+	result += "                    task.run();\n"
+	result += "                }\n"
+	result += "            };\n"
+	result += "            TIMER.schedule(timerTask, delayMillis);\n"
+	result += "            return timerTask::cancel;\n"
+	result += "        }\n"
+	result += "    }\n"
+	result += "\n"
+	result += "    private static final class ArmedTimer {\n"
+	result += "        private Timer timer;\n"
+	result += "    }\n"
+	return result
+}
+
+func timerMethods() string {
+	result := "\n"
+	result += "    private void startTimer(String name, long delayMillis, Runnable fire) {\n"
+	result += "        ArmedTimer armed = new ArmedTimer();\n"
+	result += "        timers.put(name, armed);\n"
+	result += "        armed.timer = clock.schedule(delayMillis, () -> {\n"
+	result += "            synchronized (this) {\n"
+	result += "                if (timers.get(name) == armed) {\n"
+	result += "                    timers.remove(name);\n"
+	result += "                    fire.run();\n"
+	result += "                }\n"
+	result += "            }\n"
+	result += "        });\n"
+	result += "    }\n"
+	result += "\n"
+	result += "    private void stopTimer(String name) {\n"
+	result += "        ArmedTimer armed = timers.remove(name);\n"
+	result += "        if (armed != null) {\n"
+	result += "            armed.timer.cancel();\n"
+	result += "        }\n"
+	result += "    }\n"
+	return result
+}
+
+func javaMillis(d time.Duration) string {
+	millis := d / time.Millisecond
+	if d%time.Millisecond != 0 {
+		millis++
+	}
+	return strconv.FormatInt(int64(millis), 10) + "L"
+}
@@ -4,26 +4,37 @@ import (
 	"bufio"
 	"io"
 	"regexp"
+	"strings"
 )
 
 type TokenCollector interface {
 	OpenBrace(line, pos int)
 	ClosedBrace(line, pos int)
 	Colon(line, pos int)
+	Comma(line, pos int)
 	OpenParen(line, pos int)
 	ClosedParen(line, pos int)
 	OpenAngle(line, pos int)
 	ClosedAngle(line, pos int)
+	OpenBracket(line, pos int)
+	ClosedBracket(line, pos int)
 	Dash(line, pos int)
+	Star(line, pos int)
+	Bang(line, pos int)
+	Question(line, pos int)
+	Dot(line, pos int)
 	Name(name string, line, pos int)
 	Error(line, pos int)
 	End(line, pos int)
 }
 
 type Lexer struct {
-	collector TokenCollector
-	pos       int
-	line      int
+	collector      TokenCollector
+	pos            int
+	line           int
+	inBlockComment bool
+	commentLine    int
+	commentPos     int
 }
 
 func NewLexer(collector TokenCollector) *Lexer {
@@ -32,11 +43,15 @@ func NewLexer(collector TokenCollector) *Lexer {
 
 func (l *Lexer) Lex(input io.Reader) {
 	l.line = 0
+	l.inBlockComment = false
 	scanner := bufio.NewScanner(input)
 	for scanner.Scan() {
 		l.line++
 		l.lexLine(scanner.Text())
 	}
+	if l.inBlockComment {
+		l.collector.Error(l.commentLine, l.commentPos)
+	}
 	l.collector.End(l.line+1, 1)
 }
 
@@ -50,7 +65,10 @@ func (l *Lexer) lexLine(input string) {
 }
 
 func (l *Lexer) findToken(input string) bool {
-	return l.ignorePossibleWhitespace(input) ||
+	return l.ignoreBlockCommentContent(input) ||
+		l.ignorePossibleWhitespace(input) ||
+		l.ignoreLineComment(input) ||
+		l.ignoreBlockCommentStart(input) ||
 		l.findSingleCharToken(input) ||
 		l.findName(input)
 }
@@ -64,6 +82,8 @@ func (l *Lexer) findSingleCharToken(input string) bool {
 		l.collector.ClosedBrace(l.line, l.pos)
 	case ":":
 		l.collector.Colon(l.line, l.pos)
+	case ",":
+		l.collector.Comma(l.line, l.pos)
 	case "(":
 		l.collector.OpenParen(l.line, l.pos)
 	case ")":
@@ -72,8 +92,20 @@ func (l *Lexer) findSingleCharToken(input string) bool {
 		l.collector.OpenAngle(l.line, l.pos)
 	case ">":
 		l.collector.ClosedAngle(l.line, l.pos)
+	case "[":
+		l.collector.OpenBracket(l.line, l.pos)
+	case "]":
+		l.collector.ClosedBracket(l.line, l.pos)
 	case "-":
 		l.collector.Dash(l.line, l.pos)
+	case "*":
+		l.collector.Star(l.line, l.pos)
+	case "!":
+		l.collector.Bang(l.line, l.pos)
+	case "?":
+		l.collector.Question(l.line, l.pos)
+	case ".":
+		l.collector.Dot(l.line, l.pos)
 	default:
 		return false
 	}
@@ -91,6 +123,41 @@ func (l *Lexer) ignorePossibleWhitespace(input string) bool {
 	return false
 }
 
+func (l *Lexer) ignoreLineComment(input string) bool {
+	if strings.HasPrefix(input[l.pos-1:], "//") {
+		l.pos = len(input) + 1
+		return true
+	}
+	return false
+}
+
+func (l *Lexer) ignoreBlockCommentStart(input string) bool {
+	if strings.HasPrefix(input[l.pos-1:], "/*") {
+		l.inBlockComment = true
+		l.commentLine = l.line
+		l.commentPos = l.pos
+		l.pos += 2
+		return true
+	}
+	return false
+}
+
+func (l *Lexer) ignoreBlockCommentContent(input string) bool {
+	if !l.inBlockComment {
+		return false
+	}
+
+	end := strings.Index(input[l.pos-1:], "*/")
+	if end < 0 {
+		l.pos = len(input) + 1
+		return true
+	}
+
+	l.inBlockComment = false
+	l.pos += end + 2
+	return true
+}
+
 var nameRegex = regexp.MustCompile("^\\w+")
 
 func (l *Lexer) findName(input string) bool {
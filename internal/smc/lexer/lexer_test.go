@@ -13,15 +13,20 @@ func TestLexer(t *testing.T) {
 		assertLexResult(t, "{", "OB:1/1.")
 		assertLexResult(t, "}", "CB:1/1.")
 		assertLexResult(t, ":", "C:1/1.")
+		assertLexResult(t, ",", "CO:1/1.")
+		assertLexResult(t, "*", "S:1/1.")
+		assertLexResult(t, "!", "B:1/1.")
+		assertLexResult(t, "?", "Q:1/1.")
 		assertLexResult(t, "(", "OP:1/1.")
 		assertLexResult(t, ")", "CP:1/1.")
 		assertLexResult(t, "<", "OA:1/1.")
 		assertLexResult(t, ">", "CA:1/1.")
+		assertLexResult(t, "[", "OBR:1/1.")
+		assertLexResult(t, "]", "CBR:1/1.")
 		assertLexResult(t, "-", "D:1/1.")
 		assertLexResult(t, "-", "D:1/1.")
-		assertLexResult(t, ".", "E:1/1.") // Error
+		assertLexResult(t, ".", "DOT:1/1.")
 		assertLexResult(t, "&", "E:1/1.") // Error
-		assertLexResult(t, "*", "E:1/1.") // Error
 		assertLexResult(t, "name", "#name#:1/1.")
 		assertLexResult(t, "Name", "#Name#:1/1.")
 		assertLexResult(t, "Complex_Name", "#Complex_Name#:1/1.")
@@ -30,10 +35,29 @@ func TestLexer(t *testing.T) {
 		assertLexResult(t, "{name}", "OB:1/1,#name#:1/2,CB:1/6.")
 		assertLexResult(t, "{name}asd:fgh>", "OB:1/1,#name#:1/2,CB:1/6,#asd#:1/7,C:1/10,#fgh#:1/11,CA:1/14.")
 		assertLexResult(t, "{ name }", "OB:1/1,#name#:1/3,CB:1/8.")
+		assertLexResult(t, "a [b] c", "#a#:1/1,OBR:1/3,#b#:1/4,CBR:1/5,#c#:1/7.")
+		assertLexResult(t, "a(b c, d e)", "#a#:1/1,OP:1/2,#b#:1/3,#c#:1/5,CO:1/6,#d#:1/8,#e#:1/10,CP:1/11.")
+		assertLexResult(t, "a(b *c.d)", "#a#:1/1,OP:1/2,#b#:1/3,S:1/5,#c#:1/6,DOT:1/7,#d#:1/8,CP:1/9.")
 		assertLexResult(t, "{\n  name\n}", "OB:1/1,#name#:2/3,CB:3/1.")
 		assertLexResult(t, "FSM: fsm {\n name : >asd &      \n\n  }\n", "#FSM#:1/1,C:1/4,#fsm#:1/6,OB:1/10,#name#:2/2,C:2/7,CA:2/9,#asd#:2/10,E:2/14,CB:4/3.")
 		assertLexEndPosition(t, "\n\n\na:b", 5, 1)
 	})
+
+	t.Run("Ignores comments", func(t *testing.T) {
+		assertLexResult(t, "// comment", ".")
+		assertLexResult(t, "a // b { }", "#a#:1/1.")
+		assertLexResult(t, "a//b\nc", "#a#:1/1,#c#:2/1.")
+		assertLexResult(t, "/* comment */", ".")
+		assertLexResult(t, "a /* b */ c", "#a#:1/1,#c#:1/11.")
+		assertLexResult(t, "a/**/b", "#a#:1/1,#b#:1/6.")
+		assertLexResult(t, "a /* b\n c\n\n d */ e\nf", "#a#:1/1,#e#:4/7,#f#:5/1.")
+		assertLexResult(t, "a /* // b */ c", "#a#:1/1,#c#:1/14.")
+		assertLexResult(t, "a // /* b\nc", "#a#:1/1,#c#:2/1.")
+		assertLexResult(t, "/", "E:1/1.")
+		assertLexResult(t, "a */", "#a#:1/1,S:1/3,E:1/4.")
+		assertLexResult(t, "a\n /* b\n c", "#a#:1/1,E:2/2.")
+		assertLexEndPosition(t, "/* a\nb\n*/", 4, 1)
+	})
 }
 
 func assertLexResult(t *testing.T, input, expected string) {
@@ -82,6 +106,26 @@ func (c *TokenCollectorSpy) Colon(line, pos int) {
 	c.addToken("C", line, pos)
 }
 
+func (c *TokenCollectorSpy) Star(line, pos int) {
+	c.addToken("S", line, pos)
+}
+
+func (c *TokenCollectorSpy) Bang(line, pos int) {
+	c.addToken("B", line, pos)
+}
+
+func (c *TokenCollectorSpy) Question(line, pos int) {
+	c.addToken("Q", line, pos)
+}
+
+func (c *TokenCollectorSpy) Dot(line, pos int) {
+	c.addToken("DOT", line, pos)
+}
+
+func (c *TokenCollectorSpy) Comma(line, pos int) {
+	c.addToken("CO", line, pos)
+}
+
 func (c *TokenCollectorSpy) OpenParen(line, pos int) {
 	c.addToken("OP", line, pos)
 }
@@ -98,6 +142,14 @@ func (c *TokenCollectorSpy) ClosedAngle(line, pos int) {
 	c.addToken("CA", line, pos)
 }
 
+func (c *TokenCollectorSpy) OpenBracket(line, pos int) {
+	c.addToken("OBR", line, pos)
+}
+
+func (c *TokenCollectorSpy) ClosedBracket(line, pos int) {
+	c.addToken("CBR", line, pos)
+}
+
 func (c *TokenCollectorSpy) Dash(line, pos int) {
 	c.addToken("D", line, pos)
 }
@@ -1,20 +1,80 @@
 package optimizer
 
+import "time"
+
 type FSM struct {
-	Name         string
-	InitialState string
-	Events       []string
-	Actions      []string
-	States       []*State
+	Name             string
+	Package          string
+	InitialState     string
+	FinalStates      []string
+	Events           []string
+	Actions          []string
+	Guards           []string
+	EventParameters  map[string][]Parameter
+	ActionParameters map[string][]Parameter
+	HistoryStates    []string
+	Regions          []*Region
+	InitialRegions   []string
+	Timers           []*Timer
+	InitialTimers    []string
+	States           []*State
+}
+
+type Timer struct {
+	Event    string
+	Region   string
+	Duration time.Duration
+}
+
+type Region struct {
+	Name          string
+	InitialState  string
+	Events        []string
+	EntryActions  []string
+	Exits         []*RegionExit
+	Timers        []string
+	InitialTimers []string
+}
+
+type RegionExit struct {
+	State   string
+	Actions []string
+}
+
+type Parameter struct {
+	Name string
+	Type string
 }
 
 type State struct {
-	Name        string
-	Transitions []*Transition
+	Name          string
+	Region        string
+	HistoryStates []string
+	Transitions   []*Transition
 }
 
 type Transition struct {
-	Event     string
-	NextState string
-	Actions   []string
+	Event          string
+	Guard          string
+	NextState      string
+	Actions        []string
+	Inherited      bool
+	Delegated      bool
+	History        string
+	HistoryTargets []*HistoryTarget
+	ExitedRegions  []string
+	EnteredRegions []string
+	StoppedTimers  []string
+	StartedTimers  []string
+	Branches       []*Transition
+}
+
+type HistoryTarget struct {
+	Memory         string
+	NextState      string
+	Actions        []string
+	ExitedRegions  []string
+	EnteredRegions []string
+	StoppedTimers  []string
+	StartedTimers  []string
 }
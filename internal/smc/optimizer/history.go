@@ -0,0 +1,70 @@
+package optimizer
+
+import "github.com/geisonbiazus/smc/internal/smc/semantic"
+
+func (o *Optimizer) setHistoryStates() {
+	o.historyStates = map[*semantic.State]bool{}
+	for _, s := range o.semanticFSM.States {
+		for _, t := range s.Transitions {
+			if t.History != semantic.NoHistory {
+				o.historyStates[t.NextState] = true
+			}
+		}
+	}
+
+	for _, s := range o.semanticFSM.States {
+		if o.historyStates[s] {
+			o.optimizedFSM.HistoryStates = append(o.optimizedFSM.HistoryStates, s.Name)
+		}
+	}
+}
+
+func (o *Optimizer) historyStatesOf(s *semantic.State) []string {
+	var result []string
+	for parent := s.Parent; parent != nil; parent = parent.Parent {
+		if o.historyStates[parent] {
+			result = append(result, parent.Name)
+		}
+	}
+	return result
+}
+
+func (o *Optimizer) historyTargets(source *semantic.State, t semantic.Transition, left []*semantic.State) []*HistoryTarget {
+	targets := []*HistoryTarget{}
+	for _, memory := range leaves(t.NextState) {
+		resolved := memory
+		if t.History == semantic.ShallowHistory {
+			resolved = childContaining(t.NextState, memory)
+		}
+
+		targets = append(targets, &HistoryTarget{
+			Memory:         memory.Name,
+			NextState:      defaultLeaf(resolved).Name,
+			Actions:        o.transitionActions(source, t, resolved, left),
+			ExitedRegions:  exitedRegions(source, t),
+			EnteredRegions: enteredRegions(source, t, resolved),
+			StoppedTimers:  stoppedTimers(source, t),
+			StartedTimers:  startedTimers(source, t, resolved),
+		})
+	}
+	return targets
+}
+
+func childContaining(parent, descendant *semantic.State) *semantic.State {
+	for descendant.Parent != parent {
+		descendant = descendant.Parent
+	}
+	return descendant
+}
+
+func leaves(state *semantic.State) []*semantic.State {
+	if len(state.SubStates) == 0 {
+		return []*semantic.State{state}
+	}
+
+	result := []*semantic.State{}
+	for _, child := range state.SubStates {
+		result = append(result, leaves(child)...)
+	}
+	return result
+}
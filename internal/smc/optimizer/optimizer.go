@@ -5,8 +5,9 @@ import (
 )
 
 type Optimizer struct {
-	optimizedFSM *FSM
-	semanticFSM  *semantic.FSM
+	optimizedFSM  *FSM
+	semanticFSM   *semantic.FSM
+	historyStates map[*semantic.State]bool
 }
 
 func New() *Optimizer {
@@ -18,9 +19,12 @@ func (o *Optimizer) Optimize(fsm *semantic.FSM) *FSM {
 	o.semanticFSM = fsm
 
 	o.setEventsAndActions()
+	o.setParameters()
 	o.setHeaders()
+	o.setHistoryStates()
+	o.setRegions()
+	o.setTimers()
 	o.optimizeStates()
-	o.optimizeEntryActions()
 	o.eliminateDuplicatedActions()
 
 	return o.optimizedFSM
@@ -29,116 +33,324 @@ func (o *Optimizer) Optimize(fsm *semantic.FSM) *FSM {
 func (o *Optimizer) setEventsAndActions() {
 	o.optimizedFSM.Events = o.semanticFSM.Events
 	o.optimizedFSM.Actions = o.semanticFSM.Actions
+	o.optimizedFSM.Guards = o.semanticFSM.Guards
+}
+
+func (o *Optimizer) setParameters() {
+	o.optimizedFSM.EventParameters = optimizeParameters(o.semanticFSM.EventParameters)
+	o.optimizedFSM.ActionParameters = optimizeParameters(o.semanticFSM.ActionParameters)
+}
+
+func optimizeParameters(semanticParameters map[string][]semantic.Parameter) map[string][]Parameter {
+	if len(semanticParameters) == 0 {
+		return nil
+	}
+
+	result := map[string][]Parameter{}
+	for name, parameters := range semanticParameters {
+		result[name] = []Parameter{}
+		for _, p := range parameters {
+			result[name] = append(result[name], Parameter{Name: p.Name, Type: p.Type})
+		}
+	}
+	return result
 }
 
 func (o *Optimizer) setHeaders() {
 	o.optimizedFSM.Name = o.semanticFSM.Name
-	o.optimizedFSM.InitialState = o.semanticFSM.InitialState.Name
+	o.optimizedFSM.Package = o.semanticFSM.Package
+	o.optimizedFSM.InitialState = defaultLeaf(o.semanticFSM.InitialState).Name
+	for _, s := range o.semanticFSM.States {
+		if s.Final && regionName(s) == "" {
+			o.optimizedFSM.FinalStates = append(o.optimizedFSM.FinalStates, s.Name)
+		}
+	}
 }
 
 func (o *Optimizer) optimizeStates() {
 	for _, s := range o.semanticFSM.States {
-		if !s.Abstract {
+		if !s.Abstract && !s.Choice && len(s.SubStates) == 0 {
 			o.optmizeState(s)
 		}
 	}
 }
 
 func (o *Optimizer) optmizeState(s *semantic.State) {
-	state := &State{Name: s.Name}
-	o.optimizeTransitions(state, s, make(map[string]bool))
-	o.optimizeExitActions(state, s)
+	state := &State{Name: s.Name, Region: regionName(s), HistoryStates: o.historyStatesOf(s)}
+	handledEvents := make(map[string]bool)
+	o.optimizeTransitions(state, s, s, handledEvents, false)
+	o.addRegionTransitions(state, s, handledEvents)
+	o.optimizeWildcardTransitions(state, s, s, handledEvents, false)
 	o.optimizedFSM.States = append(o.optimizedFSM.States, state)
 }
 
 func (o *Optimizer) optimizeTransitions(
-	state *State, semanticState *semantic.State, definedEvents map[string]bool,
+	state *State, source, semanticState *semantic.State, handledEvents map[string]bool, inherited bool,
 ) {
 
+	handledHere := map[string]bool{}
 	for _, t := range semanticState.Transitions {
-		if !definedEvents[t.Event] {
-			o.addTransition(state, t)
-			definedEvents[t.Event] = true
+		if t.Event != semantic.WildcardEvent && !handledEvents[t.Event] {
+			o.addTransition(state, source, t, inherited)
+			handledHere[t.Event] = handledHere[t.Event] || t.Guard == ""
 		}
 	}
 
+	for event, handled := range handledHere {
+		handledEvents[event] = handledEvents[event] || handled
+	}
+
 	for _, superState := range semanticState.SuperStates {
-		o.optimizeTransitions(state, superState, definedEvents)
+		o.optimizeTransitions(state, source, superState, handledEvents, true)
+	}
+
+	if semanticState.Parent != nil {
+		o.optimizeTransitions(state, source, semanticState.Parent, handledEvents, true)
 	}
 }
 
-func (o *Optimizer) addTransition(state *State, t semantic.Transition) {
-	transition := &Transition{
-		Event:     t.Event,
-		NextState: o.resolveNextState(t),
-		Actions:   t.Actions,
+func (o *Optimizer) optimizeWildcardTransitions(
+	state *State, source, semanticState *semantic.State, handledEvents map[string]bool, inherited bool,
+) {
+
+	handledHere := map[string]bool{}
+	for _, t := range semanticState.Transitions {
+		if t.Event != semantic.WildcardEvent {
+			continue
+		}
+		for _, event := range o.semanticFSM.Events {
+			if !handledEvents[event] {
+				t.Event = event
+				o.addTransition(state, source, t, inherited)
+				handledHere[event] = handledHere[event] || t.Guard == ""
+			}
+		}
+	}
+
+	for event, handled := range handledHere {
+		handledEvents[event] = handledEvents[event] || handled
 	}
 
+	for _, superState := range semanticState.SuperStates {
+		o.optimizeWildcardTransitions(state, source, superState, handledEvents, true)
+	}
+
+	if semanticState.Parent != nil {
+		o.optimizeWildcardTransitions(state, source, semanticState.Parent, handledEvents, true)
+	}
+}
+
+func (o *Optimizer) addTransition(
+	state *State, source *semantic.State, t semantic.Transition, inherited bool,
+) {
+	transition := o.newTransition(source, t, nil)
+	transition.Inherited = inherited
 	state.Transitions = append(state.Transitions, transition)
 }
 
+// newTransition builds the transition t from source. left holds the states
+// already exited on the way to a choice, whose exit actions ran before it.
+func (o *Optimizer) newTransition(source *semantic.State, t semantic.Transition, left []*semantic.State) *Transition {
+	if t.NextState != nil && t.NextState.Choice {
+		return o.newChoiceTransition(source, t, left)
+	}
+
+	transition := &Transition{
+		Event:          t.Event,
+		Guard:          t.Guard,
+		NextState:      o.resolveNextState(t),
+		Actions:        o.transitionActions(source, t, t.NextState, left),
+		ExitedRegions:  exitedRegions(source, t),
+		EnteredRegions: enteredRegions(source, t, t.NextState),
+		StoppedTimers:  stoppedTimers(source, t),
+		StartedTimers:  startedTimers(source, t, t.NextState),
+	}
+
+	if t.History != semantic.NoHistory {
+		transition.History = t.NextState.Name
+		transition.HistoryTargets = o.historyTargets(source, t, left)
+	}
+	return transition
+}
+
+// In the UML order the states that every branch leaves are exited before the
+// transition actions run and the guards are evaluated.
+func (o *Optimizer) newChoiceTransition(source *semantic.State, t semantic.Transition, left []*semantic.State) *Transition {
+	actions := append([]string{}, t.Actions...)
+	if o.semanticFSM.ActionOrder == semantic.ActionOrderUML {
+		exited := exitedStates(source, nil)[:commonExits(source, t.NextState)]
+		actions = concat(o.exitActions(exited[len(left):]), t.Actions)
+		left = exited
+	}
+
+	return &Transition{
+		Event:    t.Event,
+		Guard:    t.Guard,
+		Actions:  actions,
+		Branches: o.branches(source, t.NextState, left),
+	}
+}
+
+// commonExits counts the states, innermost first, that every branch of the
+// choice exits.
+func commonExits(source, choice *semantic.State) int {
+	count := -1
+	for _, b := range choice.Transitions {
+		exits := 0
+		if b.NextState != nil && b.NextState.Choice {
+			exits = commonExits(source, b.NextState)
+		} else if b.NextState != nil {
+			exits = len(exitedStates(source, transitionAncestor(source, b)))
+		}
+		if count < 0 || exits < count {
+			count = exits
+		}
+	}
+	if count < 0 {
+		return 0
+	}
+	return count
+}
+
+func (o *Optimizer) branches(source, choice *semantic.State, left []*semantic.State) []*Transition {
+	branches := []*Transition{}
+	for _, b := range choice.Transitions {
+		branches = append(branches, o.newTransition(source, b, left))
+	}
+	return branches
+}
+
 func (o *Optimizer) resolveNextState(t semantic.Transition) string {
 	if t.NextState != nil {
-		return t.NextState.Name
+		return defaultLeaf(t.NextState).Name
 	}
 
 	return ""
 }
 
-func (o *Optimizer) optimizeExitActions(state *State, semanticState *semantic.State) {
-	actions := o.getExitActionsRecursively(semanticState)
-	if len(actions) > 0 {
-		for _, t := range state.Transitions {
-			if t.NextState != "" && t.NextState != state.Name {
-				t.Actions = append(t.Actions, actions...)
-			}
-		}
+func (o *Optimizer) transitionActions(
+	source *semantic.State, t semantic.Transition, resolved *semantic.State, left []*semantic.State,
+) []string {
+	if t.NextState == nil {
+		return append([]string{}, t.Actions...)
 	}
+
+	ancestor := transitionAncestor(source, t)
+	exited := exitedStates(source, ancestor)
+	exitActions := o.exitActions(exited[len(left):])
+	entryActions := []string{}
+	for _, s := range enteredStates(ancestor, resolved) {
+		entryActions = append(entryActions, o.getEntryActionsRecursively(s)...)
+	}
+
+	if o.semanticFSM.ActionOrder == semantic.ActionOrderUML {
+		return concat(exitActions, t.Actions, entryActions)
+	}
+	return concat(t.Actions, exitActions, entryActions)
 }
 
-func (o *Optimizer) getExitActionsRecursively(s *semantic.State) []string {
+func (o *Optimizer) exitActions(states []*semantic.State) []string {
 	actions := []string{}
-	actions = append(actions, s.ExitActions...)
-	for _, super := range s.SuperStates {
-		actions = append(actions, o.getExitActionsRecursively(super)...)
+	for _, s := range states {
+		actions = append(actions, o.getExitActionsRecursively(s)...)
 	}
 	return actions
 }
 
-func (o *Optimizer) optimizeEntryActions() {
-	for _, semanticState := range o.semanticFSM.States {
-		actions := o.getEntryActionsRecursively(semanticState)
+func concat(lists ...[]string) []string {
+	result := []string{}
+	for _, list := range lists {
+		result = append(result, list...)
+	}
+	return result
+}
+
+func transitionAncestor(source *semantic.State, t semantic.Transition) *semantic.State {
+	ancestor := commonAncestor(source, t.NextState)
+	if t.External && ancestor == t.NextState {
+		return ancestor.Parent
+	}
+	return ancestor
+}
 
-		if len(actions) > 0 {
-			o.optimizeEntryActionsOfState(semanticState.Name, actions)
+func commonAncestor(source, target *semantic.State) *semantic.State {
+	for ancestor := target; ancestor != nil; ancestor = ancestor.Parent {
+		if isAncestorOrSelf(ancestor, source) {
+			return ancestor
 		}
 	}
+	return nil
 }
 
-func (o *Optimizer) getEntryActionsRecursively(s *semantic.State) []string {
+func isAncestorOrSelf(ancestor, state *semantic.State) bool {
+	for s := state; s != nil; s = s.Parent {
+		if s == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+func exitedStates(source, ancestor *semantic.State) []*semantic.State {
+	states := []*semantic.State{}
+	for s := source; s != ancestor; s = s.Parent {
+		states = append(states, s)
+	}
+	return states
+}
+
+func enteredStates(ancestor, target *semantic.State) []*semantic.State {
+	states := []*semantic.State{}
+	for s := target; s != ancestor; s = s.Parent {
+		states = append([]*semantic.State{s}, states...)
+	}
+	for s := target; len(s.SubStates) > 0; s = s.SubStates[0] {
+		states = append(states, s.SubStates[0])
+	}
+	return states
+}
+
+func defaultLeaf(state *semantic.State) *semantic.State {
+	for len(state.SubStates) > 0 {
+		state = state.SubStates[0]
+	}
+	return state
+}
+
+func (o *Optimizer) getExitActionsRecursively(s *semantic.State) []string {
 	actions := []string{}
-	actions = append(actions, s.EntryActions...)
+	actions = append(actions, s.ExitActions...)
 	for _, super := range s.SuperStates {
-		actions = append(actions, o.getEntryActionsRecursively(super)...)
+		actions = append(actions, o.getExitActionsRecursively(super)...)
 	}
 	return actions
 }
 
-func (o *Optimizer) optimizeEntryActionsOfState(stateName string, actions []string) {
-	for _, s := range o.optimizedFSM.States {
-		for _, t := range s.Transitions {
-			if t.NextState == stateName && s.Name != stateName {
-				t.Actions = append(t.Actions, actions...)
-			}
-		}
+func (o *Optimizer) getEntryActionsRecursively(s *semantic.State) []string {
+	superActions := []string{}
+	for _, super := range s.SuperStates {
+		superActions = append(superActions, o.getEntryActionsRecursively(super)...)
+	}
+
+	if o.semanticFSM.ActionOrder == semantic.ActionOrderUML {
+		return concat(superActions, s.EntryActions)
 	}
+	return concat(s.EntryActions, superActions)
 }
 
 func (o *Optimizer) eliminateDuplicatedActions() {
 	for _, s := range o.optimizedFSM.States {
-		for _, t := range s.Transitions {
-			t.Actions = unique(t.Actions)
+		eliminateDuplicatedTransitionActions(s.Transitions)
+	}
+}
+
+func eliminateDuplicatedTransitionActions(transitions []*Transition) {
+	for _, t := range transitions {
+		t.Actions = unique(t.Actions)
+		for _, target := range t.HistoryTargets {
+			target.Actions = unique(target.Actions)
 		}
+		eliminateDuplicatedTransitionActions(t.Branches)
 	}
 }
 
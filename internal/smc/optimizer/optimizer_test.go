@@ -3,6 +3,7 @@ package optimizer
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	"github.com/geisonbiazus/smc/internal/smc/lexer"
 	"github.com/geisonbiazus/smc/internal/smc/parser"
@@ -51,7 +52,7 @@ func TestOptimizer(t *testing.T) {
 				InitialState: "initial",
 				States: []*State{
 					{Name: "e", Transitions: []*Transition{
-						{Event: "b", NextState: "c", Actions: []string{"d"}},
+						{Event: "b", NextState: "c", Actions: []string{"d"}, Inherited: true},
 					}},
 				},
 				Events:  []string{"b"},
@@ -77,7 +78,7 @@ func TestOptimizer(t *testing.T) {
 						{Event: "b", NextState: "c", Actions: []string{"d"}},
 					}},
 					{Name: "e", Transitions: []*Transition{
-						{Event: "b", NextState: "c", Actions: []string{"d"}},
+						{Event: "b", NextState: "c", Actions: []string{"d"}, Inherited: true},
 					}},
 				},
 				Events:  []string{"b"},
@@ -104,18 +105,18 @@ func TestOptimizer(t *testing.T) {
 				States: []*State{
 					{Name: "c", Transitions: []*Transition{
 						{Event: "Ec", NextState: "Nc", Actions: []string{"Ac"}},
-						{Event: "Eb", NextState: "Nb", Actions: []string{"Ab1", "Ab2"}},
-						{Event: "Ea", NextState: "", Actions: []string{"Aa"}},
+						{Event: "Eb", NextState: "Nb", Actions: []string{"Ab1", "Ab2"}, Inherited: true},
+						{Event: "Ea", NextState: "", Actions: []string{"Aa"}, Inherited: true},
 					}},
 					{Name: "d", Transitions: []*Transition{
 						{Event: "Ed", NextState: "Nd", Actions: []string{"Ad"}},
 					}},
 					{Name: "e", Transitions: []*Transition{
 						{Event: "Ee", NextState: "Ne", Actions: []string{"Ae"}},
-						{Event: "Ec", NextState: "Nc", Actions: []string{"Ac"}},
-						{Event: "Eb", NextState: "Nb", Actions: []string{"Ab1", "Ab2"}},
-						{Event: "Ea", NextState: "", Actions: []string{"Aa"}},
-						{Event: "Ed", NextState: "Nd", Actions: []string{"Ad"}},
+						{Event: "Ec", NextState: "Nc", Actions: []string{"Ac"}, Inherited: true},
+						{Event: "Eb", NextState: "Nb", Actions: []string{"Ab1", "Ab2"}, Inherited: true},
+						{Event: "Ea", NextState: "", Actions: []string{"Aa"}, Inherited: true},
+						{Event: "Ed", NextState: "Nd", Actions: []string{"Ad"}, Inherited: true},
 					}},
 				},
 				Events:  []string{"Ea", "Eb", "Ec", "Ed", "Ee"},
@@ -223,7 +224,7 @@ func TestOptimizer(t *testing.T) {
 				InitialState: "initial",
 				States: []*State{
 					{Name: "S2", Transitions: []*Transition{
-						{Event: "E1", NextState: "S3", Actions: []string{}},
+						{Event: "E1", NextState: "S3", Actions: []string{}, Inherited: true},
 					}},
 					{Name: "S3", Transitions: []*Transition{
 						{Event: "E3", NextState: "S2", Actions: []string{"EA1", "EA2"}},
@@ -314,7 +315,7 @@ func TestOptimizer(t *testing.T) {
 				InitialState: "initial",
 				States: []*State{
 					{Name: "S2", Transitions: []*Transition{
-						{Event: "E1", NextState: "S3", Actions: []string{"EA1", "EA2"}},
+						{Event: "E1", NextState: "S3", Actions: []string{"EA1", "EA2"}, Inherited: true},
 					}},
 					{Name: "S3", Transitions: []*Transition{
 						{Event: "E3", NextState: "S2", Actions: []string{}},
@@ -378,6 +379,41 @@ func TestOptimizer(t *testing.T) {
 		)
 	})
 
+	t.Run("With guarded alternatives", func(t *testing.T) {
+		assertOptimizedFSM(t, `
+			FSM: fsm
+			Initial: initial
+			{
+				(S1) E1 S3 A1
+				S2:S1 <EA1 {
+					E1 [G1] S3 A2
+					E1 [G2] - A3
+					E2 [G1] S3 -
+					E2 - -
+				}
+				S3 - - -
+			}
+			`,
+			&FSM{
+				Name:         "fsm",
+				InitialState: "initial",
+				States: []*State{
+					{Name: "S2", Transitions: []*Transition{
+						{Event: "E1", Guard: "G1", NextState: "S3", Actions: []string{"A2", "EA1"}},
+						{Event: "E1", Guard: "G2", NextState: "", Actions: []string{"A3"}},
+						{Event: "E2", Guard: "G1", NextState: "S3", Actions: []string{"EA1"}},
+						{Event: "E2", NextState: "", Actions: []string{}},
+						{Event: "E1", NextState: "S3", Actions: []string{"A1", "EA1"}, Inherited: true},
+					}},
+					{Name: "S3"},
+				},
+				Events:  []string{"E1", "E2"},
+				Actions: []string{"A1", "EA1", "A2", "A3"},
+				Guards:  []string{"G1", "G2"},
+			},
+		)
+	})
+
 	t.Run("Acceptance tests", func(t *testing.T) {
 		assertOptimizedFSM(t, `
 			Actions: Turnstile
@@ -492,20 +528,20 @@ func TestOptimizer(t *testing.T) {
 					{Name: "Locked", Transitions: []*Transition{
 						{Event: "Pass", NextState: "Alarming", Actions: []string{"alarmOn"}},
 						{Event: "Coin", NextState: "FirstCoin", Actions: []string{}},
-						{Event: "Reset", NextState: "Locked", Actions: []string{"alarmOff", "lock"}},
+						{Event: "Reset", NextState: "Locked", Actions: []string{"alarmOff", "lock"}, Inherited: true},
 					}},
 					{Name: "Alarming", Transitions: []*Transition{
-						{Event: "Reset", NextState: "Locked", Actions: []string{"alarmOff", "lock"}},
+						{Event: "Reset", NextState: "Locked", Actions: []string{"alarmOff", "lock"}, Inherited: true},
 					}},
 					{Name: "FirstCoin", Transitions: []*Transition{
 						{Event: "Pass", NextState: "Alarming", Actions: []string{}},
 						{Event: "Coin", NextState: "Unlocked", Actions: []string{"unlock"}},
-						{Event: "Reset", NextState: "Locked", Actions: []string{"alarmOff", "lock"}},
+						{Event: "Reset", NextState: "Locked", Actions: []string{"alarmOff", "lock"}, Inherited: true},
 					}},
 					{Name: "Unlocked", Transitions: []*Transition{
 						{Event: "Pass", NextState: "Locked", Actions: []string{"lock"}},
 						{Event: "Coin", NextState: "", Actions: []string{"thankyou"}},
-						{Event: "Reset", NextState: "Locked", Actions: []string{"alarmOff", "lock"}},
+						{Event: "Reset", NextState: "Locked", Actions: []string{"alarmOff", "lock"}, Inherited: true},
 					}},
 				},
 				Events:  []string{"Reset", "Pass", "Coin"},
@@ -546,20 +582,20 @@ func TestOptimizer(t *testing.T) {
 					{Name: "Locked", Transitions: []*Transition{
 						{Event: "Pass", NextState: "Alarming", Actions: []string{"alarmOn"}},
 						{Event: "Coin", NextState: "FirstCoin", Actions: []string{}},
-						{Event: "Reset", NextState: "Locked", Actions: []string{"lock"}},
+						{Event: "Reset", NextState: "Locked", Actions: []string{"lock"}, Inherited: true},
 					}},
 					{Name: "Alarming", Transitions: []*Transition{
-						{Event: "Reset", NextState: "Locked", Actions: []string{"lock", "alarmOff"}},
+						{Event: "Reset", NextState: "Locked", Actions: []string{"lock", "alarmOff"}, Inherited: true},
 					}},
 					{Name: "FirstCoin", Transitions: []*Transition{
 						{Event: "Pass", NextState: "Alarming", Actions: []string{"alarmOn"}},
 						{Event: "Coin", NextState: "Unlocked", Actions: []string{"unlock"}},
-						{Event: "Reset", NextState: "Locked", Actions: []string{"lock"}},
+						{Event: "Reset", NextState: "Locked", Actions: []string{"lock"}, Inherited: true},
 					}},
 					{Name: "Unlocked", Transitions: []*Transition{
 						{Event: "Pass", NextState: "Locked", Actions: []string{"lock"}},
 						{Event: "Coin", NextState: "", Actions: []string{"thankyou"}},
-						{Event: "Reset", NextState: "Locked", Actions: []string{"lock"}},
+						{Event: "Reset", NextState: "Locked", Actions: []string{"lock"}, Inherited: true},
 					}},
 				},
 				Events:  []string{"Reset", "Pass", "Coin"},
@@ -567,6 +603,577 @@ func TestOptimizer(t *testing.T) {
 			},
 		)
 	})
+
+	t.Run("With nested states", func(t *testing.T) {
+		assertOptimizedFSM(t, `
+			FSM: f
+			Initial: op
+			{
+				op >opIn <opOut {
+					idle >idleIn <idleOut {
+						start running s
+					}
+					running >runIn <runOut {
+						stop idle t
+					}
+					fail broken f
+					reset op r
+				}
+				broken >brIn {
+					fix op x
+				}
+			}
+			`,
+			&FSM{
+				Name:         "f",
+				InitialState: "idle",
+				States: []*State{
+					{Name: "idle", Transitions: []*Transition{
+						{Event: "start", NextState: "running", Actions: []string{"s", "idleOut", "runIn"}},
+						{Event: "fail", NextState: "broken", Actions: []string{"f", "idleOut", "opOut", "brIn"}, Inherited: true},
+						{Event: "reset", NextState: "idle", Actions: []string{"r", "idleOut", "idleIn"}, Inherited: true},
+					}},
+					{Name: "running", Transitions: []*Transition{
+						{Event: "stop", NextState: "idle", Actions: []string{"t", "runOut", "idleIn"}},
+						{Event: "fail", NextState: "broken", Actions: []string{"f", "runOut", "opOut", "brIn"}, Inherited: true},
+						{Event: "reset", NextState: "idle", Actions: []string{"r", "runOut", "idleIn"}, Inherited: true},
+					}},
+					{Name: "broken", Transitions: []*Transition{
+						{Event: "fix", NextState: "idle", Actions: []string{"x", "opIn", "idleIn"}},
+					}},
+				},
+				Events: []string{"fail", "reset", "start", "stop", "fix"},
+				Actions: []string{
+					"opIn", "opOut", "f", "r", "idleIn", "idleOut", "s",
+					"runIn", "runOut", "t", "brIn", "x",
+				},
+			},
+		)
+	})
+
+	t.Run("With history targets", func(t *testing.T) {
+		assertOptimizedFSM(t, `
+			FSM: f
+			Initial: op
+			{
+				op >opIn {
+					idle {
+						start running -
+					}
+					running >runIn {
+						slow {
+							faster fast -
+						}
+						fast >fastIn {
+							slower slow -
+						}
+						stop idle -
+					}
+					fail broken -
+				}
+				broken {
+					fix op(H) x
+					deep op(H*) y
+				}
+			}
+			`,
+			&FSM{
+				Name:          "f",
+				InitialState:  "idle",
+				HistoryStates: []string{"op"},
+				States: []*State{
+					{Name: "idle", HistoryStates: []string{"op"}, Transitions: []*Transition{
+						{Event: "start", NextState: "slow", Actions: []string{"runIn"}},
+						{Event: "fail", NextState: "broken", Actions: []string{}, Inherited: true},
+					}},
+					{Name: "slow", HistoryStates: []string{"op"}, Transitions: []*Transition{
+						{Event: "faster", NextState: "fast", Actions: []string{"fastIn"}},
+						{Event: "stop", NextState: "idle", Actions: []string{}, Inherited: true},
+						{Event: "fail", NextState: "broken", Actions: []string{}, Inherited: true},
+					}},
+					{Name: "fast", HistoryStates: []string{"op"}, Transitions: []*Transition{
+						{Event: "slower", NextState: "slow", Actions: []string{}},
+						{Event: "stop", NextState: "idle", Actions: []string{}, Inherited: true},
+						{Event: "fail", NextState: "broken", Actions: []string{}, Inherited: true},
+					}},
+					{Name: "broken", Transitions: []*Transition{
+						{
+							Event: "fix", NextState: "idle", Actions: []string{"x", "opIn"},
+							History: "op",
+							HistoryTargets: []*HistoryTarget{
+								{Memory: "idle", NextState: "idle", Actions: []string{"x", "opIn"}},
+								{Memory: "slow", NextState: "slow", Actions: []string{"x", "opIn", "runIn"}},
+								{Memory: "fast", NextState: "slow", Actions: []string{"x", "opIn", "runIn"}},
+							},
+						},
+						{
+							Event: "deep", NextState: "idle", Actions: []string{"y", "opIn"},
+							History: "op",
+							HistoryTargets: []*HistoryTarget{
+								{Memory: "idle", NextState: "idle", Actions: []string{"y", "opIn"}},
+								{Memory: "slow", NextState: "slow", Actions: []string{"y", "opIn", "runIn"}},
+								{Memory: "fast", NextState: "fast", Actions: []string{"y", "opIn", "runIn", "fastIn"}},
+							},
+						},
+					}},
+				},
+				Events:  []string{"fail", "start", "stop", "faster", "slower", "fix", "deep"},
+				Actions: []string{"opIn", "runIn", "fastIn", "x", "y"},
+			},
+		)
+	})
+
+	t.Run("With regions", func(t *testing.T) {
+		assertOptimizedFSM(t, `
+			FSM: f
+			Initial: idle
+			{
+				idle {
+					start running -
+				}
+				running >on <off {
+					[conn] {
+						down >dIn {
+							plug up -
+						}
+						up <upOut {
+							unplug down -
+						}
+					}
+					[power] {
+						low {
+							charge high -
+						}
+						high { }
+					}
+					stop idle -
+				}
+			}
+			`,
+			&FSM{
+				Name:         "f",
+				InitialState: "idle",
+				Regions: []*Region{
+					{
+						Name:         "conn",
+						InitialState: "down",
+						Events:       []string{"plug", "unplug"},
+						EntryActions: []string{"dIn"},
+						Exits:        []*RegionExit{{State: "up", Actions: []string{"upOut"}}},
+					},
+					{
+						Name:         "power",
+						InitialState: "low",
+						Events:       []string{"charge"},
+						EntryActions: []string{},
+					},
+				},
+				States: []*State{
+					{Name: "idle", Transitions: []*Transition{
+						{Event: "start", NextState: "running", Actions: []string{"on"}, EnteredRegions: []string{"conn", "power"}},
+					}},
+					{Name: "running", Transitions: []*Transition{
+						{Event: "stop", NextState: "idle", Actions: []string{"off"}, ExitedRegions: []string{"conn", "power"}},
+						{Event: "plug", Actions: []string{}, Inherited: true, Delegated: true},
+						{Event: "unplug", Actions: []string{}, Inherited: true, Delegated: true},
+						{Event: "charge", Actions: []string{}, Inherited: true, Delegated: true},
+					}},
+					{Name: "down", Region: "conn", Transitions: []*Transition{
+						{Event: "plug", NextState: "up", Actions: []string{}},
+					}},
+					{Name: "up", Region: "conn", Transitions: []*Transition{
+						{Event: "unplug", NextState: "down", Actions: []string{"upOut", "dIn"}},
+					}},
+					{Name: "low", Region: "power", Transitions: []*Transition{
+						{Event: "charge", NextState: "high", Actions: []string{}},
+					}},
+					{Name: "high", Region: "power"},
+				},
+				Events:  []string{"start", "stop", "plug", "unplug", "charge"},
+				Actions: []string{"on", "off", "dIn", "upOut"},
+			},
+		)
+
+		fsm := optimizeFSM("FSM: f Initial: a { a { [r] { b { } } } }")
+		assert.Equal(t, []string{"r"}, fsm.InitialRegions)
+	})
+
+	t.Run("With final states", func(t *testing.T) {
+		fsm := optimizeFSM("FSM: f Initial: a Final: c Final: b { a { e b - \n f c - } \n b - - - \n c - - - }")
+		assert.Equal(t, []string{"b", "c"}, fsm.FinalStates)
+
+		fsm = optimizeFSM("FSM: f Initial: a Final: d { a { [r] { c { e d - } \n d { } } } }")
+		assert.Nil(t, fsm.FinalStates)
+	})
+
+	t.Run("With timeouts", func(t *testing.T) {
+		assertOptimizedFSM(t, `
+			FSM: f
+			Initial: login
+			{
+				login {
+					after 30s idle expire
+					ok session -
+				}
+				session {
+					after 1h idle -
+					active {
+						after 5m away -
+					}
+					away {
+						touch active -
+					}
+					logout idle -
+				}
+				idle {
+					login login -
+				}
+			}
+			`,
+			&FSM{
+				Name:         "f",
+				InitialState: "login",
+				Timers: []*Timer{
+					{Event: "loginAfter30s", Duration: 30 * time.Second},
+					{Event: "sessionAfter1h", Duration: time.Hour},
+					{Event: "activeAfter5m", Duration: 5 * time.Minute},
+				},
+				InitialTimers: []string{"loginAfter30s"},
+				States: []*State{
+					{Name: "login", Transitions: []*Transition{
+						{Event: "loginAfter30s", NextState: "idle", Actions: []string{"expire"}, StoppedTimers: []string{"loginAfter30s"}},
+						{
+							Event: "ok", NextState: "active", Actions: []string{},
+							StoppedTimers: []string{"loginAfter30s"},
+							StartedTimers: []string{"sessionAfter1h", "activeAfter5m"},
+						},
+					}},
+					{Name: "active", Transitions: []*Transition{
+						{Event: "activeAfter5m", NextState: "away", Actions: []string{}, StoppedTimers: []string{"activeAfter5m"}},
+						{
+							Event: "sessionAfter1h", NextState: "idle", Actions: []string{}, Inherited: true,
+							StoppedTimers: []string{"activeAfter5m", "sessionAfter1h"},
+						},
+						{
+							Event: "logout", NextState: "idle", Actions: []string{}, Inherited: true,
+							StoppedTimers: []string{"activeAfter5m", "sessionAfter1h"},
+						},
+					}},
+					{Name: "away", Transitions: []*Transition{
+						{Event: "touch", NextState: "active", Actions: []string{}, StartedTimers: []string{"activeAfter5m"}},
+						{
+							Event: "sessionAfter1h", NextState: "idle", Actions: []string{}, Inherited: true,
+							StoppedTimers: []string{"sessionAfter1h"},
+						},
+						{
+							Event: "logout", NextState: "idle", Actions: []string{}, Inherited: true,
+							StoppedTimers: []string{"sessionAfter1h"},
+						},
+					}},
+					{Name: "idle", Transitions: []*Transition{
+						{Event: "login", NextState: "login", Actions: []string{}, StartedTimers: []string{"loginAfter30s"}},
+					}},
+				},
+				Events:  []string{"ok", "logout", "touch", "login"},
+				Actions: []string{"expire"},
+			},
+		)
+
+		fsm := optimizeFSM("FSM: f Initial: a { a { [r] { b { after 1s c - } \n c { } } } }")
+		assert.Equal(t, []*Timer{{Event: "bAfter1s", Region: "r", Duration: time.Second}}, fsm.Timers)
+		assert.Equal(t, []string{"bAfter1s"}, fsm.InitialTimers)
+		assert.Equal(t, []string{"bAfter1s"}, fsm.Regions[0].Timers)
+		assert.Equal(t, []string{"bAfter1s"}, fsm.Regions[0].InitialTimers)
+	})
+
+	t.Run("With choices", func(t *testing.T) {
+		assertOptimizedFSM(t, `
+			FSM: f
+			Initial: editing
+			{
+				editing <save {
+					submit checking validate
+					cancel checking -
+				}
+				checking? {
+					[valid] review -
+					[draft] grading {discard log}
+					editing warn
+				}
+				grading? {
+					[fast] done -
+					review -
+				}
+				review >open {
+					approve done -
+				}
+				done - - -
+			}
+			`,
+			&FSM{
+				Name:         "f",
+				InitialState: "editing",
+				States: []*State{
+					{Name: "editing", Transitions: []*Transition{
+						{Event: "submit", Actions: []string{"validate"}, Branches: []*Transition{
+							{Guard: "valid", NextState: "review", Actions: []string{"save", "open"}},
+							{Guard: "draft", Actions: []string{"discard", "log"}, Branches: []*Transition{
+								{Guard: "fast", NextState: "done", Actions: []string{"save"}},
+								{NextState: "review", Actions: []string{"save", "open"}},
+							}},
+							{NextState: "editing", Actions: []string{"warn"}},
+						}},
+						{Event: "cancel", Actions: []string{}, Branches: []*Transition{
+							{Guard: "valid", NextState: "review", Actions: []string{"save", "open"}},
+							{Guard: "draft", Actions: []string{"discard", "log"}, Branches: []*Transition{
+								{Guard: "fast", NextState: "done", Actions: []string{"save"}},
+								{NextState: "review", Actions: []string{"save", "open"}},
+							}},
+							{NextState: "editing", Actions: []string{"warn"}},
+						}},
+					}},
+					{Name: "review", Transitions: []*Transition{
+						{Event: "approve", NextState: "done", Actions: []string{}},
+					}},
+					{Name: "done"},
+				},
+				Events:  []string{"submit", "cancel", "approve"},
+				Actions: []string{"save", "validate", "discard", "log", "warn", "open"},
+				Guards:  []string{"valid", "draft", "fast"},
+			},
+		)
+	})
+
+	t.Run("With external transitions", func(t *testing.T) {
+		assertOptimizedFSM(t, `
+			FSM: f
+			Initial: locked
+			{
+				locked >lock <unlock {
+					reset locked! alarm
+					coin locked refund
+					after 10s locked! -
+				}
+				session >open <close {
+					idle {
+						touch idle! -
+						tick session -
+						exit session! -
+					}
+				}
+			}
+			`,
+			&FSM{
+				Name:         "f",
+				InitialState: "locked",
+				Timers: []*Timer{
+					{Event: "lockedAfter10s", Duration: 10 * time.Second},
+				},
+				InitialTimers: []string{"lockedAfter10s"},
+				States: []*State{
+					{Name: "locked", Transitions: []*Transition{
+						{
+							Event: "reset", NextState: "locked", Actions: []string{"alarm", "unlock", "lock"},
+							StoppedTimers: []string{"lockedAfter10s"}, StartedTimers: []string{"lockedAfter10s"},
+						},
+						{Event: "coin", NextState: "locked", Actions: []string{"refund"}},
+						{
+							Event: "lockedAfter10s", NextState: "locked", Actions: []string{"unlock", "lock"},
+							StoppedTimers: []string{"lockedAfter10s"}, StartedTimers: []string{"lockedAfter10s"},
+						},
+					}},
+					{Name: "idle", Transitions: []*Transition{
+						{Event: "touch", NextState: "idle", Actions: []string{}},
+						{Event: "tick", NextState: "idle", Actions: []string{}},
+						{Event: "exit", NextState: "idle", Actions: []string{"close", "open"}},
+					}},
+				},
+				Events:  []string{"reset", "coin", "touch", "tick", "exit"},
+				Actions: []string{"lock", "unlock", "alarm", "refund", "open", "close"},
+			},
+		)
+	})
+
+	t.Run("Action ordering", func(t *testing.T) {
+		logic := `
+			{
+				(powered) >powerOn <powerOff {}
+				operational : powered >enable <disable {
+					idle >showIdle <hideIdle {
+						start running startMotor
+					}
+					running >spin <stopSpin {
+						fail broken alarm
+					}
+				}
+				broken >blink <unblink {
+					repair operational fix
+				}
+			}
+			`
+		expectedTransitions := func(fail, start, repair []string) []*State {
+			return []*State{
+				{Name: "idle", Transitions: []*Transition{
+					{Event: "start", NextState: "running", Actions: start},
+				}},
+				{Name: "running", Transitions: []*Transition{
+					{Event: "fail", NextState: "broken", Actions: fail},
+				}},
+				{Name: "broken", Transitions: []*Transition{
+					{Event: "repair", NextState: "idle", Actions: repair},
+				}},
+			}
+		}
+
+		t.Run("Transition actions first by default", func(t *testing.T) {
+			for _, header := range []string{"", "Order: smc"} {
+				fsm := optimizeFSM("FSM: f Initial: idle " + header + logic)
+				assert.Equal(t, expectedTransitions(
+					[]string{"alarm", "stopSpin", "disable", "powerOff", "blink"},
+					[]string{"startMotor", "hideIdle", "spin"},
+					[]string{"fix", "unblink", "enable", "powerOn", "showIdle"},
+				), fsm.States)
+			}
+		})
+
+		t.Run("Exit, transition and entry actions with the UML order", func(t *testing.T) {
+			fsm := optimizeFSM("FSM: f Initial: idle Order: uml" + logic)
+			assert.Equal(t, expectedTransitions(
+				[]string{"stopSpin", "disable", "powerOff", "alarm", "blink"},
+				[]string{"hideIdle", "startMotor", "spin"},
+				[]string{"unblink", "fix", "powerOn", "enable", "showIdle"},
+			), fsm.States)
+		})
+
+		t.Run("Choices", func(t *testing.T) {
+			choiceLogic := `
+			{
+				p <xp {
+					a <xa {
+						e c go
+					}
+					d {
+						f a -
+					}
+				}
+				c? {
+					[ok] d got
+					b other
+				}
+				b >nb {
+					f a -
+				}
+			}
+			`
+			choice := func(fsm *FSM) *Transition {
+				return fsm.States[0].Transitions[0]
+			}
+
+			fsm := optimizeFSM("FSM: f Initial: a" + choiceLogic)
+			assert.Equal(t, &Transition{Event: "e", Actions: []string{"go"}, Branches: []*Transition{
+				{Guard: "ok", NextState: "d", Actions: []string{"got", "xa"}},
+				{NextState: "b", Actions: []string{"other", "xa", "xp", "nb"}},
+			}}, choice(fsm))
+
+			fsm = optimizeFSM("FSM: f Initial: a Order: uml" + choiceLogic)
+			assert.Equal(t, &Transition{Event: "e", Actions: []string{"xa", "go"}, Branches: []*Transition{
+				{Guard: "ok", NextState: "d", Actions: []string{"got"}},
+				{NextState: "b", Actions: []string{"xp", "other", "nb"}},
+			}}, choice(fsm))
+		})
+	})
+
+	t.Run("With wildcard events", func(t *testing.T) {
+		assertOptimizedFSM(t, `
+			FSM: f
+			Initial: idle
+			{
+				(base) {
+					reset idle -
+					* error log
+				}
+				idle : base {
+					start running -
+				}
+				running : base {
+					stop idle -
+					pause [canPause] paused -
+				}
+				paused {
+					* running -
+				}
+				error {
+					reset idle -
+				}
+			}
+			`,
+			&FSM{
+				Name:         "f",
+				InitialState: "idle",
+				States: []*State{
+					{Name: "idle", Transitions: []*Transition{
+						{Event: "start", NextState: "running", Actions: []string{}},
+						{Event: "reset", NextState: "idle", Actions: []string{}, Inherited: true},
+						{Event: "stop", NextState: "error", Actions: []string{"log"}, Inherited: true},
+						{Event: "pause", NextState: "error", Actions: []string{"log"}, Inherited: true},
+					}},
+					{Name: "running", Transitions: []*Transition{
+						{Event: "stop", NextState: "idle", Actions: []string{}},
+						{Event: "pause", Guard: "canPause", NextState: "paused", Actions: []string{}},
+						{Event: "reset", NextState: "idle", Actions: []string{}, Inherited: true},
+						{Event: "start", NextState: "error", Actions: []string{"log"}, Inherited: true},
+						{Event: "pause", NextState: "error", Actions: []string{"log"}, Inherited: true},
+					}},
+					{Name: "paused", Transitions: []*Transition{
+						{Event: "reset", NextState: "running", Actions: []string{}},
+						{Event: "start", NextState: "running", Actions: []string{}},
+						{Event: "stop", NextState: "running", Actions: []string{}},
+						{Event: "pause", NextState: "running", Actions: []string{}},
+					}},
+					{Name: "error", Transitions: []*Transition{
+						{Event: "reset", NextState: "idle", Actions: []string{}},
+					}},
+				},
+				Events:  []string{"reset", "start", "stop", "pause"},
+				Actions: []string{"log"},
+				Guards:  []string{"canPause"},
+			},
+		)
+		fsm := optimizeFSM("FSM: f Initial: a { a { [r] { b { e c - } \n c { } } \n * d - } \n d { f a - } }")
+		assert.Equal(t, []*Transition{
+			{Event: "e", Actions: []string{}, Inherited: true, Delegated: true},
+			{Event: "f", NextState: "d", Actions: []string{}, ExitedRegions: []string{"r"}},
+		}, fsm.States[0].Transitions)
+	})
+
+	t.Run("With event parameters", func(t *testing.T) {
+		assertOptimizedFSM(t, `
+			FSM: a
+			Initial: b
+			Event: c(d int, e string)
+			{
+				b c - f
+			}
+			`,
+			&FSM{
+				Name:         "a",
+				InitialState: "b",
+				States: []*State{
+					{Name: "b", Transitions: []*Transition{
+						{Event: "c", NextState: "", Actions: []string{"f"}},
+					}},
+				},
+				Events:  []string{"c"},
+				Actions: []string{"f"},
+				EventParameters: map[string][]Parameter{
+					"c": {{Name: "d", Type: "int"}, {Name: "e", Type: "string"}},
+				},
+				ActionParameters: map[string][]Parameter{
+					"f": {{Name: "d", Type: "int"}, {Name: "e", Type: "string"}},
+				},
+			},
+		)
+	})
 }
 
 func optimizeFSM(input string) *FSM {
@@ -0,0 +1,135 @@
+package optimizer
+
+import "github.com/geisonbiazus/smc/internal/smc/semantic"
+
+func (o *Optimizer) setRegions() {
+	for _, r := range o.semanticFSM.Regions {
+		o.optimizedFSM.Regions = append(o.optimizedFSM.Regions, &Region{
+			Name:          r.Name,
+			InitialState:  defaultLeaf(r.States[0]).Name,
+			Events:        o.regionEvents(r),
+			EntryActions:  o.regionEntryActions(r),
+			Exits:         o.regionExits(r),
+			Timers:        timersOf(allStates(r.States)),
+			InitialTimers: timersOf(enteredStates(nil, r.States[0])),
+		})
+	}
+
+	initial := defaultLeaf(o.semanticFSM.InitialState)
+	o.optimizedFSM.InitialRegions = regionNames(enteredStates(nil, initial))
+}
+
+func (o *Optimizer) regionEvents(r *semantic.Region) []string {
+	handled := map[string]bool{}
+	for _, leaf := range regionLeaves(r) {
+		o.collectEvents(leaf, handled)
+	}
+
+	events := []string{}
+	for _, event := range o.semanticFSM.Events {
+		if handled[event] {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func (o *Optimizer) collectEvents(state *semantic.State, events map[string]bool) {
+	for _, t := range state.Transitions {
+		if t.Event == semantic.WildcardEvent {
+			for _, event := range o.semanticFSM.Events {
+				events[event] = true
+			}
+		}
+		events[t.Event] = true
+	}
+	for _, super := range state.SuperStates {
+		o.collectEvents(super, events)
+	}
+	if state.Parent != nil {
+		o.collectEvents(state.Parent, events)
+	}
+}
+
+func (o *Optimizer) regionEntryActions(r *semantic.Region) []string {
+	actions := []string{}
+	for _, s := range enteredStates(nil, r.States[0]) {
+		actions = append(actions, o.getEntryActionsRecursively(s)...)
+	}
+	return unique(actions)
+}
+
+func (o *Optimizer) regionExits(r *semantic.Region) []*RegionExit {
+	var exits []*RegionExit
+	for _, leaf := range regionLeaves(r) {
+		actions := []string{}
+		for _, s := range exitedStates(leaf, nil) {
+			actions = append(actions, o.getExitActionsRecursively(s)...)
+		}
+		if len(actions) > 0 {
+			exits = append(exits, &RegionExit{State: leaf.Name, Actions: unique(actions)})
+		}
+	}
+	return exits
+}
+
+func regionLeaves(r *semantic.Region) []*semantic.State {
+	result := []*semantic.State{}
+	for _, s := range r.States {
+		result = append(result, leaves(s)...)
+	}
+	return result
+}
+
+func (o *Optimizer) addRegionTransitions(state *State, s *semantic.State, handledEvents map[string]bool) {
+	for _, owner := range enteredStates(nil, s) {
+		for _, region := range owner.Regions {
+			o.addRegionEvents(state, region, handledEvents)
+		}
+	}
+}
+
+func (o *Optimizer) addRegionEvents(state *State, r *semantic.Region, handledEvents map[string]bool) {
+	for _, event := range o.regionEvents(r) {
+		if !handledEvents[event] {
+			handledEvents[event] = true
+			state.Transitions = append(state.Transitions, &Transition{
+				Event: event, Actions: []string{}, Inherited: true, Delegated: true,
+			})
+		}
+	}
+}
+
+func exitedRegions(source *semantic.State, t semantic.Transition) []string {
+	if t.NextState == nil {
+		return nil
+	}
+	return regionNames(exitedStates(source, transitionAncestor(source, t)))
+}
+
+func enteredRegions(source *semantic.State, t semantic.Transition, resolved *semantic.State) []string {
+	if t.NextState == nil {
+		return nil
+	}
+	return regionNames(enteredStates(transitionAncestor(source, t), resolved))
+}
+
+func regionNames(states []*semantic.State) []string {
+	var names []string
+	for _, s := range states {
+		for _, r := range s.Regions {
+			names = append(names, r.Name)
+		}
+	}
+	return names
+}
+
+func regionName(state *semantic.State) string {
+	for state.Parent != nil {
+		state = state.Parent
+	}
+	if state.Region == nil {
+		return ""
+	}
+	return state.Region.Name
+}
@@ -0,0 +1,71 @@
+package optimizer
+
+import "github.com/geisonbiazus/smc/internal/smc/semantic"
+
+func (o *Optimizer) setTimers() {
+	timers := map[string]bool{}
+	for _, s := range o.semanticFSM.States {
+		for _, t := range s.Timers {
+			if !timers[t.Event] {
+				timers[t.Event] = true
+				o.optimizedFSM.Timers = append(o.optimizedFSM.Timers, &Timer{
+					Event:    t.Event,
+					Region:   regionName(s),
+					Duration: t.Duration,
+				})
+			}
+		}
+	}
+
+	initial := enteredStates(nil, defaultLeaf(o.semanticFSM.InitialState))
+	o.optimizedFSM.InitialTimers = timersOf(initial)
+	for _, s := range initial {
+		for _, r := range s.Regions {
+			o.optimizedFSM.InitialTimers = append(
+				o.optimizedFSM.InitialTimers, timersOf(enteredStates(nil, r.States[0]))...,
+			)
+		}
+	}
+}
+
+func stoppedTimers(source *semantic.State, t semantic.Transition) []string {
+	if t.NextState == nil {
+		return nil
+	}
+	return timersOf(exitedStates(source, transitionAncestor(source, t)))
+}
+
+func startedTimers(source *semantic.State, t semantic.Transition, resolved *semantic.State) []string {
+	if t.NextState == nil {
+		return nil
+	}
+	return timersOf(enteredStates(transitionAncestor(source, t), resolved))
+}
+
+func timersOf(states []*semantic.State) []string {
+	var events []string
+	for _, s := range states {
+		events = append(events, stateTimers(s)...)
+	}
+	return events
+}
+
+func stateTimers(s *semantic.State) []string {
+	events := []string{}
+	for _, t := range s.Timers {
+		events = append(events, t.Event)
+	}
+	for _, super := range s.SuperStates {
+		events = append(events, stateTimers(super)...)
+	}
+	return events
+}
+
+func allStates(states []*semantic.State) []*semantic.State {
+	result := []*semantic.State{}
+	for _, s := range states {
+		result = append(result, s)
+		result = append(result, allStates(s.SubStates)...)
+	}
+	return result
+}
@@ -10,8 +10,16 @@ type FSMSyntax struct {
 }
 
 type Header struct {
-	Name  string
-	Value string
+	Name       string
+	Value      string
+	Parameters []Parameter
+	LineNumber int
+	Position   int
+}
+
+type Parameter struct {
+	Name string
+	Type string
 }
 
 type Transition struct {
@@ -21,16 +29,28 @@ type Transition struct {
 
 type StateSpec struct {
 	Name          string
+	Parent        string
+	Region        string
+	Regions       []string
 	SuperStates   []string
 	EntryActions  []string
 	ExitActions   []string
 	AbstractState bool
+	Choice        bool
+	LineNumber    int
+	Position      int
 }
 
 type SubTransition struct {
-	Event     string
-	NextState string
-	Actions   []string
+	Event      string
+	Timeout    string
+	Guard      string
+	NextState  string
+	History    string
+	External   bool
+	Actions    []string
+	LineNumber int
+	Position   int
 }
 
 type SyntaxError struct {
@@ -47,6 +67,17 @@ func (e SyntaxError) String() string {
 	)
 }
 
+func (e SyntaxError) Location() (line, pos int) {
+	return e.LineNumber, e.Position
+}
+
+func (e SyntaxError) Message() string {
+	if e.Msg == "" {
+		return string(e.Type)
+	}
+	return fmt.Sprintf("%s: %s", e.Type, e.Msg)
+}
+
 type ErrorType string
 
 const (
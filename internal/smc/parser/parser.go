@@ -2,16 +2,33 @@ package parser
 
 type Builder interface {
 	SetName(name string)
+	SetPosition(line, pos int)
 	NewHeader()
 	AddHeaderValue()
+	AddHeaderParameters()
+	AddHeaderParameter()
+	AddHeaderParameterTypeName()
+	AddHeaderParameterTypeToken(token string)
 	AddNewTransition()
 	AddNewAbstractTransition()
+	SetChoice()
+	AddNestedState()
+	CloseStateGroup()
+	AddRegion()
+	AddRegionState()
+	CloseRegion()
 	AddSuperState()
 	AddEntryAction()
 	AddExitAction()
 	AddEmptyEvent()
 	AddEvent()
+	AddWildcardEvent()
+	SetTimeout()
+	AddGuard()
 	AddNextState()
+	AddHistory()
+	AddDeepHistory()
+	SetExternal()
 	AddAction()
 	Done()
 	SyntaxError(line, pos int)
@@ -21,6 +38,7 @@ type Builder interface {
 type Parser struct {
 	Builder Builder
 	state   State
+	groups  []State
 }
 
 func NewParser(builder Builder) *Parser {
@@ -39,6 +57,10 @@ func (p *Parser) Colon(line, pos int) {
 	p.HandleEvent(EventColon, line, pos)
 }
 
+func (p *Parser) Comma(line, pos int) {
+	p.HandleEvent(EventComma, line, pos)
+}
+
 func (p *Parser) OpenParen(line, pos int) {
 	p.HandleEvent(EventOpenParen, line, pos)
 }
@@ -55,15 +77,51 @@ func (p *Parser) ClosedAngle(line, pos int) {
 	p.HandleEvent(EventClosedAngle, line, pos)
 }
 
+func (p *Parser) OpenBracket(line, pos int) {
+	p.HandleEvent(EventOpenBracket, line, pos)
+}
+
+func (p *Parser) ClosedBracket(line, pos int) {
+	p.HandleEvent(EventClosedBracket, line, pos)
+}
+
 func (p *Parser) Dash(line, pos int) {
 	p.HandleEvent(EventDash, line, pos)
 }
 
+func (p *Parser) Star(line, pos int) {
+	p.HandleEvent(EventStar, line, pos)
+}
+
+func (p *Parser) Bang(line, pos int) {
+	p.HandleEvent(EventBang, line, pos)
+}
+
+func (p *Parser) Question(line, pos int) {
+	p.HandleEvent(EventQuestion, line, pos)
+}
+
+func (p *Parser) Dot(line, pos int) {
+	p.HandleEvent(EventDot, line, pos)
+}
+
 func (p *Parser) Name(name string, line, pos int) {
 	p.Builder.SetName(name)
+	if name == KeywordAfter && p.expectsEvent() {
+		p.HandleEvent(EventAfter, line, pos)
+		return
+	}
+	if name == KeywordMap && p.state == StateHeaderParameterType {
+		p.HandleEvent(EventMap, line, pos)
+		return
+	}
 	p.HandleEvent(EventName, line, pos)
 }
 
+func (p *Parser) expectsEvent() bool {
+	return p.state == StateNewTransition || p.state == StateSubTransitionGroup
+}
+
 func (p *Parser) Error(line, pos int) {
 	p.Builder.SyntaxError(line, pos)
 }
@@ -88,6 +146,30 @@ var transitions = []transition{
 	{StateHeader, EventOpenBrace, StateTransitionGroup, NoAction},
 	{StateHeaderColon, EventColon, StateHeaderValue, NoAction},
 	{StateHeaderValue, EventName, StateHeader, func(b Builder) { b.AddHeaderValue() }},
+	{StateHeader, EventOpenParen, StateHeaderParameters, func(b Builder) { b.AddHeaderParameters() }},
+	{StateHeaderParameters, EventClosedParen, StateHeader, NoAction},
+	{StateHeaderParameters, EventName, StateHeaderParameterType, func(b Builder) { b.AddHeaderParameter() }},
+	{StateHeaderParameterType, EventName, StateHeaderParameterEnd, func(b Builder) { b.AddHeaderParameterTypeName() }},
+	{StateHeaderParameterType, EventStar, StateHeaderParameterType, func(b Builder) { b.AddHeaderParameterTypeToken("*") }},
+	{StateHeaderParameterType, EventOpenBracket, StateHeaderParameterBracket, func(b Builder) { b.AddHeaderParameterTypeToken("[") }},
+	{StateHeaderParameterType, EventMap, StateHeaderParameterMap, func(b Builder) { b.AddHeaderParameterTypeToken("map") }},
+	{StateHeaderParameterBracket, EventClosedBracket, StateHeaderParameterType, func(b Builder) { b.AddHeaderParameterTypeToken("]") }},
+	{StateHeaderParameterBracket, EventName, StateHeaderParameterLength, func(b Builder) { b.AddHeaderParameterTypeName() }},
+	{StateHeaderParameterLength, EventClosedBracket, StateHeaderParameterType, func(b Builder) { b.AddHeaderParameterTypeToken("]") }},
+	{StateHeaderParameterMap, EventOpenBracket, StateHeaderParameterKey, func(b Builder) { b.AddHeaderParameterTypeToken("[") }},
+	{StateHeaderParameterKey, EventStar, StateHeaderParameterKey, func(b Builder) { b.AddHeaderParameterTypeToken("*") }},
+	{StateHeaderParameterKey, EventName, StateHeaderParameterKeyName, func(b Builder) { b.AddHeaderParameterTypeName() }},
+	{StateHeaderParameterKeyName, EventDot, StateHeaderParameterKeyPackage, func(b Builder) { b.AddHeaderParameterTypeToken(".") }},
+	{StateHeaderParameterKeyPackage, EventName, StateHeaderParameterKeyEnd, func(b Builder) { b.AddHeaderParameterTypeName() }},
+	{StateHeaderParameterKeyName, EventClosedBracket, StateHeaderParameterType, func(b Builder) { b.AddHeaderParameterTypeToken("]") }},
+	{StateHeaderParameterKeyEnd, EventClosedBracket, StateHeaderParameterType, func(b Builder) { b.AddHeaderParameterTypeToken("]") }},
+	{StateHeaderParameterEnd, EventDot, StateHeaderParameterPackage, func(b Builder) { b.AddHeaderParameterTypeToken(".") }},
+	{StateHeaderParameterPackage, EventName, StateHeaderParameterQualified, func(b Builder) { b.AddHeaderParameterTypeName() }},
+	{StateHeaderParameterQualified, EventComma, StateHeaderParameterName, NoAction},
+	{StateHeaderParameterQualified, EventClosedParen, StateHeader, NoAction},
+	{StateHeaderParameterEnd, EventComma, StateHeaderParameterName, NoAction},
+	{StateHeaderParameterEnd, EventClosedParen, StateHeader, NoAction},
+	{StateHeaderParameterName, EventName, StateHeaderParameterType, func(b Builder) { b.AddHeaderParameter() }},
 
 	{StateTransitionGroup, EventName, StateNewTransition, func(b Builder) { b.AddNewTransition() }},
 	{StateTransitionGroup, EventClosedBrace, StateEnd, NoAction},
@@ -96,7 +178,8 @@ var transitions = []transition{
 	{StateSuperStateName, EventClosedParen, StateNewTransition, NoAction},
 	{StateNewTransition, EventName, StateSingleEvent, func(b Builder) { b.AddEvent() }},
 	{StateNewTransition, EventDash, StateSingleEvent, func(b Builder) { b.AddEmptyEvent() }},
-	{StateNewTransition, EventOpenBrace, StateSubTransitionGroup, NoAction},
+	{StateNewTransition, EventStar, StateSingleEvent, func(b Builder) { b.AddWildcardEvent() }},
+	{StateNewTransition, EventOpenBrace, StateNestedGroup, NoAction},
 	{StateNewTransition, EventColon, StateStateBase, NoAction},
 	{StateNewTransition, EventClosedAngle, StateEntryAction, NoAction},
 	{StateNewTransition, EventOpenAngle, StateExitAction, NoAction},
@@ -105,21 +188,91 @@ var transitions = []transition{
 	{StateExitAction, EventName, StateNewTransition, func(b Builder) { b.AddExitAction() }},
 	{StateEnd, EventEnd, StateEnd, NoAction},
 
+	{StateNewTransition, EventQuestion, StateChoice, func(b Builder) { b.SetChoice() }},
+	{StateChoice, EventOpenBrace, StateChoiceGroup, NoAction},
+	{StateChoiceGroup, EventClosedBrace, StateTransitionGroup, NoAction},
+	{StateChoiceGroup, EventOpenBracket, StateChoiceGuard, func(b Builder) { b.AddEmptyEvent() }},
+	{StateChoiceGroup, EventName, StateChoiceNextState, func(b Builder) { b.AddEmptyEvent(); b.AddNextState() }},
+	{StateChoiceGuard, EventName, StateChoiceGuardName, func(b Builder) { b.AddGuard() }},
+	{StateChoiceGuardName, EventClosedBracket, StateChoiceGuarded, NoAction},
+	{StateChoiceGuarded, EventName, StateChoiceNextState, func(b Builder) { b.AddNextState() }},
+	{StateChoiceNextState, EventName, StateChoiceGroup, func(b Builder) { b.AddAction() }},
+	{StateChoiceNextState, EventDash, StateChoiceGroup, NoAction},
+	{StateChoiceNextState, EventOpenBrace, StateChoiceActionGroup, NoAction},
+	{StateChoiceActionGroup, EventName, StateChoiceActionGroup, func(b Builder) { b.AddAction() }},
+	{StateChoiceActionGroup, EventClosedBrace, StateChoiceGroup, NoAction},
+
+	{StateNewTransition, EventAfter, StateSingleTimeout, func(b Builder) { b.AddEmptyEvent() }},
+	{StateSingleTimeout, EventName, StateSingleEvent, func(b Builder) { b.SetTimeout() }},
 	{StateSingleEvent, EventName, StateNextState, func(b Builder) { b.AddNextState() }},
 	{StateSingleEvent, EventDash, StateNextState, NoAction},
+	{StateSingleEvent, EventOpenBracket, StateSingleGuard, NoAction},
+	{StateSingleGuard, EventName, StateSingleGuardName, func(b Builder) { b.AddGuard() }},
+	{StateSingleGuardName, EventClosedBracket, StateSingleGuarded, NoAction},
+	{StateSingleGuarded, EventName, StateNextState, func(b Builder) { b.AddNextState() }},
+	{StateSingleGuarded, EventDash, StateNextState, NoAction},
 	{StateNextState, EventName, StateTransitionGroup, func(b Builder) { b.AddAction() }},
+	{StateNextState, EventOpenParen, StateHistory, NoAction},
+	{StateHistory, EventName, StateHistoryName, func(b Builder) { b.AddHistory() }},
+	{StateHistoryName, EventStar, StateDeepHistory, func(b Builder) { b.AddDeepHistory() }},
+	{StateHistoryName, EventClosedParen, StateNextState, NoAction},
+	{StateDeepHistory, EventClosedParen, StateNextState, NoAction},
 	{StateNextState, EventOpenBrace, StateActionGroup, NoAction},
 	{StateNextState, EventDash, StateTransitionGroup, NoAction},
+	{StateNextState, EventBang, StateExternalNextState, func(b Builder) { b.SetExternal() }},
+	{StateExternalNextState, EventName, StateTransitionGroup, func(b Builder) { b.AddAction() }},
+	{StateExternalNextState, EventOpenBrace, StateActionGroup, NoAction},
+	{StateExternalNextState, EventDash, StateTransitionGroup, NoAction},
 	{StateActionGroup, EventName, StateActionGroup, func(b Builder) { b.AddAction() }},
 	{StateActionGroup, EventClosedBrace, StateTransitionGroup, NoAction},
 
-	{StateSubTransitionGroup, EventClosedBrace, StateTransitionGroup, NoAction},
+	{StateSubTransitionGroup, EventClosedBrace, StateParentGroup, func(b Builder) { b.CloseStateGroup() }},
 	{StateSubTransitionGroup, EventName, StateSubTransitionEvent, func(b Builder) { b.AddEvent() }},
 	{StateSubTransitionGroup, EventDash, StateSubTransitionEvent, func(b Builder) { b.AddEmptyEvent() }},
+	{StateSubTransitionGroup, EventStar, StateSubTransitionWildcard, func(b Builder) { b.AddWildcardEvent() }},
+	{StateSubTransitionWildcard, EventName, StateSubTransitionNextState, func(b Builder) { b.AddNextState() }},
+	{StateSubTransitionWildcard, EventDash, StateSubTransitionNextState, NoAction},
+	{StateSubTransitionWildcard, EventOpenBracket, StateSubTransitionGuard, NoAction},
+	{StateSubTransitionGroup, EventAfter, StateSubTransitionTimeout, func(b Builder) { b.AddEmptyEvent() }},
+	{StateSubTransitionTimeout, EventName, StateSubTransitionTimed, func(b Builder) { b.SetTimeout() }},
+	{StateSubTransitionTimed, EventName, StateSubTransitionNextState, func(b Builder) { b.AddNextState() }},
+	{StateSubTransitionTimed, EventDash, StateSubTransitionNextState, NoAction},
+	{StateSubTransitionTimed, EventOpenBracket, StateSubTransitionGuard, NoAction},
 	{StateSubTransitionEvent, EventName, StateSubTransitionNextState, func(b Builder) { b.AddNextState() }},
 	{StateSubTransitionEvent, EventDash, StateSubTransitionNextState, NoAction},
+	{StateSubTransitionEvent, EventOpenBracket, StateSubTransitionGuard, NoAction},
+	{StateSubTransitionEvent, EventOpenBrace, StateNestedGroup, func(b Builder) { b.AddNestedState() }},
+	{StateSubTransitionEvent, EventColon, StateNestedStateBase, func(b Builder) { b.AddNestedState() }},
+	{StateSubTransitionEvent, EventClosedAngle, StateNestedEntryAction, func(b Builder) { b.AddNestedState() }},
+	{StateSubTransitionEvent, EventOpenAngle, StateNestedExitAction, func(b Builder) { b.AddNestedState() }},
+	{StateNestedState, EventOpenBrace, StateNestedGroup, NoAction},
+	{StateNestedState, EventColon, StateNestedStateBase, NoAction},
+	{StateNestedState, EventClosedAngle, StateNestedEntryAction, NoAction},
+	{StateNestedState, EventOpenAngle, StateNestedExitAction, NoAction},
+	{StateNestedStateBase, EventName, StateNestedState, func(b Builder) { b.AddSuperState() }},
+	{StateSubTransitionGroup, EventOpenBracket, StateRegion, NoAction},
+	{StateRegion, EventName, StateRegionName, func(b Builder) { b.AddRegion() }},
+	{StateRegionName, EventClosedBracket, StateRegionNamed, NoAction},
+	{StateRegionNamed, EventOpenBrace, StateRegionGroupStart, NoAction},
+	{StateRegionGroup, EventName, StateNestedState, func(b Builder) { b.AddRegionState() }},
+	{StateRegionGroup, EventClosedBrace, StateParentGroup, func(b Builder) { b.CloseRegion() }},
+	{StateNestedEntryAction, EventName, StateNestedState, func(b Builder) { b.AddEntryAction() }},
+	{StateNestedExitAction, EventName, StateNestedState, func(b Builder) { b.AddExitAction() }},
+	{StateSubTransitionGuard, EventName, StateSubTransitionGuardName, func(b Builder) { b.AddGuard() }},
+	{StateSubTransitionGuardName, EventClosedBracket, StateSubTransitionGuarded, NoAction},
+	{StateSubTransitionGuarded, EventName, StateSubTransitionNextState, func(b Builder) { b.AddNextState() }},
+	{StateSubTransitionGuarded, EventDash, StateSubTransitionNextState, NoAction},
 	{StateSubTransitionNextState, EventName, StateSubTransitionGroup, func(b Builder) { b.AddAction() }},
+	{StateSubTransitionNextState, EventOpenParen, StateSubTransitionHistory, NoAction},
+	{StateSubTransitionHistory, EventName, StateSubTransitionHistoryName, func(b Builder) { b.AddHistory() }},
+	{StateSubTransitionHistoryName, EventStar, StateSubTransitionDeepHistory, func(b Builder) { b.AddDeepHistory() }},
+	{StateSubTransitionHistoryName, EventClosedParen, StateSubTransitionNextState, NoAction},
+	{StateSubTransitionDeepHistory, EventClosedParen, StateSubTransitionNextState, NoAction},
 	{StateSubTransitionNextState, EventDash, StateSubTransitionGroup, NoAction},
+	{StateSubTransitionNextState, EventBang, StateSubTransitionExternal, func(b Builder) { b.SetExternal() }},
+	{StateSubTransitionExternal, EventName, StateSubTransitionGroup, func(b Builder) { b.AddAction() }},
+	{StateSubTransitionExternal, EventOpenBrace, StateSubTransitionActionGroup, NoAction},
+	{StateSubTransitionExternal, EventDash, StateSubTransitionGroup, NoAction},
 	{StateSubTransitionNextState, EventOpenBrace, StateSubTransitionActionGroup, NoAction},
 	{StateSubTransitionActionGroup, EventClosedBrace, StateSubTransitionGroup, NoAction},
 	{StateSubTransitionActionGroup, EventName, StateSubTransitionActionGroup, func(b Builder) { b.AddAction() }},
@@ -128,7 +281,8 @@ var transitions = []transition{
 func (p *Parser) HandleEvent(event Event, line, pos int) {
 	for _, t := range transitions {
 		if t.currentState == p.state && t.event == event {
-			p.state = t.newState
+			p.state = p.enter(t.newState)
+			p.Builder.SetPosition(line, pos)
 			t.action(p.Builder)
 			return
 		}
@@ -136,40 +290,122 @@ func (p *Parser) HandleEvent(event Event, line, pos int) {
 	p.HandleEventError(event, line, pos)
 }
 
+func (p *Parser) enter(state State) State {
+	switch state {
+	case StateNestedGroup:
+		p.groups = append(p.groups, StateSubTransitionGroup)
+		return StateSubTransitionGroup
+	case StateRegionGroupStart:
+		p.groups = append(p.groups, StateRegionGroup)
+		return StateRegionGroup
+	case StateParentGroup:
+		p.groups = p.groups[:len(p.groups)-1]
+		if len(p.groups) == 0 {
+			return StateTransitionGroup
+		}
+		return p.groups[len(p.groups)-1]
+	}
+	return state
+}
+
 func (p *Parser) HandleEventError(event Event, line, pos int) {
 	p.Builder.ParseError(p.state, event, line, pos)
 }
 
 const (
-	StateHeader                   State = "HEADER"
-	StateHeaderColon              State = "HEADER_COLON"
-	StateHeaderValue              State = "HEADER_VALUE"
-	StateTransitionGroup          State = "TRANSITION_GROUP"
-	StateNewTransition            State = "NEW_TRANSITION"
-	StateSingleEvent              State = "SINGLE_EVENT"
-	StateNextState                State = "NEXT_STATE"
-	StateActionGroup              State = "ACTION_GROUP"
-	StateSubTransitionGroup       State = "STATE_SUB_TRANSITION_GROUP"
-	StateSubTransitionEvent       State = "STATE_SUB_TRANSITION_EVENT"
-	StateSubTransitionNextState   State = "STATE_SUB_TRANSITION_NEXT_STATE"
-	StateSubTransitionActionGroup State = "STATE_SUB_TRANSITION_ACTION_GROUP"
-	StateSuperState               State = "SUPER_STATE"
-	StateSuperStateName           State = "SUPER_STATE_NAME"
-	StateStateBase                State = "STATE_BASE"
-	StateEntryAction              State = "ENTRY_ACTION"
-	StateExitAction               State = "EXIT_ACTION"
-	StateEnd                      State = "END"
-
-	EventName        Event = "NAME"
-	EventColon       Event = "COLON"
-	EventOpenBrace   Event = "OPEN_BRACE"
-	EventClosedBrace Event = "CLOSED_BRACE"
-	EventDash        Event = "DASH"
-	EventOpenParen   Event = "OPEN_PAREN"
-	EventClosedParen Event = "CLOSED_PAREN"
-	EventOpenAngle   Event = "OPEN_ANGLE"
-	EventClosedAngle Event = "CLOSED_ANGLE"
-	EventEnd         Event = "END"
+	StateHeader                    State = "HEADER"
+	StateHeaderColon               State = "HEADER_COLON"
+	StateHeaderValue               State = "HEADER_VALUE"
+	StateHeaderParameters          State = "HEADER_PARAMETERS"
+	StateHeaderParameterName       State = "HEADER_PARAMETER_NAME"
+	StateHeaderParameterType       State = "HEADER_PARAMETER_TYPE"
+	StateHeaderParameterEnd        State = "HEADER_PARAMETER_END"
+	StateHeaderParameterBracket    State = "HEADER_PARAMETER_BRACKET"
+	StateHeaderParameterLength     State = "HEADER_PARAMETER_LENGTH"
+	StateHeaderParameterMap        State = "HEADER_PARAMETER_MAP"
+	StateHeaderParameterKey        State = "HEADER_PARAMETER_KEY"
+	StateHeaderParameterKeyName    State = "HEADER_PARAMETER_KEY_NAME"
+	StateHeaderParameterKeyPackage State = "HEADER_PARAMETER_KEY_PACKAGE"
+	StateHeaderParameterKeyEnd     State = "HEADER_PARAMETER_KEY_END"
+	StateHeaderParameterPackage    State = "HEADER_PARAMETER_PACKAGE"
+	StateHeaderParameterQualified  State = "HEADER_PARAMETER_QUALIFIED"
+	StateTransitionGroup           State = "TRANSITION_GROUP"
+	StateNewTransition             State = "NEW_TRANSITION"
+	StateSingleTimeout             State = "SINGLE_TIMEOUT"
+	StateSingleEvent               State = "SINGLE_EVENT"
+	StateSingleGuard               State = "SINGLE_GUARD"
+	StateSingleGuardName           State = "SINGLE_GUARD_NAME"
+	StateSingleGuarded             State = "SINGLE_GUARDED"
+	StateNextState                 State = "NEXT_STATE"
+	StateExternalNextState         State = "EXTERNAL_NEXT_STATE"
+	StateHistory                   State = "HISTORY"
+	StateHistoryName               State = "HISTORY_NAME"
+	StateDeepHistory               State = "DEEP_HISTORY"
+	StateActionGroup               State = "ACTION_GROUP"
+	StateSubTransitionGroup        State = "STATE_SUB_TRANSITION_GROUP"
+	StateSubTransitionWildcard     State = "STATE_SUB_TRANSITION_WILDCARD"
+	StateSubTransitionTimeout      State = "STATE_SUB_TRANSITION_TIMEOUT"
+	StateSubTransitionTimed        State = "STATE_SUB_TRANSITION_TIMED"
+	StateSubTransitionEvent        State = "STATE_SUB_TRANSITION_EVENT"
+	StateSubTransitionGuard        State = "STATE_SUB_TRANSITION_GUARD"
+	StateSubTransitionGuardName    State = "STATE_SUB_TRANSITION_GUARD_NAME"
+	StateSubTransitionGuarded      State = "STATE_SUB_TRANSITION_GUARDED"
+	StateSubTransitionNextState    State = "STATE_SUB_TRANSITION_NEXT_STATE"
+	StateSubTransitionExternal     State = "STATE_SUB_TRANSITION_EXTERNAL"
+	StateSubTransitionHistory      State = "STATE_SUB_TRANSITION_HISTORY"
+	StateSubTransitionHistoryName  State = "STATE_SUB_TRANSITION_HISTORY_NAME"
+	StateSubTransitionDeepHistory  State = "STATE_SUB_TRANSITION_DEEP_HISTORY"
+	StateSubTransitionActionGroup  State = "STATE_SUB_TRANSITION_ACTION_GROUP"
+	StateChoice                    State = "CHOICE"
+	StateChoiceGroup               State = "CHOICE_GROUP"
+	StateChoiceGuard               State = "CHOICE_GUARD"
+	StateChoiceGuardName           State = "CHOICE_GUARD_NAME"
+	StateChoiceGuarded             State = "CHOICE_GUARDED"
+	StateChoiceNextState           State = "CHOICE_NEXT_STATE"
+	StateChoiceActionGroup         State = "CHOICE_ACTION_GROUP"
+	StateSuperState                State = "SUPER_STATE"
+	StateSuperStateName            State = "SUPER_STATE_NAME"
+	StateStateBase                 State = "STATE_BASE"
+	StateEntryAction               State = "ENTRY_ACTION"
+	StateExitAction                State = "EXIT_ACTION"
+	StateNestedState               State = "NESTED_STATE"
+	StateNestedStateBase           State = "NESTED_STATE_BASE"
+	StateNestedEntryAction         State = "NESTED_ENTRY_ACTION"
+	StateNestedExitAction          State = "NESTED_EXIT_ACTION"
+	StateNestedGroup               State = "NESTED_GROUP"
+	StateRegion                    State = "REGION"
+	StateRegionName                State = "REGION_NAME"
+	StateRegionNamed               State = "REGION_NAMED"
+	StateRegionGroupStart          State = "REGION_GROUP_START"
+	StateRegionGroup               State = "REGION_GROUP"
+	StateParentGroup               State = "PARENT_GROUP"
+	StateEnd                       State = "END"
+
+	EventName          Event = "NAME"
+	EventAfter         Event = "AFTER"
+	EventMap           Event = "MAP"
+	EventDot           Event = "DOT"
+	EventColon         Event = "COLON"
+	EventComma         Event = "COMMA"
+	EventOpenBrace     Event = "OPEN_BRACE"
+	EventClosedBrace   Event = "CLOSED_BRACE"
+	EventDash          Event = "DASH"
+	EventStar          Event = "STAR"
+	EventBang          Event = "BANG"
+	EventQuestion      Event = "QUESTION"
+	EventOpenParen     Event = "OPEN_PAREN"
+	EventClosedParen   Event = "CLOSED_PAREN"
+	EventOpenAngle     Event = "OPEN_ANGLE"
+	EventClosedAngle   Event = "CLOSED_ANGLE"
+	EventOpenBracket   Event = "OPEN_BRACKET"
+	EventClosedBracket Event = "CLOSED_BRACKET"
+	EventEnd           Event = "END"
 )
 
+const KeywordAfter = "after"
+
+const KeywordMap = "map"
+
+const WildcardEvent = "*"
+
 var NoAction = func(Builder) {}
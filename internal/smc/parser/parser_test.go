@@ -25,7 +25,7 @@ func TestParser(t *testing.T) {
 			FSMSyntax{
 				Headers: []Header{{Name: "a", Value: "b"}},
 				Logic: []Transition{
-					{StateSpec{Name: "c"}, []SubTransition{{"d", "e", []string{"f"}}}},
+					{StateSpec{Name: "c"}, []SubTransition{{Event: "d", NextState: "e", Actions: []string{"f"}}}},
 				},
 				Done: true,
 			})
@@ -35,8 +35,8 @@ func TestParser(t *testing.T) {
 			FSMSyntax{
 				Headers: []Header{{Name: "a", Value: "b"}},
 				Logic: []Transition{
-					{StateSpec{Name: "c"}, []SubTransition{{"d", "e", []string{"f", "g"}}}},
-					{StateSpec{Name: "h"}, []SubTransition{{"i", "j", []string{"k"}}}},
+					{StateSpec{Name: "c"}, []SubTransition{{Event: "d", NextState: "e", Actions: []string{"f", "g"}}}},
+					{StateSpec{Name: "h"}, []SubTransition{{Event: "i", NextState: "j", Actions: []string{"k"}}}},
 				},
 				Done: true,
 			})
@@ -46,8 +46,8 @@ func TestParser(t *testing.T) {
 			FSMSyntax{
 				Headers: []Header{{Name: "a", Value: "b"}},
 				Logic: []Transition{
-					{StateSpec{Name: "c"}, []SubTransition{{"d", "e", []string{}}}},
-					{StateSpec{Name: "f"}, []SubTransition{{"g", "h", []string{"i"}}}},
+					{StateSpec{Name: "c"}, []SubTransition{{Event: "d", NextState: "e", Actions: []string{}}}},
+					{StateSpec{Name: "f"}, []SubTransition{{Event: "g", NextState: "h", Actions: []string{"i"}}}},
 				},
 				Done: true,
 			})
@@ -57,7 +57,7 @@ func TestParser(t *testing.T) {
 			FSMSyntax{
 				Headers: []Header{{Name: "a", Value: "b"}},
 				Logic: []Transition{
-					{StateSpec{Name: "c"}, []SubTransition{{"d", "", []string{"e"}}}},
+					{StateSpec{Name: "c"}, []SubTransition{{Event: "d", NextState: "", Actions: []string{"e"}}}},
 				},
 				Done: true,
 			})
@@ -67,7 +67,7 @@ func TestParser(t *testing.T) {
 			FSMSyntax{
 				Headers: []Header{{Name: "a", Value: "b"}},
 				Logic: []Transition{
-					{StateSpec{Name: "c"}, []SubTransition{{"", "d", []string{"e"}}}},
+					{StateSpec{Name: "c"}, []SubTransition{{Event: "", NextState: "d", Actions: []string{"e"}}}},
 				},
 				Done: true,
 			})
@@ -79,8 +79,8 @@ func TestParser(t *testing.T) {
 				Logic: []Transition{
 					{
 						StateSpec{Name: "c"}, []SubTransition{
-							{"d", "e", []string{"f"}},
-							{"g", "h", []string{"i"}},
+							{Event: "d", NextState: "e", Actions: []string{"f"}},
+							{Event: "g", NextState: "h", Actions: []string{"i"}},
 						},
 					},
 				},
@@ -92,8 +92,8 @@ func TestParser(t *testing.T) {
 			FSMSyntax{
 				Headers: []Header{{Name: "a", Value: "b"}},
 				Logic: []Transition{
-					{StateSpec{Name: "c"}, []SubTransition{{"", "", []string{}}}},
-					{StateSpec{Name: "g"}, []SubTransition{{"h", "i", []string{"j"}}}},
+					{StateSpec{Name: "c"}, []SubTransition{{Event: "", NextState: "", Actions: []string{}}}},
+					{StateSpec{Name: "g"}, []SubTransition{{Event: "h", NextState: "i", Actions: []string{"j"}}}},
 				},
 				Done: true,
 			})
@@ -110,8 +110,8 @@ func TestParser(t *testing.T) {
 				Logic: []Transition{
 					{
 						StateSpec{Name: "c"}, []SubTransition{
-							{"d", "e", []string{"f", "g"}},
-							{"h", "i", []string{"j"}},
+							{Event: "d", NextState: "e", Actions: []string{"f", "g"}},
+							{Event: "h", NextState: "i", Actions: []string{"j"}},
 						},
 					},
 				},
@@ -129,17 +129,17 @@ func TestParser(t *testing.T) {
 				Logic: []Transition{
 					{
 						StateSpec{Name: "c", AbstractState: true}, []SubTransition{
-							{"d", "e", []string{"f"}},
+							{Event: "d", NextState: "e", Actions: []string{"f"}},
 						},
 					},
 					{
 						StateSpec{Name: "g", AbstractState: true}, []SubTransition{
-							{"h", "i", []string{}},
+							{Event: "h", NextState: "i", Actions: []string{}},
 						},
 					},
 					{
 						StateSpec{Name: "j", SuperStates: []string{"c", "g"}}, []SubTransition{
-							{"", "", []string{}},
+							{Event: "", NextState: "", Actions: []string{}},
 						},
 					},
 				},
@@ -159,17 +159,368 @@ func TestParser(t *testing.T) {
 							EntryActions: []string{"d", "e"},
 							ExitActions:  []string{"f", "g"},
 						}, []SubTransition{
-							{"h", "i", []string{"j"}},
+							{Event: "h", NextState: "i", Actions: []string{"j"}},
 						},
 					},
 				},
 				Done: true,
 			})
+		assertParserResult(t,
+			"a:b { c d [g] e f }",
+			FSMSyntax{
+				Headers: []Header{{Name: "a", Value: "b"}},
+				Logic: []Transition{
+					{StateSpec{Name: "c"}, []SubTransition{
+						{Event: "d", Guard: "g", NextState: "e", Actions: []string{"f"}},
+					}},
+				},
+				Done: true,
+			})
+		assertParserResult(t,
+			"a:b { c { d [g] e f \n d [h] - - \n d - i } }",
+			FSMSyntax{
+				Headers: []Header{{Name: "a", Value: "b"}},
+				Logic: []Transition{
+					{StateSpec{Name: "c"}, []SubTransition{
+						{Event: "d", Guard: "g", NextState: "e", Actions: []string{"f"}},
+						{Event: "d", Guard: "h", NextState: "", Actions: []string{}},
+						{Event: "d", NextState: "", Actions: []string{"i"}},
+					}},
+				},
+				Done: true,
+			})
+		assertParserResult(t,
+			"a:b c:d() e:f(g h) i:j(k l, m n) {}",
+			FSMSyntax{
+				Headers: []Header{
+					{Name: "a", Value: "b"},
+					{Name: "c", Value: "d", Parameters: []Parameter{}},
+					{Name: "e", Value: "f", Parameters: []Parameter{{Name: "g", Type: "h"}}},
+					{Name: "i", Value: "j", Parameters: []Parameter{
+						{Name: "k", Type: "l"},
+						{Name: "m", Type: "n"},
+					}},
+				},
+				Done: true,
+			})
+		assertParserResult(t,
+			"a:b(c *http.Request, d []byte, e map[string]*f.G, g [4]*int, h map[*k.L][]int) {}",
+			FSMSyntax{
+				Headers: []Header{
+					{Name: "a", Value: "b", Parameters: []Parameter{
+						{Name: "c", Type: "*http.Request"},
+						{Name: "d", Type: "[]byte"},
+						{Name: "e", Type: "map[string]*f.G"},
+						{Name: "g", Type: "[4]*int"},
+						{Name: "h", Type: "map[*k.L][]int"},
+					}},
+				},
+				Done: true,
+			})
+	})
+
+	t.Run("Nested states", func(t *testing.T) {
+		assertParserResult(t,
+			`a:b {
+				c >d {
+					e {
+						f g -
+						h {
+							i j -
+						}
+					}
+					k : l <m { n - - }
+					o e p
+				}
+			}`,
+			FSMSyntax{
+				Headers: []Header{{Name: "a", Value: "b"}},
+				Logic: []Transition{
+					{StateSpec{Name: "c", EntryActions: []string{"d"}}, []SubTransition{
+						{Event: "o", NextState: "e", Actions: []string{"p"}},
+					}},
+					{StateSpec{Name: "e", Parent: "c"}, []SubTransition{
+						{Event: "f", NextState: "g", Actions: []string{}},
+					}},
+					{StateSpec{Name: "h", Parent: "e"}, []SubTransition{
+						{Event: "i", NextState: "j", Actions: []string{}},
+					}},
+					{
+						StateSpec{
+							Name:        "k",
+							Parent:      "c",
+							SuperStates: []string{"l"},
+							ExitActions: []string{"m"},
+						},
+						[]SubTransition{{Event: "n", Actions: []string{}}},
+					},
+				},
+				Done: true,
+			})
+		assertParserResult(t,
+			"a:b { c { d { } } e f g h }",
+			FSMSyntax{
+				Headers: []Header{{Name: "a", Value: "b"}},
+				Logic: []Transition{
+					{StateSpec{Name: "c"}, []SubTransition{}},
+					{StateSpec{Name: "d", Parent: "c"}, nil},
+					{StateSpec{Name: "e"}, []SubTransition{
+						{Event: "f", NextState: "g", Actions: []string{"h"}},
+					}},
+				},
+				Done: true,
+			})
+	})
+
+	t.Run("History targets", func(t *testing.T) {
+		assertParserResult(t,
+			"a:b { c d e(H) - \n f { g h(H*) i \n j k(H) {l} } }",
+			FSMSyntax{
+				Headers: []Header{{Name: "a", Value: "b"}},
+				Logic: []Transition{
+					{StateSpec{Name: "c"}, []SubTransition{
+						{Event: "d", NextState: "e", History: "H", Actions: []string{}},
+					}},
+					{StateSpec{Name: "f"}, []SubTransition{
+						{Event: "g", NextState: "h", History: "H*", Actions: []string{"i"}},
+						{Event: "j", NextState: "k", History: "H", Actions: []string{"l"}},
+					}},
+				},
+				Done: true,
+			})
+		assertParserResult(t,
+			"a:b { c d e(*)",
+			FSMSyntax{
+				Headers: []Header{{Name: "a", Value: "b"}},
+				Logic: []Transition{
+					{StateSpec{Name: "c"}, []SubTransition{
+						{Event: "d", NextState: "e", Actions: []string{}},
+					}},
+				},
+				Errors: []SyntaxError{
+					{Type: ErrorParse, LineNumber: 1, Position: 13, Msg: "HISTORY|STAR"},
+					{Type: ErrorParse, LineNumber: 1, Position: 14, Msg: "HISTORY|CLOSED_PAREN"},
+					{Type: ErrorParse, LineNumber: 2, Position: 1, Msg: "HISTORY|END"},
+				},
+				Done: true,
+			})
+	})
+
+	t.Run("Timeouts", func(t *testing.T) {
+		assertParserResult(t,
+			"a:b { c after 5s d e \n f { after 1m30s [g] h - \n after 2s - i \n after 3s after - } }",
+			FSMSyntax{
+				Headers: []Header{{Name: "a", Value: "b"}},
+				Logic: []Transition{
+					{StateSpec{Name: "c"}, []SubTransition{
+						{Timeout: "5s", NextState: "d", Actions: []string{"e"}},
+					}},
+					{StateSpec{Name: "f"}, []SubTransition{
+						{Timeout: "1m30s", Guard: "g", NextState: "h", Actions: []string{}},
+						{Timeout: "2s", Actions: []string{"i"}},
+						{Timeout: "3s", NextState: "after", Actions: []string{}},
+					}},
+				},
+				Done: true,
+			})
+		assertParserResult(t,
+			"a:b { c after { } }",
+			FSMSyntax{
+				Headers: []Header{{Name: "a", Value: "b"}},
+				Logic: []Transition{
+					{StateSpec{Name: "c"}, []SubTransition{{Actions: []string{}}}},
+				},
+				Errors: []SyntaxError{
+					{Type: ErrorParse, LineNumber: 1, Position: 15, Msg: "SINGLE_TIMEOUT|OPEN_BRACE"},
+					{Type: ErrorParse, LineNumber: 1, Position: 17, Msg: "SINGLE_TIMEOUT|CLOSED_BRACE"},
+					{Type: ErrorParse, LineNumber: 1, Position: 19, Msg: "SINGLE_TIMEOUT|CLOSED_BRACE"},
+					{Type: ErrorParse, LineNumber: 2, Position: 1, Msg: "SINGLE_TIMEOUT|END"},
+				},
+				Done: true,
+			})
+	})
+
+	t.Run("Choices", func(t *testing.T) {
+		assertParserResult(t,
+			"a:b { c d e f \n e? { [g] h i \n [j] c { k l } \n m - } }",
+			FSMSyntax{
+				Headers: []Header{{Name: "a", Value: "b"}},
+				Logic: []Transition{
+					{StateSpec{Name: "c"}, []SubTransition{
+						{Event: "d", NextState: "e", Actions: []string{"f"}},
+					}},
+					{StateSpec{Name: "e", Choice: true}, []SubTransition{
+						{Guard: "g", NextState: "h", Actions: []string{"i"}},
+						{Guard: "j", NextState: "c", Actions: []string{"k", "l"}},
+						{NextState: "m", Actions: []string{}},
+					}},
+				},
+				Done: true,
+			})
+		assertParserResult(t,
+			"a:b { e? { - h - } }",
+			FSMSyntax{
+				Headers: []Header{{Name: "a", Value: "b"}},
+				Logic: []Transition{
+					{StateSpec{Name: "e", Choice: true}, []SubTransition{
+						{NextState: "h", Actions: []string{}},
+					}},
+				},
+				Errors: []SyntaxError{
+					{Type: ErrorParse, LineNumber: 1, Position: 12, Msg: "CHOICE_GROUP|DASH"},
+				},
+				Done: true,
+			})
+	})
+
+	t.Run("External transitions", func(t *testing.T) {
+		assertParserResult(t,
+			"a:b { c d c! e \n f { g f! - \n h i(H)! { j k } } }",
+			FSMSyntax{
+				Headers: []Header{{Name: "a", Value: "b"}},
+				Logic: []Transition{
+					{StateSpec{Name: "c"}, []SubTransition{
+						{Event: "d", NextState: "c", External: true, Actions: []string{"e"}},
+					}},
+					{StateSpec{Name: "f"}, []SubTransition{
+						{Event: "g", NextState: "f", External: true, Actions: []string{}},
+						{Event: "h", NextState: "i", History: "H", External: true, Actions: []string{"j", "k"}},
+					}},
+				},
+				Done: true,
+			})
+		assertParserResult(t,
+			"a:b { c d c!! e }",
+			FSMSyntax{
+				Headers: []Header{{Name: "a", Value: "b"}},
+				Logic: []Transition{
+					{StateSpec{Name: "c"}, []SubTransition{
+						{Event: "d", NextState: "c", External: true, Actions: []string{"e"}},
+					}},
+				},
+				Errors: []SyntaxError{
+					{Type: ErrorParse, LineNumber: 1, Position: 13, Msg: "EXTERNAL_NEXT_STATE|BANG"},
+				},
+				Done: true,
+			})
+	})
+
+	t.Run("Wildcard events", func(t *testing.T) {
+		assertParserResult(t,
+			"a:b { c * d e \n f { g h - \n * [i] j - \n * - k } }",
+			FSMSyntax{
+				Headers: []Header{{Name: "a", Value: "b"}},
+				Logic: []Transition{
+					{StateSpec{Name: "c"}, []SubTransition{
+						{Event: "*", NextState: "d", Actions: []string{"e"}},
+					}},
+					{StateSpec{Name: "f"}, []SubTransition{
+						{Event: "g", NextState: "h", Actions: []string{}},
+						{Event: "*", Guard: "i", NextState: "j", Actions: []string{}},
+						{Event: "*", Actions: []string{"k"}},
+					}},
+				},
+				Done: true,
+			})
+		assertParserResult(t,
+			"a:b { c { * { } } }",
+			FSMSyntax{
+				Headers: []Header{{Name: "a", Value: "b"}},
+				Logic: []Transition{
+					{StateSpec{Name: "c"}, []SubTransition{{Event: "*", Actions: []string{}}}},
+				},
+				Errors: []SyntaxError{
+					{Type: ErrorParse, LineNumber: 1, Position: 13, Msg: "STATE_SUB_TRANSITION_WILDCARD|OPEN_BRACE"},
+					{Type: ErrorParse, LineNumber: 1, Position: 15, Msg: "STATE_SUB_TRANSITION_WILDCARD|CLOSED_BRACE"},
+					{Type: ErrorParse, LineNumber: 1, Position: 17, Msg: "STATE_SUB_TRANSITION_WILDCARD|CLOSED_BRACE"},
+					{Type: ErrorParse, LineNumber: 1, Position: 19, Msg: "STATE_SUB_TRANSITION_WILDCARD|CLOSED_BRACE"},
+					{Type: ErrorParse, LineNumber: 2, Position: 1, Msg: "STATE_SUB_TRANSITION_WILDCARD|END"},
+				},
+				Done: true,
+			})
+	})
+
+	t.Run("Regions", func(t *testing.T) {
+		assertParserResult(t,
+			`a:b {
+				c {
+					[d] {
+						e { f g - }
+						g <h { i e - }
+					}
+					[j] {
+						k { l - - }
+					}
+					m n -
+				}
+			}`,
+			FSMSyntax{
+				Headers: []Header{{Name: "a", Value: "b"}},
+				Logic: []Transition{
+					{StateSpec{Name: "c", Regions: []string{"d", "j"}}, []SubTransition{
+						{Event: "m", NextState: "n", Actions: []string{}},
+					}},
+					{StateSpec{Name: "e", Parent: "c", Region: "d"}, []SubTransition{
+						{Event: "f", NextState: "g", Actions: []string{}},
+					}},
+					{StateSpec{Name: "g", Parent: "c", Region: "d", ExitActions: []string{"h"}}, []SubTransition{
+						{Event: "i", NextState: "e", Actions: []string{}},
+					}},
+					{StateSpec{Name: "k", Parent: "c", Region: "j"}, []SubTransition{
+						{Event: "l", Actions: []string{}},
+					}},
+				},
+				Done: true,
+			})
+		assertParserResult(t,
+			"a:b { c { [d] { e f - } } }",
+			FSMSyntax{
+				Headers: []Header{{Name: "a", Value: "b"}},
+				Logic: []Transition{
+					{StateSpec{Name: "c", Regions: []string{"d"}}, nil},
+					{StateSpec{Name: "e", Parent: "c", Region: "d"}, nil},
+				},
+				Errors: []SyntaxError{
+					{Type: ErrorParse, LineNumber: 1, Position: 19, Msg: "NESTED_STATE|NAME"},
+					{Type: ErrorParse, LineNumber: 1, Position: 21, Msg: "NESTED_STATE|DASH"},
+					{Type: ErrorParse, LineNumber: 1, Position: 23, Msg: "NESTED_STATE|CLOSED_BRACE"},
+					{Type: ErrorParse, LineNumber: 1, Position: 25, Msg: "NESTED_STATE|CLOSED_BRACE"},
+					{Type: ErrorParse, LineNumber: 1, Position: 27, Msg: "NESTED_STATE|CLOSED_BRACE"},
+					{Type: ErrorParse, LineNumber: 2, Position: 1, Msg: "NESTED_STATE|END"},
+				},
+				Done: true,
+			})
+	})
+
+	t.Run("Positions", func(t *testing.T) {
+		fsm := parseFSM("a:b\n  c: d {\n  e { f g h\n    - i j }\n  (k) l - - }")
+
+		assert.Equal(t, []Header{
+			{Name: "a", Value: "b", LineNumber: 1, Position: 1},
+			{Name: "c", Value: "d", LineNumber: 2, Position: 3},
+		}, fsm.Headers)
+
+		assert.Equal(t, StateSpec{Name: "e", LineNumber: 3, Position: 3}, fsm.Logic[0].StateSpec)
+		assert.Equal(t, []SubTransition{
+			{Event: "f", NextState: "g", Actions: []string{"h"}, LineNumber: 3, Position: 7},
+			{Event: "", NextState: "i", Actions: []string{"j"}, LineNumber: 4, Position: 5},
+		}, fsm.Logic[0].SubTransitions)
+
+		assert.Equal(t,
+			StateSpec{Name: "k", AbstractState: true, LineNumber: 5, Position: 4},
+			fsm.Logic[1].StateSpec,
+		)
+
+		fsm = parseFSM("a:b {\n  c {\n    d { } } }")
+		assert.Equal(t,
+			StateSpec{Name: "d", Parent: "c", LineNumber: 3, Position: 5},
+			fsm.Logic[1].StateSpec,
+		)
 	})
 
 	t.Run("Error tests", func(t *testing.T) {
 		assertParserResult(t,
-			"a:b . {}",
+			"a:b & {}",
 			FSMSyntax{
 				Headers: []Header{
 					{Name: "a", Value: "b"},
@@ -190,7 +541,7 @@ func TestParser(t *testing.T) {
 					{Name: "c", Value: "d"},
 				},
 				Logic: []Transition{
-					{StateSpec{Name: "e"}, []SubTransition{{"f", "g", []string{"h"}}}},
+					{StateSpec{Name: "e"}, []SubTransition{{Event: "f", NextState: "g", Actions: []string{"h"}}}},
 				},
 				Errors: []SyntaxError{
 					{Type: ErrorParse, LineNumber: 1, Position: 4, Msg: "HEADER|COLON"},
@@ -199,6 +550,20 @@ func TestParser(t *testing.T) {
 				Done: true,
 			})
 
+		assertParserResult(t,
+			"a:b { c d [g h] e - }",
+			FSMSyntax{
+				Headers: []Header{{Name: "a", Value: "b"}},
+				Logic: []Transition{
+					{StateSpec{Name: "c"}, []SubTransition{
+						{Event: "d", Guard: "g", NextState: "e", Actions: []string{}},
+					}},
+				},
+				Errors: []SyntaxError{
+					{Type: ErrorParse, LineNumber: 1, Position: 14, Msg: "SINGLE_GUARD_NAME|NAME"},
+				},
+				Done: true,
+			})
 		assertParserResult(t,
 			"a:b {",
 			FSMSyntax{
@@ -210,6 +575,31 @@ func TestParser(t *testing.T) {
 				},
 				Done: true,
 			})
+		assertParserResult(t,
+			"a:b(c map[]d)",
+			FSMSyntax{
+				Headers: []Header{
+					{Name: "a", Value: "b", Parameters: []Parameter{{Name: "c", Type: "map[d"}}},
+				},
+				Errors: []SyntaxError{
+					{Type: ErrorParse, LineNumber: 1, Position: 11, Msg: "HEADER_PARAMETER_KEY|CLOSED_BRACKET"},
+					{Type: ErrorParse, LineNumber: 1, Position: 13, Msg: "HEADER_PARAMETER_KEY_NAME|CLOSED_PAREN"},
+					{Type: ErrorParse, LineNumber: 2, Position: 1, Msg: "HEADER_PARAMETER_KEY_NAME|END"},
+				},
+				Done: true,
+			})
+		assertParserResult(t,
+			"a:b(c d,)",
+			FSMSyntax{
+				Headers: []Header{
+					{Name: "a", Value: "b", Parameters: []Parameter{{Name: "c", Type: "d"}}},
+				},
+				Errors: []SyntaxError{
+					{Type: ErrorParse, LineNumber: 1, Position: 9, Msg: "HEADER_PARAMETER_NAME|CLOSED_PAREN"},
+					{Type: ErrorParse, LineNumber: 2, Position: 1, Msg: "HEADER_PARAMETER_NAME|END"},
+				},
+				Done: true,
+			})
 	})
 
 	t.Run("Acceptance tests", func(t *testing.T) {
@@ -228,10 +618,10 @@ func TestParser(t *testing.T) {
 					{Name: "Initial", Value: "Locked"},
 				},
 				Logic: []Transition{
-					{StateSpec{Name: "Locked"}, []SubTransition{{"Coin", "Unlocked", []string{"alarmOff", "unlock"}}}},
-					{StateSpec{Name: "Locked"}, []SubTransition{{"Pass", "Locked", []string{"alarmOn"}}}},
-					{StateSpec{Name: "Unlocked"}, []SubTransition{{"Coin", "Unlocked", []string{"thankyou"}}}},
-					{StateSpec{Name: "Unlocked"}, []SubTransition{{"Pass", "Locked", []string{"lock"}}}},
+					{StateSpec{Name: "Locked"}, []SubTransition{{Event: "Coin", NextState: "Unlocked", Actions: []string{"alarmOff", "unlock"}}}},
+					{StateSpec{Name: "Locked"}, []SubTransition{{Event: "Pass", NextState: "Locked", Actions: []string{"alarmOn"}}}},
+					{StateSpec{Name: "Unlocked"}, []SubTransition{{Event: "Coin", NextState: "Unlocked", Actions: []string{"thankyou"}}}},
+					{StateSpec{Name: "Unlocked"}, []SubTransition{{Event: "Pass", NextState: "Locked", Actions: []string{"lock"}}}},
 				},
 				Done: true,
 			})
@@ -267,22 +657,22 @@ func TestParser(t *testing.T) {
 				},
 				Logic: []Transition{
 					{StateSpec{Name: "Locked"}, []SubTransition{
-						{"Pass", "Alarming", []string{"alarmOn"}},
-						{"Coin", "FirstCoin", []string{}},
-						{"Reset", "Locked", []string{"lock", "alarmOff"}},
+						{Event: "Pass", NextState: "Alarming", Actions: []string{"alarmOn"}},
+						{Event: "Coin", NextState: "FirstCoin", Actions: []string{}},
+						{Event: "Reset", NextState: "Locked", Actions: []string{"lock", "alarmOff"}},
 					}},
 					{StateSpec{Name: "Alarming"}, []SubTransition{
-						{"Reset", "Locked", []string{"lock", "alarmOff"}},
+						{Event: "Reset", NextState: "Locked", Actions: []string{"lock", "alarmOff"}},
 					}},
 					{StateSpec{Name: "FirstCoin"}, []SubTransition{
-						{"Pass", "Alarming", []string{}},
-						{"Coin", "Unlocked", []string{"unlock"}},
-						{"Reset", "Locked", []string{"lock", "alarmOff"}},
+						{Event: "Pass", NextState: "Alarming", Actions: []string{}},
+						{Event: "Coin", NextState: "Unlocked", Actions: []string{"unlock"}},
+						{Event: "Reset", NextState: "Locked", Actions: []string{"lock", "alarmOff"}},
 					}},
 					{StateSpec{Name: "Unlocked"}, []SubTransition{
-						{"Pass", "Locked", []string{"lock"}},
-						{"Coin", "", []string{"thankyou"}},
-						{"Reset", "Locked", []string{"lock", "alarmOff"}},
+						{Event: "Pass", NextState: "Locked", Actions: []string{"lock"}},
+						{Event: "Coin", NextState: "", Actions: []string{"thankyou"}},
+						{Event: "Reset", NextState: "Locked", Actions: []string{"lock", "alarmOff"}},
 					}},
 				},
 				Done: true,
@@ -318,22 +708,22 @@ func TestParser(t *testing.T) {
 				},
 				Logic: []Transition{
 					{StateSpec{Name: "Base", AbstractState: true}, []SubTransition{
-						{"Reset", "Locked", []string{"alarmOff", "lock"}},
+						{Event: "Reset", NextState: "Locked", Actions: []string{"alarmOff", "lock"}},
 					}},
 					{StateSpec{Name: "Locked", SuperStates: []string{"Base"}}, []SubTransition{
-						{"Pass", "Alarming", []string{"alarmOn"}},
-						{"Coin", "FirstCoin", []string{}},
+						{Event: "Pass", NextState: "Alarming", Actions: []string{"alarmOn"}},
+						{Event: "Coin", NextState: "FirstCoin", Actions: []string{}},
 					}},
 					{StateSpec{Name: "Alarming", SuperStates: []string{"Base"}}, []SubTransition{
-						{"", "", []string{}},
+						{Event: "", NextState: "", Actions: []string{}},
 					}},
 					{StateSpec{Name: "FirstCoin", SuperStates: []string{"Base"}}, []SubTransition{
-						{"Pass", "Alarming", []string{}},
-						{"Coin", "Unlocked", []string{"unlock"}},
+						{Event: "Pass", NextState: "Alarming", Actions: []string{}},
+						{Event: "Coin", NextState: "Unlocked", Actions: []string{"unlock"}},
 					}},
 					{StateSpec{Name: "Unlocked", SuperStates: []string{"Base"}}, []SubTransition{
-						{"Pass", "Locked", []string{"lock"}},
-						{"Coin", "", []string{"thankyou"}},
+						{Event: "Pass", NextState: "Locked", Actions: []string{"lock"}},
+						{Event: "Coin", NextState: "", Actions: []string{"thankyou"}},
 					}},
 				},
 				Done: true,
@@ -371,11 +761,11 @@ func TestParser(t *testing.T) {
 				},
 				Logic: []Transition{
 					{StateSpec{Name: "Base", AbstractState: true}, []SubTransition{
-						{"Reset", "Locked", []string{"lock"}},
+						{Event: "Reset", NextState: "Locked", Actions: []string{"lock"}},
 					}},
 					{StateSpec{Name: "Locked", SuperStates: []string{"Base"}}, []SubTransition{
-						{"Pass", "Alarming", []string{}},
-						{"Coin", "FirstCoin", []string{}},
+						{Event: "Pass", NextState: "Alarming", Actions: []string{}},
+						{Event: "Coin", NextState: "FirstCoin", Actions: []string{}},
 					}},
 					{StateSpec{
 						Name:         "Alarming",
@@ -383,15 +773,15 @@ func TestParser(t *testing.T) {
 						EntryActions: []string{"alarmOn"},
 						ExitActions:  []string{"alarmOff"},
 					}, []SubTransition{
-						{"", "", []string{}},
+						{Event: "", NextState: "", Actions: []string{}},
 					}},
 					{StateSpec{Name: "FirstCoin", SuperStates: []string{"Base"}}, []SubTransition{
-						{"Pass", "Alarming", []string{}},
-						{"Coin", "Unlocked", []string{"unlock"}},
+						{Event: "Pass", NextState: "Alarming", Actions: []string{}},
+						{Event: "Coin", NextState: "Unlocked", Actions: []string{"unlock"}},
 					}},
 					{StateSpec{Name: "Unlocked", SuperStates: []string{"Base"}}, []SubTransition{
-						{"Pass", "Locked", []string{"lock"}},
-						{"Coin", "", []string{"thankyou"}},
+						{Event: "Pass", NextState: "Locked", Actions: []string{"lock"}},
+						{Event: "Coin", NextState: "", Actions: []string{"thankyou"}},
 					}},
 				},
 				Done: true,
@@ -401,10 +791,29 @@ func TestParser(t *testing.T) {
 
 func assertParserResult(t *testing.T, input string, expected FSMSyntax) {
 	t.Helper()
+	assert.Equal(t, expected, withoutPositions(parseFSM(input)))
+}
+
+func parseFSM(input string) FSMSyntax {
 	builder := NewSyntaxBuilder()
 	parser := NewParser(builder)
 	lexer := lexer.NewLexer(parser)
 
 	lexer.Lex(bytes.NewBufferString(input))
-	assert.Equal(t, expected, builder.FSM())
+	return builder.FSM()
+}
+
+func withoutPositions(fsm FSMSyntax) FSMSyntax {
+	for i := range fsm.Headers {
+		fsm.Headers[i].LineNumber, fsm.Headers[i].Position = 0, 0
+	}
+	for i := range fsm.Logic {
+		spec := &fsm.Logic[i].StateSpec
+		spec.LineNumber, spec.Position = 0, 0
+		for j := range fsm.Logic[i].SubTransitions {
+			sub := &fsm.Logic[i].SubTransitions[j]
+			sub.LineNumber, sub.Position = 0, 0
+		}
+	}
+	return fsm
 }
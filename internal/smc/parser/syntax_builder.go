@@ -5,7 +5,11 @@ import "fmt"
 type SyntaxBuilder struct {
 	fsm           FSMSyntax
 	currentName   string
+	currentLine   int
+	currentPos    int
 	currentHeader Header
+	openStates    []int
+	openRegions   []string
 }
 
 func NewSyntaxBuilder() *SyntaxBuilder {
@@ -20,25 +24,96 @@ func (b *SyntaxBuilder) SetName(name string) {
 	b.currentName = name
 }
 
+func (b *SyntaxBuilder) SetPosition(line, pos int) {
+	b.currentLine = line
+	b.currentPos = pos
+}
+
 func (b *SyntaxBuilder) NewHeader() {
-	b.fsm.Headers = append(b.fsm.Headers, Header{Name: b.currentName})
+	b.fsm.Headers = append(b.fsm.Headers, Header{
+		Name:       b.currentName,
+		LineNumber: b.currentLine,
+		Position:   b.currentPos,
+	})
 }
 
 func (b *SyntaxBuilder) AddHeaderValue() {
 	b.lastHeader().Value = b.currentName
 }
 
-func (b *SyntaxBuilder) AddNewTransition() {
-	b.fsm.Logic = append(
-		b.fsm.Logic, Transition{StateSpec: StateSpec{Name: b.currentName}},
+func (b *SyntaxBuilder) AddHeaderParameters() {
+	b.lastHeader().Parameters = []Parameter{}
+}
+
+func (b *SyntaxBuilder) AddHeaderParameter() {
+	b.lastHeader().Parameters = append(
+		b.lastHeader().Parameters, Parameter{Name: b.currentName},
 	)
 }
 
+func (b *SyntaxBuilder) AddHeaderParameterTypeName() {
+	b.AddHeaderParameterTypeToken(b.currentName)
+}
+
+func (b *SyntaxBuilder) AddHeaderParameterTypeToken(token string) {
+	parameters := b.lastHeader().Parameters
+	parameters[len(parameters)-1].Type += token
+}
+
+func (b *SyntaxBuilder) AddNewTransition() {
+	b.fsm.Logic = append(b.fsm.Logic, Transition{StateSpec: StateSpec{
+		Name:       b.currentName,
+		LineNumber: b.currentLine,
+		Position:   b.currentPos,
+	}})
+	b.openStates = []int{len(b.fsm.Logic) - 1}
+}
+
 func (b *SyntaxBuilder) AddNewAbstractTransition() {
 	b.AddNewTransition()
 	b.lastStateSpec().AbstractState = true
 }
 
+func (b *SyntaxBuilder) AddNestedState() {
+	parent := b.lastTransition()
+	sub := *b.lastSubTransition()
+	parent.SubTransitions = parent.SubTransitions[:len(parent.SubTransitions)-1]
+
+	b.fsm.Logic = append(b.fsm.Logic, Transition{StateSpec: StateSpec{
+		Name:       sub.Event,
+		Parent:     parent.StateSpec.Name,
+		LineNumber: sub.LineNumber,
+		Position:   sub.Position,
+	}})
+	b.openStates = append(b.openStates, len(b.fsm.Logic)-1)
+}
+
+func (b *SyntaxBuilder) CloseStateGroup() {
+	if len(b.openStates) > 1 {
+		b.openStates = b.openStates[:len(b.openStates)-1]
+	}
+}
+
+func (b *SyntaxBuilder) AddRegion() {
+	b.lastStateSpec().Regions = append(b.lastStateSpec().Regions, b.currentName)
+	b.openRegions = append(b.openRegions, b.currentName)
+}
+
+func (b *SyntaxBuilder) AddRegionState() {
+	b.fsm.Logic = append(b.fsm.Logic, Transition{StateSpec: StateSpec{
+		Name:       b.currentName,
+		Parent:     b.lastStateSpec().Name,
+		Region:     b.openRegions[len(b.openRegions)-1],
+		LineNumber: b.currentLine,
+		Position:   b.currentPos,
+	}})
+	b.openStates = append(b.openStates, len(b.fsm.Logic)-1)
+}
+
+func (b *SyntaxBuilder) CloseRegion() {
+	b.openRegions = b.openRegions[:len(b.openRegions)-1]
+}
+
 func (b *SyntaxBuilder) AddSuperState() {
 	b.lastStateSpec().SuperStates = append(b.lastStateSpec().SuperStates, b.currentName)
 }
@@ -54,7 +129,11 @@ func (b *SyntaxBuilder) AddExitAction() {
 func (b *SyntaxBuilder) AddEmptyEvent() {
 	b.lastTransition().SubTransitions = append(
 		b.lastTransition().SubTransitions,
-		SubTransition{Actions: []string{}},
+		SubTransition{
+			Actions:    []string{},
+			LineNumber: b.currentLine,
+			Position:   b.currentPos,
+		},
 	)
 }
 
@@ -63,10 +142,39 @@ func (b *SyntaxBuilder) AddEvent() {
 	b.lastSubTransition().Event = b.currentName
 }
 
+func (b *SyntaxBuilder) SetChoice() {
+	b.lastTransition().StateSpec.Choice = true
+}
+
+func (b *SyntaxBuilder) AddWildcardEvent() {
+	b.AddEmptyEvent()
+	b.lastSubTransition().Event = WildcardEvent
+}
+
+func (b *SyntaxBuilder) SetTimeout() {
+	b.lastSubTransition().Timeout = b.currentName
+}
+
+func (b *SyntaxBuilder) AddGuard() {
+	b.lastSubTransition().Guard = b.currentName
+}
+
 func (b *SyntaxBuilder) AddNextState() {
 	b.lastSubTransition().NextState = b.currentName
 }
 
+func (b *SyntaxBuilder) AddHistory() {
+	b.lastSubTransition().History = b.currentName
+}
+
+func (b *SyntaxBuilder) AddDeepHistory() {
+	b.lastSubTransition().History += "*"
+}
+
+func (b *SyntaxBuilder) SetExternal() {
+	b.lastSubTransition().External = true
+}
+
 func (b *SyntaxBuilder) AddAction() {
 	b.lastSubTransition().Actions = append(
 		b.lastSubTransition().Actions, b.currentName,
@@ -93,7 +201,7 @@ func (b *SyntaxBuilder) lastHeader() *Header {
 }
 
 func (b *SyntaxBuilder) lastTransition() *Transition {
-	return &b.fsm.Logic[len(b.fsm.Logic)-1]
+	return &b.fsm.Logic[b.openStates[len(b.openStates)-1]]
 }
 
 func (b *SyntaxBuilder) lastStateSpec() *StateSpec {
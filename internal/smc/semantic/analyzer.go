@@ -1,17 +1,32 @@
 package semantic
 
 import (
+	"go/token"
 	"strings"
+	"time"
 
 	"github.com/geisonbiazus/smc/internal/smc/parser"
 )
 
 type Analyzer struct {
-	semanticFSM *FSM
-	parsedFSM   parser.FSMSyntax
-	stateCache  map[string]*State
-	eventCache  map[string]bool
-	actionCache map[string]bool
+	semanticFSM         *FSM
+	parsedFSM           parser.FSMSyntax
+	stateCache          map[string]*State
+	eventCache          map[string]bool
+	actionCache         map[string]bool
+	guardCache          map[string]bool
+	actionSignatures    map[string][]Parameter
+	parentNames         map[*State]string
+	regionNames         map[*State]string
+	regionCache         map[string]*Region
+	location            location
+	stateLocations      map[*State]location
+	transitionLocations map[*State][]location
+}
+
+type location struct {
+	line int
+	pos  int
 }
 
 func NewAnalyzer() *Analyzer {
@@ -22,14 +37,40 @@ func (a *Analyzer) Analyze(parsedFSM parser.FSMSyntax) *FSM {
 	a.stateCache = map[string]*State{}
 	a.eventCache = map[string]bool{}
 	a.actionCache = map[string]bool{}
-	a.semanticFSM = &FSM{}
+	a.guardCache = map[string]bool{}
+	a.actionSignatures = map[string][]Parameter{}
+	a.parentNames = map[*State]string{}
+	a.regionNames = map[*State]string{}
+	a.regionCache = map[string]*Region{}
+	a.location = location{}
+	a.stateLocations = map[*State]location{}
+	a.transitionLocations = map[*State][]location{}
+	a.semanticFSM = &FSM{
+		EventParameters:  map[string][]Parameter{},
+		ActionParameters: map[string][]Parameter{},
+	}
 	a.parsedFSM = parsedFSM
 
 	a.addDefinedStates()
+	a.validateRegions()
 	a.setAndValidateHeaders()
 	a.setAndValidateStates()
+	a.checkFSMName()
+	a.markHierarchyUsed()
 	a.checkForUnusedStates()
+	a.checkForCyclicSuperStates()
 	a.checkForConflictingTransitions()
+	a.validateChoices()
+	a.setActionParameters()
+
+	if len(a.semanticFSM.Errors) == 0 {
+		a.checkForTransitionsOutOfRegion()
+		a.checkFinalStates()
+	}
+
+	if len(a.semanticFSM.Errors) == 0 {
+		a.analyzeReachability()
+	}
 
 	return a.semanticFSM
 }
@@ -43,12 +84,79 @@ func (a *Analyzer) addDefinedStates() {
 func (a *Analyzer) addState(spec parser.StateSpec) {
 	state := a.findOrCreateState(spec.Name)
 	state.Abstract = spec.AbstractState
+	a.setLocation(spec.LineNumber, spec.Position)
 	if _, ok := a.stateCache[spec.Name]; !ok {
 		a.stateCache[spec.Name] = state
+		a.stateLocations[state] = location{spec.LineNumber, spec.Position}
 		a.semanticFSM.States = append(a.semanticFSM.States, state)
+		a.setParent(state, spec.Parent, spec.Region)
+		state.Choice = spec.Choice
+	} else if a.parentNames[state] != spec.Parent || a.regionNames[state] != spec.Region {
+		a.addError(ErrorConflictingParentStates, state.Name)
+	} else if state.Choice != spec.Choice {
+		a.addError(ErrorInvalidChoice, state.Name)
+	}
+	a.addRegions(state, spec.Regions)
+}
+
+func (a *Analyzer) setParent(state *State, parentName, regionName string) {
+	a.parentNames[state] = parentName
+	a.regionNames[state] = regionName
+	if regionName != "" {
+		region := a.regionCache[regionName]
+		state.Region = region
+		region.States = append(region.States, state)
+		return
+	}
+
+	if parentName == "" {
+		return
+	}
+
+	parent := a.stateCache[parentName]
+	if parent.Abstract {
+		a.addError(ErrorAbstractStateWithSubStates, parent.Name)
+	}
+	state.Parent = parent
+	parent.SubStates = append(parent.SubStates, state)
+}
+
+func (a *Analyzer) addRegions(state *State, names []string) {
+	for _, name := range names {
+		if _, ok := a.regionCache[name]; ok {
+			a.addError(ErrorDuplicateRegion, name)
+			continue
+		}
+		if state.Abstract {
+			a.addError(ErrorAbstractStateWithSubStates, state.Name)
+		}
+
+		region := &Region{Name: name, Owner: state}
+		a.regionCache[name] = region
+		state.Regions = append(state.Regions, region)
+		a.semanticFSM.Regions = append(a.semanticFSM.Regions, region)
+	}
+}
+
+func (a *Analyzer) validateRegions() {
+	for _, region := range a.semanticFSM.Regions {
+		a.setStateLocation(region.Owner)
+		if len(region.States) == 0 {
+			a.addError(ErrorNoRegionInitial, region.Name)
+		}
+		if regionOf(region.Owner) != nil {
+			a.addError(ErrorNestedRegions, region.Name)
+		}
 	}
 }
 
+func regionOf(state *State) *Region {
+	for state.Parent != nil {
+		state = state.Parent
+	}
+	return state.Region
+}
+
 func (a *Analyzer) setAndValidateHeaders() {
 	a.setHeaders()
 	a.validateRequiredHeaders()
@@ -56,11 +164,21 @@ func (a *Analyzer) setAndValidateHeaders() {
 
 func (a *Analyzer) setHeaders() {
 	for _, header := range a.parsedFSM.Headers {
+		a.setLocation(header.LineNumber, header.Position)
+		a.validateHeaderParameters(header)
 		switch strings.ToLower(header.Name) {
+		case "event":
+			a.declareEvent(header)
+		case "final":
+			a.setFinalState(header.Value)
 		case "fsm":
 			a.setName(header.Value)
 		case "initial":
 			a.setInitialState(header.Value)
+		case "order":
+			a.setActionOrder(header.Value)
+		case "package":
+			a.setPackage(header.Value)
 		default:
 			a.addError(ErrorInvalidHeader, header.Name)
 		}
@@ -73,12 +191,109 @@ func (a *Analyzer) setName(value string) {
 	}
 }
 
+// clockTypes are the types generated next to the FSM type of a machine with
+// timers.
+var clockTypes = map[string]bool{
+	"Clock": true,
+	"Timer": true,
+}
+
+func (a *Analyzer) checkFSMName() {
+	if !clockTypes[strings.Title(a.semanticFSM.Name)] || !a.hasTimers() {
+		return
+	}
+
+	for _, header := range a.parsedFSM.Headers {
+		if strings.ToLower(header.Name) == "fsm" {
+			a.setLocation(header.LineNumber, header.Position)
+		}
+	}
+	a.addError(ErrorConflictingFSMName, a.semanticFSM.Name)
+}
+
+func (a *Analyzer) hasTimers() bool {
+	for _, state := range a.semanticFSM.States {
+		if len(state.Timers) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func (a *Analyzer) setInitialState(value string) {
 	if !a.isDuplicateState(a.semanticFSM.InitialState, ErrorDuplicateHeader, "Initial") {
 		a.semanticFSM.InitialState = markUsed(a.findAndValidateState(value))
 	}
 }
 
+func (a *Analyzer) setFinalState(value string) {
+	state := a.findAndValidateState(value)
+	if state.Final {
+		a.addError(ErrorDuplicateHeader, "Final:"+value)
+	}
+	state.Final = true
+}
+
+func (a *Analyzer) setActionOrder(value string) {
+	if a.isDuplicate(string(a.semanticFSM.ActionOrder), ErrorDuplicateHeader, "Order") {
+		return
+	}
+
+	switch order := ActionOrder(strings.ToLower(value)); order {
+	case ActionOrderSMC, ActionOrderUML:
+		a.semanticFSM.ActionOrder = order
+	default:
+		a.addError(ErrorInvalidActionOrder, value)
+	}
+}
+
+func (a *Analyzer) setPackage(value string) {
+	if !a.isDuplicate(a.semanticFSM.Package, ErrorDuplicateHeader, "Package") {
+		a.semanticFSM.Package = value
+	}
+}
+
+func (a *Analyzer) validateHeaderParameters(header parser.Header) {
+	if header.Parameters != nil && strings.ToLower(header.Name) != "event" {
+		a.addError(ErrorUnexpectedParameters, header.Name)
+	}
+}
+
+func (a *Analyzer) declareEvent(header parser.Header) {
+	if _, ok := a.semanticFSM.EventParameters[header.Value]; ok {
+		a.addError(ErrorDuplicateEvent, header.Value)
+		return
+	}
+	a.addEvent(header.Value)
+	a.semanticFSM.EventParameters[header.Value] = a.eventParameters(header)
+}
+
+func (a *Analyzer) eventParameters(header parser.Header) []Parameter {
+	parameters := []Parameter{}
+	index := make(map[string]bool)
+	for _, p := range header.Parameters {
+		if !token.IsIdentifier(p.Name) || generatedNames[p.Name] {
+			a.addError(ErrorInvalidParameterName, header.Value+":"+p.Name)
+		}
+		if index[p.Name] {
+			a.addError(ErrorDuplicateParameter, header.Value+":"+p.Name)
+		}
+		index[p.Name] = true
+		parameters = append(parameters, Parameter{Name: p.Name, Type: p.Type})
+	}
+	return parameters
+}
+
+// generatedNames are the receivers, parameters and locals of the generated
+// event methods, which would be redeclared by a parameter of the same name.
+var generatedNames = map[string]bool{
+	"f":       true,
+	"fsm":     true,
+	"s":       true,
+	"b":       true,
+	"handled": true,
+}
+
 func (a *Analyzer) isDuplicate(value string, errorType ErrorType, element string) bool {
 	if value != "" {
 		a.addError(errorType, element)
@@ -96,6 +311,8 @@ func (a *Analyzer) isDuplicateState(value *State, errorType ErrorType, element s
 }
 
 func (a *Analyzer) validateRequiredHeaders() {
+	a.setLocation(0, 0)
+
 	if a.semanticFSM.Name == "" {
 		a.addError(ErrorNoFSM, "FSM")
 	}
@@ -113,11 +330,16 @@ func (a *Analyzer) setAndValidateStates() {
 
 func (a *Analyzer) setAndValidateState(t parser.Transition) {
 	state := a.stateCache[t.StateSpec.Name]
+	a.setLocation(t.StateSpec.LineNumber, t.StateSpec.Position)
 	a.validateAbstractState(state, t)
 	a.setEntryActions(state, t)
 	a.setExitActions(state, t)
 	a.setSuperStates(state, t)
-	a.setTransitions(state, t)
+	if state.Choice {
+		a.setBranches(state, t)
+	} else {
+		a.setTransitions(state, t)
+	}
 }
 
 func (a *Analyzer) validateAbstractState(state *State, t parser.Transition) {
@@ -146,20 +368,43 @@ func (a *Analyzer) setExitActions(state *State, t parser.Transition) {
 
 func (a *Analyzer) setSuperStates(state *State, t parser.Transition) {
 	for _, name := range t.StateSpec.SuperStates {
-		state.SuperStates = append(
-			state.SuperStates,
-			markUsed(a.findAndValidateSuperState(name)),
-		)
+		super := markUsed(a.findAndValidateSuperState(name))
+		if super.Choice {
+			a.addError(ErrorInvalidChoice, super.Name)
+		}
+		state.SuperStates = append(state.SuperStates, super)
 	}
 }
 
 func (a *Analyzer) setTransitions(state *State, t parser.Transition) {
 	for _, sub := range t.SubTransitions {
-		if sub.Event != "" {
+		if sub.Event == "" && sub.Timeout == "" {
+			continue
+		}
+
+		a.setLocation(sub.LineNumber, sub.Position)
+		if sub.Timeout != "" {
+			sub.Event = a.addTimer(state, sub.Timeout)
+		} else if sub.Event != WildcardEvent {
 			a.addEvent(sub.Event)
-			a.setTransition(state, sub)
-			a.addActions(sub.Actions)
 		}
+		a.addGuard(sub.Guard)
+		a.setTransition(state, sub)
+		a.addActions(sub.Actions)
+	}
+}
+
+func (a *Analyzer) setBranches(state *State, t parser.Transition) {
+	spec := t.StateSpec
+	if spec.AbstractState || len(spec.SuperStates) > 0 || len(spec.EntryActions) > 0 || len(spec.ExitActions) > 0 {
+		a.addError(ErrorInvalidChoice, state.Name)
+	}
+
+	for _, sub := range t.SubTransitions {
+		a.setLocation(sub.LineNumber, sub.Position)
+		a.addGuard(sub.Guard)
+		a.setTransition(state, sub)
+		a.addActions(sub.Actions)
 	}
 }
 
@@ -170,6 +415,33 @@ func (a *Analyzer) addEvent(name string) {
 	}
 }
 
+func (a *Analyzer) addTimer(state *State, timeout string) string {
+	event := timerEvent(state.Name, timeout)
+	for _, timer := range state.Timers {
+		if timer.Event == event {
+			return event
+		}
+	}
+
+	duration, err := time.ParseDuration(timeout)
+	if err != nil || duration <= 0 {
+		a.addError(ErrorInvalidTimeout, state.Name+":"+timeout)
+	}
+	state.Timers = append(state.Timers, Timer{Event: event, Duration: duration})
+	return event
+}
+
+func timerEvent(state, timeout string) string {
+	return state + "After" + timeout
+}
+
+func (a *Analyzer) addGuard(name string) {
+	if name != "" && !a.guardCache[name] {
+		a.guardCache[name] = true
+		a.semanticFSM.Guards = append(a.semanticFSM.Guards, name)
+	}
+}
+
 func (a *Analyzer) addActions(actions []string) {
 	for _, action := range actions {
 		if !a.actionCache[action] {
@@ -182,10 +454,39 @@ func (a *Analyzer) addActions(actions []string) {
 func (a *Analyzer) setTransition(state *State, sub parser.SubTransition) {
 	transition := Transition{
 		Event:     sub.Event,
+		Guard:     sub.Guard,
 		NextState: a.resolveNextState(state, sub.NextState),
+		History:   History(sub.History),
+		External:  sub.External,
 		Actions:   sub.Actions,
 	}
+	a.validateHistory(state, transition)
+	a.validateExternal(state, transition)
 	state.Transitions = append(state.Transitions, transition)
+	a.transitionLocations[state] = append(a.transitionLocations[state], a.location)
+}
+
+func (a *Analyzer) validateHistory(state *State, t Transition) {
+	switch t.History {
+	case NoHistory:
+		return
+	case ShallowHistory, DeepHistory:
+	default:
+		a.addError(ErrorInvalidHistory, string(t.History))
+		return
+	}
+
+	if t.NextState == nil {
+		a.addError(ErrorHistoryOfSimpleState, state.Name)
+	} else if len(t.NextState.SubStates) == 0 {
+		a.addError(ErrorHistoryOfSimpleState, t.NextState.Name)
+	}
+}
+
+func (a *Analyzer) validateExternal(state *State, t Transition) {
+	if t.External && t.NextState == nil {
+		a.addError(ErrorExternalWithoutNextState, state.Name+":"+t.Name())
+	}
 }
 
 func (a *Analyzer) resolveNextState(state *State, nextStateName string) *State {
@@ -228,14 +529,80 @@ func (a *Analyzer) findOrCreateState(name string) *State {
 	return state
 }
 
+func (a *Analyzer) markHierarchyUsed() {
+	used := []*State{}
+	for _, state := range a.semanticFSM.States {
+		if state.Used {
+			used = append(used, state)
+		}
+	}
+
+	for _, state := range used {
+		for parent := state.Parent; parent != nil; parent = parent.Parent {
+			parent.Used = true
+		}
+		markDefaultsUsed(state)
+	}
+
+	for _, region := range a.semanticFSM.Regions {
+		if region.Owner.Used && len(region.States) > 0 {
+			markDefaultsUsed(markUsed(region.States[0]))
+		}
+	}
+}
+
+func markDefaultsUsed(state *State) {
+	for child := state; len(child.SubStates) > 0; child = child.SubStates[0] {
+		child.SubStates[0].Used = true
+	}
+}
+
 func (a *Analyzer) checkForUnusedStates() {
 	for _, state := range a.semanticFSM.States {
 		if !state.Used {
+			a.setStateLocation(state)
 			a.addWarning(ErrorUnusedState, state.Name)
 		}
 	}
 }
 
+func (a *Analyzer) checkForCyclicSuperStates() {
+	visited := map[*State]bool{}
+	for _, state := range a.semanticFSM.States {
+		a.findSuperStateCycles(state, []*State{}, visited)
+	}
+}
+
+func (a *Analyzer) findSuperStateCycles(state *State, path []*State, visited map[*State]bool) {
+	for i, s := range path {
+		if s == state {
+			a.addCyclicSuperStatesError(path[i:])
+			return
+		}
+	}
+
+	if visited[state] {
+		return
+	}
+	visited[state] = true
+
+	path = append(path, state)
+	for _, super := range state.SuperStates {
+		a.findSuperStateCycles(super, path, visited)
+	}
+}
+
+func (a *Analyzer) addCyclicSuperStatesError(cycle []*State) {
+	names := []string{}
+	for _, s := range cycle {
+		names = append(names, s.Name)
+	}
+	names = append(names, cycle[0].Name)
+
+	a.setStateLocation(cycle[0])
+	a.addError(ErrorCyclicSuperStates, strings.Join(names, "->"))
+}
+
 func (a *Analyzer) checkForConflictingTransitions() {
 	for _, state := range a.semanticFSM.States {
 		a.checkForDuplicateTransition(state)
@@ -245,38 +612,154 @@ func (a *Analyzer) checkForConflictingTransitions() {
 
 func (a *Analyzer) checkForDuplicateTransition(state *State) {
 	index := make(map[string]bool)
-	for _, transition := range state.Transitions {
-		if index[transition.Event] {
-			a.addError(ErrorDuplicateTransition, state.Name+":"+transition.Event)
+	unguarded := make(map[string]bool)
+	for i, transition := range state.Transitions {
+		a.location = a.transitionLocations[state][i]
+		if index[transition.Name()] {
+			a.addError(ErrorDuplicateTransition, state.Name+":"+transition.Name())
+		} else if unguarded[transition.Event] {
+			a.addWarning(ErrorUnreachableAlternative, state.Name+":"+transition.Name())
 		}
-		index[transition.Event] = true
+		index[transition.Name()] = true
+		unguarded[transition.Event] = unguarded[transition.Event] || transition.Guard == ""
 	}
 }
 
 func (a *Analyzer) checkForConflictingTransitionsOnSuperStates(state *State) {
 	index := make(map[string]bool)
 	for _, super := range state.SuperStates {
-		for _, transition := range super.Transitions {
-			if index[transition.Event] {
-				a.addError(ErrorConflictingSuperStates, state.Name+":"+transition.Event)
+		for _, event := range transitionEvents(super) {
+			if index[event] {
+				a.setStateLocation(state)
+				a.addError(ErrorConflictingSuperStates, state.Name+":"+event)
+			}
+			index[event] = true
+		}
+	}
+}
+
+func (a *Analyzer) checkForTransitionsOutOfRegion() {
+	for _, state := range a.semanticFSM.States {
+		if state.Abstract {
+			continue
+		}
+		for _, t := range effectiveTransitions(state) {
+			next := t.transition.NextState
+			if next != nil && regionOf(next) != regionOf(state) {
+				a.location = a.transitionLocations[t.owner][t.index]
+				a.addError(ErrorTransitionOutOfRegion, state.Name+":"+t.transition.Event)
 			}
+		}
+	}
+}
+
+func (a *Analyzer) checkFinalStates() {
+	for _, state := range a.semanticFSM.States {
+		if !state.Final {
+			continue
+		}
+
+		a.setStateLocation(state)
+		if state.Abstract || len(state.SubStates) > 0 || len(state.Regions) > 0 {
+			a.addError(ErrorInvalidFinalState, state.Name)
+		} else if hasTransitions(state) {
+			a.addError(ErrorFinalStateWithTransitions, state.Name)
+		}
+	}
+}
+
+func hasTransitions(state *State) bool {
+	if len(state.Transitions) > 0 {
+		return true
+	}
+	for _, super := range state.SuperStates {
+		if hasTransitions(super) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *Analyzer) setActionParameters() {
+	for _, state := range a.semanticFSM.States {
+		a.setStateLocation(state)
+		a.setActionSignatures(state.EntryActions, nil)
+		a.setActionSignatures(state.ExitActions, nil)
+		for i, transition := range state.Transitions {
+			a.location = a.transitionLocations[state][i]
+			a.setActionSignatures(transition.Actions, a.semanticFSM.EventParameters[transition.Event])
+		}
+	}
+}
+
+func (a *Analyzer) setActionSignatures(actions []string, parameters []Parameter) {
+	for _, action := range actions {
+		a.setActionSignature(action, parameters)
+	}
+}
+
+func (a *Analyzer) setActionSignature(action string, parameters []Parameter) {
+	signature, ok := a.actionSignatures[action]
+	if !ok {
+		a.actionSignatures[action] = parameters
+		if len(parameters) > 0 {
+			a.semanticFSM.ActionParameters[action] = parameters
+		}
+		return
+	}
+
+	if !sameTypes(signature, parameters) {
+		a.addError(ErrorConflictingActionParameters, action)
+	}
+}
+
+func sameTypes(a, b []Parameter) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Type != b[i].Type {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *Analyzer) setLocation(line, pos int) {
+	a.location = location{line, pos}
+}
+
+func (a *Analyzer) setStateLocation(state *State) {
+	a.location = a.stateLocations[state]
+}
+
+func transitionEvents(state *State) []string {
+	events := []string{}
+	index := make(map[string]bool)
+	for _, transition := range state.Transitions {
+		if !index[transition.Event] {
 			index[transition.Event] = true
+			events = append(events, transition.Event)
 		}
 	}
+	return events
 }
 
 func (a *Analyzer) addError(errorType ErrorType, element string) {
-	a.semanticFSM.Errors = append(
-		a.semanticFSM.Errors,
-		Error{Type: errorType, Element: element},
-	)
+	a.semanticFSM.Errors = append(a.semanticFSM.Errors, a.newError(errorType, element))
 }
 
 func (a *Analyzer) addWarning(errorType ErrorType, element string) {
-	a.semanticFSM.Warnings = append(
-		a.semanticFSM.Warnings,
-		Error{Type: errorType, Element: element},
-	)
+	a.semanticFSM.Warnings = append(a.semanticFSM.Warnings, a.newError(errorType, element))
+}
+
+func (a *Analyzer) newError(errorType ErrorType, element string) Error {
+	return Error{
+		Type:       errorType,
+		Element:    element,
+		LineNumber: a.location.line,
+		Position:   a.location.pos,
+	}
 }
 
 func markUsed(s *State) *State {
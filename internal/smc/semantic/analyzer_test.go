@@ -3,6 +3,7 @@ package semantic
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	"github.com/geisonbiazus/smc/internal/smc/lexer"
 	"github.com/geisonbiazus/smc/internal/smc/parser"
@@ -15,65 +16,93 @@ func TestAnalyzer(t *testing.T) {
 			semanticFSM := analizeSemantically("FSM:b Initial:c {}")
 			assert.Equal(t, "b", semanticFSM.Name)
 			assert.Equal(t, "c", semanticFSM.InitialState.Name)
+			assert.Equal(t, "", semanticFSM.Package)
+
+			semanticFSM = analizeSemantically("FSM:b Initial:c Package:d {}")
+			assert.Equal(t, "d", semanticFSM.Package)
 		})
 
 		t.Run("Errors", func(t *testing.T) {
 			assertContainsError(t,
 				analizeSemantically("{}"),
-				Error{ErrorNoFSM, "FSM"},
+				Error{Type: ErrorNoFSM, Element: "FSM"},
 			)
 
 			assertNotContainsError(t,
 				analizeSemantically("FSM:a{}"),
-				Error{ErrorNoFSM, "FSM"},
+				Error{Type: ErrorNoFSM, Element: "FSM"},
 			)
 
 			assertContainsError(t,
 				analizeSemantically("{}"),
-				Error{ErrorNoInitial, "Initial"},
+				Error{Type: ErrorNoInitial, Element: "Initial"},
 			)
 
 			assertNotContainsError(t,
 				analizeSemantically("Initial:a{}"),
-				Error{ErrorNoFSM, "Initial"},
+				Error{Type: ErrorNoFSM, Element: "Initial"},
 			)
 
 			assertContainsError(t,
 				analizeSemantically("{}"),
-				Error{ErrorNoFSM, "FSM"}, Error{ErrorNoInitial, "Initial"},
+				Error{Type: ErrorNoFSM, Element: "FSM"}, Error{Type: ErrorNoInitial, Element: "Initial"},
 			)
 
 			assertContainsError(t,
 				analizeSemantically("a:b {}"),
-				Error{ErrorInvalidHeader, "a"},
+				Error{Type: ErrorInvalidHeader, Element: "a"},
 			)
 
 			assertNotContainsError(t,
 				analizeSemantically("FSM:b Initial:c {}"),
-				Error{ErrorNoFSM, "FSM"},
-				Error{ErrorNoInitial, "Initial"},
+				Error{Type: ErrorNoFSM, Element: "FSM"},
+				Error{Type: ErrorNoInitial, Element: "Initial"},
 			)
 
 			assertNotContainsError(t,
 				analizeSemantically("fsm:b initial:c {}"),
-				Error{ErrorNoFSM, "FSM"},
-				Error{ErrorNoInitial, "Initial"},
+				Error{Type: ErrorNoFSM, Element: "FSM"},
+				Error{Type: ErrorNoInitial, Element: "Initial"},
 			)
 
 			assertContainsError(t,
 				analizeSemantically("FSM:a fsm:b {}"),
-				Error{ErrorDuplicateHeader, "FSM"},
+				Error{Type: ErrorDuplicateHeader, Element: "FSM"},
 			)
 
 			assertContainsError(t,
 				analizeSemantically("Initial:b Initial:c {}"),
-				Error{ErrorDuplicateHeader, "Initial"},
+				Error{Type: ErrorDuplicateHeader, Element: "Initial"},
+			)
+
+			assertContainsError(t,
+				analizeSemantically("Package:b package:c {}"),
+				Error{Type: ErrorDuplicateHeader, Element: "Package"},
 			)
 
 			assertNotContainsError(t,
 				analizeSemantically("FSM:b Initial:c {}"),
-				Error{ErrorDuplicateHeader, "FSM"},
-				Error{ErrorDuplicateHeader, "Initial"},
+				Error{Type: ErrorDuplicateHeader, Element: "FSM"},
+				Error{Type: ErrorDuplicateHeader, Element: "Initial"},
+			)
+
+			assertNotContainsError(t,
+				analizeSemantically("Package:b {}"),
+				Error{Type: ErrorInvalidHeader, Element: "Package"},
+			)
+
+			assert.Equal(t, ActionOrderUML, analizeSemantically("Order:UML {}").ActionOrder)
+			assert.Equal(t, ActionOrderSMC, analizeSemantically("Order:smc {}").ActionOrder)
+			assert.Equal(t, ActionOrder(""), analizeSemantically("FSM:a {}").ActionOrder)
+
+			assertContainsError(t,
+				analizeSemantically("Order:uml order:smc {}"),
+				Error{Type: ErrorDuplicateHeader, Element: "Order"},
+			)
+
+			assertContainsError(t,
+				analizeSemantically("Order:scxml {}"),
+				Error{Type: ErrorInvalidActionOrder, Element: "scxml"},
 			)
 		})
 	})
@@ -174,6 +203,20 @@ func TestAnalyzer(t *testing.T) {
 				assert.Equal(t, []string{"c", "e"}, semanticFSM.Actions)
 			})
 
+			t.Run("Guarded transitions", func(t *testing.T) {
+				semanticFSM := analizeSemantically("{a { b [g] a c \n b [h] - - \n b - d }}")
+				stateA := &State{Name: "a", Used: true}
+				stateA.Transitions = []Transition{
+					{Event: "b", Guard: "g", NextState: stateA, Actions: []string{"c"}},
+					{Event: "b", Guard: "h", NextState: nil, Actions: []string{}},
+					{Event: "b", NextState: nil, Actions: []string{"d"}},
+				}
+				assert.Equal(t, stateA, findState(semanticFSM, "a"))
+				assert.Equal(t, []string{"b"}, semanticFSM.Events)
+				assert.Equal(t, []string{"g", "h"}, semanticFSM.Guards)
+				assert.Equal(t, []string{"c", "d"}, semanticFSM.Actions)
+			})
+
 			t.Run("Undefined states are not added", func(t *testing.T) {
 				semanticFSM := analizeSemantically("Initial: a{b c d -}")
 				assert.Len(t, semanticFSM.States, 1)
@@ -218,87 +261,87 @@ func TestAnalyzer(t *testing.T) {
 		t.Run("Errors", func(t *testing.T) {
 			assertContainsError(t,
 				analizeSemantically("Initial: a{}"),
-				Error{ErrorUndefinedState, "a"},
+				Error{Type: ErrorUndefinedState, Element: "a"},
 			)
 
 			assertNotContainsError(t,
 				analizeSemantically("Initial: a{a - - -}"),
-				Error{ErrorUndefinedState, "a"},
+				Error{Type: ErrorUndefinedState, Element: "a"},
 			)
 
 			assertContainsError(t,
 				analizeSemantically("{a b c -}"),
-				Error{ErrorUndefinedState, "c"},
+				Error{Type: ErrorUndefinedState, Element: "c"},
 			)
 
 			assertNotContainsError(t,
 				analizeSemantically("{a b c - c - - -}"),
-				Error{ErrorUndefinedState, "c"},
+				Error{Type: ErrorUndefinedState, Element: "c"},
 			)
 
 			assertContainsError(t,
 				analizeSemantically("{a >b - - - \n a >c - - -}"),
-				Error{ErrorEntryActionsAlreadyDefined, "a"},
+				Error{Type: ErrorEntryActionsAlreadyDefined, Element: "a"},
 			)
 
 			assertNotContainsError(t,
 				analizeSemantically("{a >b - - - \n b >c - - -}"),
-				Error{ErrorEntryActionsAlreadyDefined, "a"},
+				Error{Type: ErrorEntryActionsAlreadyDefined, Element: "a"},
 			)
 
 			assertContainsError(t,
 				analizeSemantically("{a <b - - - \n a <c - - -}"),
-				Error{ErrorExitActionsAlreadyDefined, "a"},
+				Error{Type: ErrorExitActionsAlreadyDefined, Element: "a"},
 			)
 
 			assertNotContainsError(t,
 				analizeSemantically("{a <b - - - \n b <c - - -}"),
-				Error{ErrorExitActionsAlreadyDefined, "a"},
+				Error{Type: ErrorExitActionsAlreadyDefined, Element: "a"},
 			)
 
 			assertContainsError(t,
 				analizeSemantically("{(a) - - - \n a - - -}"),
-				Error{ErrorAbstractStateRedefinedAsNonAbstract, "a"},
+				Error{Type: ErrorAbstractStateRedefinedAsNonAbstract, Element: "a"},
 			)
 
 			assertContainsError(t,
 				analizeSemantically("{a - - - \n (a) - - -}"),
-				Error{ErrorAbstractStateRedefinedAsNonAbstract, "a"},
+				Error{Type: ErrorAbstractStateRedefinedAsNonAbstract, Element: "a"},
 			)
 
 			assertNotContainsError(t,
 				analizeSemantically("{(a) - - - \n (a) - - -}"),
-				Error{ErrorAbstractStateRedefinedAsNonAbstract, "a"},
+				Error{Type: ErrorAbstractStateRedefinedAsNonAbstract, Element: "a"},
 			)
 
 			assertContainsError(t,
 				analizeSemantically("{a:b - - -}"),
-				Error{ErrorUndefinedSuperState, "b"},
+				Error{Type: ErrorUndefinedSuperState, Element: "b"},
 			)
 
 			assertNotContainsError(t,
 				analizeSemantically("{a:b - - - b - - - }"),
-				Error{ErrorUndefinedSuperState, "b"},
+				Error{Type: ErrorUndefinedSuperState, Element: "b"},
 			)
 
 			assertContainsError(t,
 				analizeSemantically("{a b c - (c) - - -}"),
-				Error{ErrorAbstractStateUsedAsNextState, "c"},
+				Error{Type: ErrorAbstractStateUsedAsNextState, Element: "c"},
 			)
 
 			assertNotContainsError(t,
 				analizeSemantically("{a b c - c - - -}"),
-				Error{ErrorAbstractStateUsedAsNextState, "c"},
+				Error{Type: ErrorAbstractStateUsedAsNextState, Element: "c"},
 			)
 
 			assertContainsError(t,
 				analizeSemantically("{a { b c - \n b d - }"),
-				Error{ErrorDuplicateTransition, "a:b"},
+				Error{Type: ErrorDuplicateTransition, Element: "a:b"},
 			)
 
 			assertNotContainsError(t,
 				analizeSemantically("{a { b c - \n e d - }"),
-				Error{ErrorDuplicateTransition, "a:b"},
+				Error{Type: ErrorDuplicateTransition, Element: "a:b"},
 			)
 
 			assertContainsError(t,
@@ -309,7 +352,7 @@ func TestAnalyzer(t *testing.T) {
 						c:a:b
 					}
 					`),
-				Error{ErrorConflictingSuperStates, "c:e1"},
+				Error{Type: ErrorConflictingSuperStates, Element: "c:e1"},
 			)
 
 			assertNotContainsError(t,
@@ -320,10 +363,61 @@ func TestAnalyzer(t *testing.T) {
 						c:a:b
 					}
 					`),
-				Error{ErrorConflictingSuperStates, "c:e1"},
-				Error{ErrorConflictingSuperStates, "c:e2"},
+				Error{Type: ErrorConflictingSuperStates, Element: "c:e1"},
+				Error{Type: ErrorConflictingSuperStates, Element: "c:e2"},
 			)
 
+			t.Run("Guarded transitions", func(t *testing.T) {
+				assertNotContainsError(t,
+					analizeSemantically("{a { b [g] c - \n b [h] d - \n b - - } c - - - d - - -}"),
+					Error{Type: ErrorDuplicateTransition, Element: "a:b"},
+					Error{Type: ErrorDuplicateTransition, Element: "a:b[g]"},
+					Error{Type: ErrorDuplicateTransition, Element: "a:b[h]"},
+				)
+
+				assertContainsError(t,
+					analizeSemantically("{a { b [g] - - \n b [g] - - }}"),
+					Error{Type: ErrorDuplicateTransition, Element: "a:b[g]"},
+				)
+
+				assertContainsError(t,
+					analizeSemantically("{a { b [g] - - \n b - - \n b - - }}"),
+					Error{Type: ErrorDuplicateTransition, Element: "a:b"},
+				)
+
+				assertNotContainsError(t,
+					analizeSemantically("{(s) { b [g] - - \n b - - } \n (t) c - - \n a:s:t - - - }"),
+					Error{Type: ErrorConflictingSuperStates, Element: "a:b"},
+				)
+			})
+
+			t.Run("Cyclic super states", func(t *testing.T) {
+				assertContainsError(t,
+					analizeSemantically("{a:a - - -}"),
+					Error{Type: ErrorCyclicSuperStates, Element: "a->a"},
+				)
+
+				assertContainsError(t,
+					analizeSemantically("{a:b - - - \n b:a - - -}"),
+					Error{Type: ErrorCyclicSuperStates, Element: "a->b->a"},
+				)
+
+				assertContainsError(t,
+					analizeSemantically("{(a):b - - - \n (b):c - - - \n (c):a - - - \n d:a - - -}"),
+					Error{Type: ErrorCyclicSuperStates, Element: "a->b->c->a"},
+				)
+
+				assert.Empty(t, errorsOfType(
+					analizeSemantically("{(a) - - - \n (b):a - - - \n (c):a - - - \n d:b:c - - -}"),
+					ErrorCyclicSuperStates,
+				))
+
+				assert.Len(t, errorsOfType(
+					analizeSemantically("{a:b - - - \n b:a - - - \n c:a - - -}"),
+					ErrorCyclicSuperStates,
+				), 1)
+			})
+
 			t.Run("State can be overriden", func(t *testing.T) {
 				assertNotContainsError(t,
 					analizeSemantically(`
@@ -332,7 +426,7 @@ func TestAnalyzer(t *testing.T) {
 							b:a e1 d -
 						}
 						`),
-					Error{ErrorConflictingSuperStates, "c:e1"},
+					Error{Type: ErrorConflictingSuperStates, Element: "c:e1"},
 				)
 			})
 		})
@@ -340,27 +434,130 @@ func TestAnalyzer(t *testing.T) {
 		t.Run("Warnings", func(t *testing.T) {
 			assertContainsWarning(t,
 				analizeSemantically("{a b c d}"),
-				Error{ErrorUnusedState, "a"},
+				Error{Type: ErrorUnusedState, Element: "a"},
 			)
 
 			assertNotContainsWarning(t,
 				analizeSemantically("{a b a d}"),
-				Error{ErrorUnusedState, "a"},
+				Error{Type: ErrorUnusedState, Element: "a"},
 			)
 
 			assertNotContainsWarning(t,
 				analizeSemantically("{a b - d}"),
-				Error{ErrorUnusedState, "a"},
+				Error{Type: ErrorUnusedState, Element: "a"},
 			)
 
 			assertNotContainsWarning(t,
 				analizeSemantically("Initial: a{a b c d}"),
-				Error{ErrorUnusedState, "a"},
+				Error{Type: ErrorUnusedState, Element: "a"},
 			)
 
 			assertNotContainsWarning(t,
 				analizeSemantically("{a b c d c:a - - -}"),
-				Error{ErrorUnusedState, "a"},
+				Error{Type: ErrorUnusedState, Element: "a"},
+			)
+		})
+
+		t.Run("Warning types", func(t *testing.T) {
+			assert.True(t, IsWarningType(ErrorUnusedState))
+			assert.True(t, IsWarningType(ErrorTrapStates))
+			assert.False(t, IsWarningType(ErrorUndefinedState))
+			assert.False(t, IsWarningType(ErrorType("UNUSED_SATE")))
+		})
+
+		t.Run("Unreachable guarded alternatives", func(t *testing.T) {
+			semanticFSM := analizeSemantically("{a { b [g] - - \n b - - \n b [h] - - \n c - - }}")
+			assertContainsWarning(t, semanticFSM,
+				Error{Type: ErrorUnreachableAlternative, Element: "a:b[h]"},
+			)
+			assertNotContainsWarning(t, semanticFSM,
+				Error{Type: ErrorUnreachableAlternative, Element: "a:b[g]"},
+				Error{Type: ErrorUnreachableAlternative, Element: "a:b"},
+				Error{Type: ErrorUnreachableAlternative, Element: "a:c"},
+			)
+		})
+
+		t.Run("Guarded alternatives fall back to super states", func(t *testing.T) {
+			assertValid(t, "FSM:f Initial:a { (s) e b - \n a:s e [g] a - \n b e a - }")
+		})
+
+		t.Run("Reachability", func(t *testing.T) {
+			semanticFSM := analizeSemantically("FSM:f Initial:a { a e a - \n b e c - \n c e b - }")
+			assertContainsWarning(t, semanticFSM,
+				Error{Type: ErrorUnreachableState, Element: "b"},
+				Error{Type: ErrorUnreachableState, Element: "c"},
+			)
+			assertNotContainsWarning(t, semanticFSM,
+				Error{Type: ErrorUnreachableState, Element: "a"},
+				Error{Type: ErrorUnreachableTransition, Element: "b:e"},
+				Error{Type: ErrorUnreachableTransition, Element: "c:e"},
+			)
+
+			assertValid(t, "FSM:f Initial:a { (base) e b - \n a:base - - - \n b f a - }")
+
+			semanticFSM = analizeSemantically(
+				"FSM:f Initial:a { (base) e b - \n a:base e a - \n b:base f a - }",
+			)
+			assertContainsWarning(t, semanticFSM,
+				Error{Type: ErrorUnreachableState, Element: "b"},
+				Error{Type: ErrorUnreachableTransition, Element: "base:e"},
+			)
+			assertNotContainsWarning(t, semanticFSM,
+				Error{Type: ErrorUnreachableTransition, Element: "a:e"},
+				Error{Type: ErrorUnreachableTransition, Element: "b:f"},
+			)
+
+			semanticFSM = analizeSemantically("FSM:f Initial:a { a e a - \n c e a - }")
+			assertContainsWarning(t, semanticFSM, Error{Type: ErrorUnusedState, Element: "c"})
+			assertNotContainsWarning(t, semanticFSM,
+				Error{Type: ErrorUnreachableState, Element: "c"},
+				Error{Type: ErrorUnreachableTransition, Element: "c:e"},
+			)
+
+			assertNotContainsWarning(t,
+				analizeSemantically("Initial:a { a e a - \n c e c - }"),
+				Error{Type: ErrorUnreachableState, Element: "c"},
+			)
+		})
+
+		t.Run("Dead ends and traps", func(t *testing.T) {
+			semanticFSM := analizeSemantically("FSM:f Initial:a { a { e b - \n f c - } \n b - - - \n c e c - }")
+			assertContainsWarning(t, semanticFSM,
+				Error{Type: ErrorDeadEndState, Element: "b"},
+				Error{Type: ErrorDeadEndState, Element: "c"},
+			)
+			assertNotContainsWarning(t, semanticFSM,
+				Error{Type: ErrorDeadEndState, Element: "a"},
+			)
+
+			assertContainsWarning(t,
+				analizeSemantically("FSM:f Initial:a { a e b - \n b { e c - \n f d - } \n c e d - \n d e c - }"),
+				Error{Type: ErrorTrapStates, Element: "c,d"},
+			)
+
+			assertNotContainsWarning(t,
+				analizeSemantically("FSM:f Initial:a { (base) e a - \n a f b - \n b:base - - - }"),
+				Error{Type: ErrorDeadEndState, Element: "b"},
+			)
+
+			assert.Empty(t, analizeSemantically("FSM:f Initial:a { a e a - }").Warnings)
+			assert.Empty(t, analizeSemantically("FSM:f Initial:a { a e b - \n b e a - }").Warnings)
+		})
+
+		t.Run("Locations", func(t *testing.T) {
+			semanticFSM := analizeSemantically("FSM: f\nInitial: a\nfoo: bar {\n  a {\n    b c -\n    b a -\n  }\n  d b a -\n}")
+
+			assert.Contains(t, semanticFSM.Errors,
+				Error{Type: ErrorInvalidHeader, Element: "foo", LineNumber: 3, Position: 1},
+			)
+			assert.Contains(t, semanticFSM.Errors,
+				Error{Type: ErrorUndefinedState, Element: "c", LineNumber: 5, Position: 5},
+			)
+			assert.Contains(t, semanticFSM.Errors,
+				Error{Type: ErrorDuplicateTransition, Element: "a:b", LineNumber: 6, Position: 5},
+			)
+			assert.Contains(t, semanticFSM.Warnings,
+				Error{Type: ErrorUnusedState, Element: "d", LineNumber: 8, Position: 3},
 			)
 		})
 
@@ -455,6 +652,455 @@ func TestAnalyzer(t *testing.T) {
 			`)
 		})
 	})
+
+	t.Run("Event parameters", func(t *testing.T) {
+		t.Run("Values", func(t *testing.T) {
+			semanticFSM := analizeSemantically(`
+				Event: Coin(amount int, note string)
+				Event: Reset()
+				Initial: a
+				{
+					a >enter {
+						Coin - unlock
+						Pass - lock
+						Reset - clear
+					}
+				}`)
+
+			assert.Equal(t, []string{"Coin", "Reset", "Pass"}, semanticFSM.Events)
+			assert.Equal(t, map[string][]Parameter{
+				"Coin":  {{Name: "amount", Type: "int"}, {Name: "note", Type: "string"}},
+				"Reset": {},
+			}, semanticFSM.EventParameters)
+			assert.Equal(t, map[string][]Parameter{
+				"unlock": {{Name: "amount", Type: "int"}, {Name: "note", Type: "string"}},
+			}, semanticFSM.ActionParameters)
+
+			semanticFSM = analizeSemantically(`
+				Event: Coin(amount int)
+				Event: Refund(value int)
+				Initial: a
+				{
+					a {
+						Coin - {unlock log}
+						Refund - log
+					}
+				}`)
+
+			assert.Empty(t, errorsOfType(semanticFSM, ErrorConflictingActionParameters))
+			assert.Equal(t, map[string][]Parameter{
+				"unlock": {{Name: "amount", Type: "int"}},
+				"log":    {{Name: "amount", Type: "int"}},
+			}, semanticFSM.ActionParameters)
+		})
+
+		t.Run("Errors", func(t *testing.T) {
+			assertContainsError(t,
+				analizeSemantically("Event: a Event: a(b int) {}"),
+				Error{Type: ErrorDuplicateEvent, Element: "a"},
+			)
+
+			assertContainsError(t,
+				analizeSemantically("Event: a(b int, b string) {}"),
+				Error{Type: ErrorDuplicateParameter, Element: "a:b"},
+			)
+
+			assert.Contains(t,
+				analizeSemantically("FSM: f\nEvent: a(type int, b_1 int) {}").Errors,
+				Error{Type: ErrorInvalidParameterName, Element: "a:type", LineNumber: 2, Position: 1},
+			)
+			assertNotContainsError(t,
+				analizeSemantically("Event: a(type int, b_1 int) {}"),
+				Error{Type: ErrorInvalidParameterName, Element: "a:b_1"},
+			)
+			assertContainsError(t,
+				analizeSemantically("FSM: timer Initial: a { a { after 1s a - } }"),
+				Error{Type: ErrorConflictingFSMName, Element: "timer"},
+			)
+			assertNotContainsError(t,
+				analizeSemantically("FSM: Timer Initial: a { a e a - }"),
+				Error{Type: ErrorConflictingFSMName, Element: "Timer"},
+			)
+
+			for _, name := range []string{"f", "fsm", "s", "b", "handled"} {
+				assertContainsError(t,
+					analizeSemantically("Event: a("+name+" int) {}"),
+					Error{Type: ErrorInvalidParameterName, Element: "a:" + name},
+				)
+			}
+
+			assertContainsError(t,
+				analizeSemantically("FSM: a(b int) {}"),
+				Error{Type: ErrorUnexpectedParameters, Element: "FSM"},
+			)
+
+			assertContainsError(t,
+				analizeSemantically("Event: e(b int) Initial: a { a { e - c \n f - c } }"),
+				Error{Type: ErrorConflictingActionParameters, Element: "c"},
+			)
+
+			assertContainsError(t,
+				analizeSemantically("Event: e(b int) Initial: a { a >c e - c }"),
+				Error{Type: ErrorConflictingActionParameters, Element: "c"},
+			)
+
+			assertContainsError(t,
+				analizeSemantically("Event: e(b int) Event: f(b string) Initial: a { a { e - c \n f - c } }"),
+				Error{Type: ErrorConflictingActionParameters, Element: "c"},
+			)
+
+			assertNotContainsError(t,
+				analizeSemantically("Event: e(b int) Event: f(c int) Initial: a { a { e - c \n f - c } }"),
+				Error{Type: ErrorConflictingActionParameters, Element: "c"},
+			)
+		})
+	})
+
+	t.Run("Nested states", func(t *testing.T) {
+		t.Run("Values", func(t *testing.T) {
+			semanticFSM := analizeSemantically("FSM:f Initial:a { a { b { e c - } \n c { e b - } } }")
+			stateA := findState(semanticFSM, "a")
+			stateB := findState(semanticFSM, "b")
+			stateC := findState(semanticFSM, "c")
+
+			assert.Equal(t, []*State{stateA, stateB, stateC}, semanticFSM.States)
+			assert.Equal(t, []*State{stateB, stateC}, stateA.SubStates)
+			assert.Nil(t, stateA.Parent)
+			assert.Equal(t, stateA, stateB.Parent)
+			assert.Equal(t, stateA, stateC.Parent)
+			assert.Equal(t, stateC, stateB.Transitions[0].NextState)
+			assert.Empty(t, semanticFSM.Errors)
+			assert.Empty(t, semanticFSM.Warnings)
+		})
+
+		t.Run("Errors", func(t *testing.T) {
+			assertContainsError(t,
+				analizeSemantically("{ a { c { } } \n b { c { } } }"),
+				Error{Type: ErrorConflictingParentStates, Element: "c"},
+			)
+
+			assertContainsError(t,
+				analizeSemantically("{ a { c { } } \n c - - - }"),
+				Error{Type: ErrorConflictingParentStates, Element: "c"},
+			)
+
+			assertNotContainsError(t,
+				analizeSemantically("{ a { c { e - - } } \n a { c { f - - } } }"),
+				Error{Type: ErrorConflictingParentStates, Element: "c"},
+			)
+
+			assertContainsError(t,
+				analizeSemantically("{ (a) { c { } } }"),
+				Error{Type: ErrorAbstractStateWithSubStates, Element: "a"},
+			)
+		})
+
+		t.Run("Usage", func(t *testing.T) {
+			semanticFSM := analizeSemantically("FSM:f Initial:a { a { b { } \n c { } } \n d { e { } } }")
+			assertNotContainsWarning(t, semanticFSM,
+				Error{Type: ErrorUnusedState, Element: "a"},
+				Error{Type: ErrorUnusedState, Element: "b"},
+			)
+			assertContainsWarning(t, semanticFSM,
+				Error{Type: ErrorUnusedState, Element: "c"},
+				Error{Type: ErrorUnusedState, Element: "d"},
+				Error{Type: ErrorUnusedState, Element: "e"},
+			)
+
+			assertNotContainsWarning(t,
+				analizeSemantically("FSM:f Initial:a { a { e c - \n b { } \n c { } } }"),
+				Error{Type: ErrorUnusedState, Element: "b"},
+			)
+		})
+
+		t.Run("Reachability", func(t *testing.T) {
+			assertValid(t, "FSM:f Initial:a { a { e d - \n b { f c - } \n c { f b - } } \n d e a - }")
+
+			semanticFSM := analizeSemantically("FSM:f Initial:a { a { e b - \n b { e c - } \n c { e c - \n f d - } } \n d - - - }")
+			assertContainsWarning(t, semanticFSM,
+				Error{Type: ErrorUnreachableTransition, Element: "a:e"},
+				Error{Type: ErrorDeadEndState, Element: "d"},
+			)
+			assertNotContainsWarning(t, semanticFSM,
+				Error{Type: ErrorDeadEndState, Element: "a"},
+				Error{Type: ErrorUnreachableState, Element: "d"},
+			)
+
+			assertContainsWarning(t,
+				analizeSemantically("FSM:f Initial:a { a { b { e a - } } \n c { d { e c - } } }"),
+				Error{Type: ErrorUnreachableState, Element: "c"},
+			)
+		})
+
+		t.Run("History", func(t *testing.T) {
+			semanticFSM := analizeSemantically("FSM:f Initial:a { a { b { e c - } \n c { e d - } } \n d { f a(H) - \n g a(H*) - } }")
+			assert.Equal(t, ShallowHistory, findState(semanticFSM, "d").Transitions[0].History)
+			assert.Equal(t, DeepHistory, findState(semanticFSM, "d").Transitions[1].History)
+			assert.Empty(t, semanticFSM.Errors)
+			assert.Empty(t, semanticFSM.Warnings)
+
+			assertContainsError(t,
+				analizeSemantically("{ a { b { } } \n c e a(X) - }"),
+				Error{Type: ErrorInvalidHistory, Element: "X"},
+			)
+
+			assertContainsError(t,
+				analizeSemantically("{ a { b { } } \n c e c(H) - }"),
+				Error{Type: ErrorHistoryOfSimpleState, Element: "c"},
+			)
+
+			assertContainsError(t,
+				analizeSemantically("{ a { b { } } \n c e -(H*) - }"),
+				Error{Type: ErrorHistoryOfSimpleState, Element: "c"},
+			)
+		})
+	})
+
+	t.Run("Regions", func(t *testing.T) {
+		t.Run("Values", func(t *testing.T) {
+			semanticFSM := analizeSemantically(
+				"FSM:f Initial:a { a { [r] { b { e c - } \n c { e b - } } \n [s] { d { f d - } } \n g h - } \n h g a - }",
+			)
+			stateA := findState(semanticFSM, "a")
+			regionR := &Region{Name: "r", Owner: stateA, States: []*State{findState(semanticFSM, "b"), findState(semanticFSM, "c")}}
+			regionS := &Region{Name: "s", Owner: stateA, States: []*State{findState(semanticFSM, "d")}}
+
+			assert.Equal(t, []*Region{regionR, regionS}, semanticFSM.Regions)
+			assert.Equal(t, []*Region{regionR, regionS}, stateA.Regions)
+			assert.Equal(t, regionR, findState(semanticFSM, "b").Region)
+			assert.Nil(t, findState(semanticFSM, "b").Parent)
+			assert.Empty(t, stateA.SubStates)
+			assert.Empty(t, semanticFSM.Errors)
+			assert.Empty(t, semanticFSM.Warnings)
+		})
+
+		t.Run("Errors", func(t *testing.T) {
+			assertContainsError(t,
+				analizeSemantically("{ a { [r] { b { } } \n [r] { c { } } } }"),
+				Error{Type: ErrorDuplicateRegion, Element: "r"},
+			)
+
+			assertContainsError(t,
+				analizeSemantically("{ a { [r] { } } }"),
+				Error{Type: ErrorNoRegionInitial, Element: "r"},
+			)
+
+			assertContainsError(t,
+				analizeSemantically("{ a { [r] { b { [s] { c { } } } } } }"),
+				Error{Type: ErrorNestedRegions, Element: "s"},
+			)
+
+			assertContainsError(t,
+				analizeSemantically("{ (a) { [r] { b { } } } }"),
+				Error{Type: ErrorAbstractStateWithSubStates, Element: "a"},
+			)
+
+			assertContainsError(t,
+				analizeSemantically("{ a { [r] { b { } } } \n b { } }"),
+				Error{Type: ErrorConflictingParentStates, Element: "b"},
+			)
+
+			assertContainsError(t,
+				analizeSemantically("FSM:f Initial:a { a { [r] { b { e c - } } } \n c - - - }"),
+				Error{Type: ErrorTransitionOutOfRegion, Element: "b:e"},
+			)
+
+			assertContainsError(t,
+				analizeSemantically("FSM:f Initial:a { a { [r] { b { } } \n e b - } }"),
+				Error{Type: ErrorTransitionOutOfRegion, Element: "a:e"},
+			)
+
+			assertContainsError(t,
+				analizeSemantically("FSM:f Initial:a { (x) e c - \n a { [r] { b : x { } } } \n c - - - }"),
+				Error{Type: ErrorTransitionOutOfRegion, Element: "b:e"},
+			)
+		})
+
+		t.Run("Usage and reachability", func(t *testing.T) {
+			semanticFSM := analizeSemantically(
+				"FSM:f Initial:a { a { [r] { b { e c - } \n c { } \n d { } } } \n x { [s] { y { } } } }",
+			)
+			assertNotContainsWarning(t, semanticFSM,
+				Error{Type: ErrorUnusedState, Element: "b"},
+				Error{Type: ErrorUnusedState, Element: "c"},
+				Error{Type: ErrorDeadEndState, Element: "a"},
+			)
+			assertContainsWarning(t, semanticFSM,
+				Error{Type: ErrorUnusedState, Element: "d"},
+				Error{Type: ErrorUnusedState, Element: "x"},
+				Error{Type: ErrorUnusedState, Element: "y"},
+				Error{Type: ErrorDeadEndState, Element: "c"},
+			)
+
+			assertContainsWarning(t,
+				analizeSemantically("FSM:f Initial:a { a { [r] { b { e b - } \n c { e b - } \n d { f c - } } } }"),
+				Error{Type: ErrorUnreachableState, Element: "c"},
+			)
+		})
+	})
+
+	t.Run("Final states", func(t *testing.T) {
+		semanticFSM := analizeSemantically("FSM:f Initial:a Final:c { a { e b - \n f c - } \n b e c - \n c - - - }")
+		assert.True(t, findState(semanticFSM, "c").Final)
+		assert.False(t, findState(semanticFSM, "a").Final)
+		assert.Empty(t, semanticFSM.Errors)
+		assert.Empty(t, semanticFSM.Warnings)
+
+		assertContainsWarning(t,
+			analizeSemantically("FSM:f Initial:a { a { e b - \n f c - } \n b e c - \n c - - - }"),
+			Error{Type: ErrorDeadEndState, Element: "c"},
+		)
+
+		assertContainsError(t,
+			analizeSemantically("FSM:f Initial:a Final:b { a e b - \n b e a - }"),
+			Error{Type: ErrorFinalStateWithTransitions, Element: "b"},
+		)
+
+		assertContainsError(t,
+			analizeSemantically("FSM:f Initial:a Final:b { (x) e a - \n a e b - \n b : x - - - }"),
+			Error{Type: ErrorFinalStateWithTransitions, Element: "b"},
+		)
+
+		assertContainsError(t,
+			analizeSemantically("FSM:f Initial:a Final:b { a e b - \n b { c { } } }"),
+			Error{Type: ErrorInvalidFinalState, Element: "b"},
+		)
+
+		assertContainsError(t,
+			analizeSemantically("FSM:f Initial:a Final:b Final:b { a e b - \n b - - - }"),
+			Error{Type: ErrorDuplicateHeader, Element: "Final:b"},
+		)
+
+		assertContainsError(t,
+			analizeSemantically("FSM:f Initial:a Final:x { a - - - }"),
+			Error{Type: ErrorUndefinedState, Element: "x"},
+		)
+	})
+
+	t.Run("Timeouts", func(t *testing.T) {
+		semanticFSM := analizeSemantically(
+			"FSM:f Initial:a { a { after 30s b x \n after 1m [g] b - \n after 1m - - \n e b - } \n b after 500ms a - }",
+		)
+		stateA := findState(semanticFSM, "a")
+		assert.Equal(t, []Timer{
+			{Event: "aAfter30s", Duration: 30 * time.Second},
+			{Event: "aAfter1m", Duration: time.Minute},
+		}, stateA.Timers)
+		assert.Equal(t, "aAfter30s", stateA.Transitions[0].Event)
+		assert.Equal(t, "aAfter1m", stateA.Transitions[2].Event)
+		assert.Equal(t, []Timer{{Event: "bAfter500ms", Duration: 500 * time.Millisecond}}, findState(semanticFSM, "b").Timers)
+		assert.Equal(t, []string{"e"}, semanticFSM.Events)
+		assert.Empty(t, semanticFSM.Errors)
+		assert.Empty(t, semanticFSM.Warnings)
+
+		assertContainsError(t,
+			analizeSemantically("FSM:f Initial:a { a after 5x a - }"),
+			Error{Type: ErrorInvalidTimeout, Element: "a:5x"},
+		)
+
+		assertContainsError(t,
+			analizeSemantically("FSM:f Initial:a { a after 0s a - }"),
+			Error{Type: ErrorInvalidTimeout, Element: "a:0s"},
+		)
+
+		assertContainsError(t,
+			analizeSemantically("FSM:f Initial:a { a { after 5s a - \n after 5s a - } }"),
+			Error{Type: ErrorDuplicateTransition, Element: "a:aAfter5s"},
+		)
+
+		assertNotContainsWarning(t,
+			analizeSemantically("FSM:f Initial:a { a e b - \n b after 5s a - }"),
+			Error{Type: ErrorDeadEndState, Element: "b"},
+		)
+	})
+
+	t.Run("Choices", func(t *testing.T) {
+		semanticFSM := analizeSemantically(
+			"FSM:f Initial:a { a e c x \n c? { [g] b y \n a - } \n b e a - }",
+		)
+		choice := findState(semanticFSM, "c")
+		assert.True(t, choice.Choice)
+		assert.Equal(t, []Transition{
+			{Guard: "g", NextState: findState(semanticFSM, "b"), Actions: []string{"y"}},
+			{NextState: findState(semanticFSM, "a"), Actions: []string{}},
+		}, choice.Transitions)
+		assert.Equal(t, []string{"e"}, semanticFSM.Events)
+		assert.Equal(t, []string{"g"}, semanticFSM.Guards)
+		assert.Equal(t, []string{"x", "y"}, semanticFSM.Actions)
+		assert.Empty(t, semanticFSM.Errors)
+		assert.Empty(t, semanticFSM.Warnings)
+
+		assertContainsError(t,
+			analizeSemantically("FSM:f Initial:a { a e c - \n c? { [g] a - } }"),
+			Error{Type: ErrorChoiceWithoutDefault, Element: "c"},
+		)
+
+		assertContainsError(t,
+			analizeSemantically("FSM:f Initial:c { a e c - \n c? { a - } }"),
+			Error{Type: ErrorInvalidChoice, Element: "c"},
+		)
+
+		assertContainsError(t,
+			analizeSemantically("FSM:f Initial:a { a : c e a - \n c? { a - } }"),
+			Error{Type: ErrorInvalidChoice, Element: "c"},
+		)
+
+		assertContainsError(t,
+			analizeSemantically("FSM:f Initial:a { a e c - \n c >x ? { a - } }"),
+			Error{Type: ErrorInvalidChoice, Element: "c"},
+		)
+
+		assertContainsError(t,
+			analizeSemantically("FSM:f Initial:a { a e c - \n c? { a - } \n c f a - }"),
+			Error{Type: ErrorInvalidChoice, Element: "c"},
+		)
+
+		assertContainsError(t,
+			analizeSemantically("FSM:f Initial:a { a e c - \n c? { [g] d - \n a - } \n d? { c - } }"),
+			Error{Type: ErrorCyclicChoices, Element: "c"},
+			Error{Type: ErrorCyclicChoices, Element: "d"},
+		)
+
+		assertContainsWarning(t,
+			analizeSemantically("FSM:f Initial:a { a e a - \n c? { a - } }"),
+			Error{Type: ErrorUnusedState, Element: "c"},
+		)
+	})
+
+	t.Run("External transitions", func(t *testing.T) {
+		semanticFSM := analizeSemantically("FSM:f Initial:a { a { e a! x \n f a - } }")
+		stateA := findState(semanticFSM, "a")
+		assert.Equal(t, []Transition{
+			{Event: "e", NextState: stateA, External: true, Actions: []string{"x"}},
+			{Event: "f", NextState: stateA, Actions: []string{}},
+		}, stateA.Transitions)
+		assert.Empty(t, semanticFSM.Errors)
+
+		assertContainsError(t,
+			analizeSemantically("FSM:f Initial:a { a e -! - }"),
+			Error{Type: ErrorExternalWithoutNextState, Element: "a:e"},
+		)
+	})
+
+	t.Run("Wildcard events", func(t *testing.T) {
+		semanticFSM := analizeSemantically("FSM:f Initial:a { a { e b - \n * c x } \n b e a - \n c e a - }")
+		assert.Equal(t, []string{"e"}, semanticFSM.Events)
+		assert.Equal(t, Transition{
+			Event: WildcardEvent, NextState: findState(semanticFSM, "c"), Actions: []string{"x"},
+		}, findState(semanticFSM, "a").Transitions[1])
+		assert.Empty(t, semanticFSM.Errors)
+		assert.Empty(t, semanticFSM.Warnings)
+
+		assertContainsError(t,
+			analizeSemantically("FSM:f Initial:a { a { * b - \n * b - } \n b e a - }"),
+			Error{Type: ErrorDuplicateTransition, Element: "a:*"},
+		)
+
+		assertNotContainsWarning(t,
+			analizeSemantically("FSM:f Initial:a { (base) * c - \n a : base e b - \n b e a - \n c e a - }"),
+			Error{Type: ErrorUnreachableState, Element: "c"},
+		)
+	})
 }
 
 func analizeSemantically(input string) *FSM {
@@ -471,29 +1117,47 @@ func analizeSemantically(input string) *FSM {
 func assertContainsError(t *testing.T, semanticFSM *FSM, errors ...Error) {
 	t.Helper()
 	for _, err := range errors {
-		assert.Contains(t, semanticFSM.Errors, err)
+		assert.Contains(t, withoutLocations(semanticFSM.Errors), err)
 	}
 }
 
 func assertNotContainsError(t *testing.T, semanticFSM *FSM, errors ...Error) {
 	t.Helper()
 	for _, err := range errors {
-		assert.NotContains(t, semanticFSM.Errors, err)
+		assert.NotContains(t, withoutLocations(semanticFSM.Errors), err)
 	}
 }
 
 func assertContainsWarning(t *testing.T, semanticFSM *FSM, errors ...Error) {
 	t.Helper()
 	for _, err := range errors {
-		assert.Contains(t, semanticFSM.Warnings, err)
+		assert.Contains(t, withoutLocations(semanticFSM.Warnings), err)
 	}
 }
 
 func assertNotContainsWarning(t *testing.T, semanticFSM *FSM, errors ...Error) {
 	t.Helper()
 	for _, err := range errors {
-		assert.NotContains(t, semanticFSM.Warnings, err)
+		assert.NotContains(t, withoutLocations(semanticFSM.Warnings), err)
+	}
+}
+
+func errorsOfType(semanticFSM *FSM, errorType ErrorType) []Error {
+	result := []Error{}
+	for _, err := range semanticFSM.Errors {
+		if err.Type == errorType {
+			result = append(result, err)
+		}
+	}
+	return result
+}
+
+func withoutLocations(errors []Error) []Error {
+	result := []Error{}
+	for _, err := range errors {
+		result = append(result, Error{Type: err.Type, Element: err.Element})
 	}
+	return result
 }
 
 func assertValid(t *testing.T, input string) {
@@ -0,0 +1,48 @@
+package semantic
+
+func (a *Analyzer) validateChoices() {
+	if initial := a.semanticFSM.InitialState; initial != nil && initial.Choice {
+		a.setStateLocation(initial)
+		a.addError(ErrorInvalidChoice, initial.Name)
+	}
+
+	for _, state := range a.semanticFSM.States {
+		if !state.Choice {
+			continue
+		}
+
+		a.setStateLocation(state)
+		if !hasDefaultBranch(state) {
+			a.addError(ErrorChoiceWithoutDefault, state.Name)
+		}
+		if leadsToChoice(state, state, map[*State]bool{}) {
+			a.addError(ErrorCyclicChoices, state.Name)
+		}
+	}
+}
+
+func hasDefaultBranch(choice *State) bool {
+	for _, t := range choice.Transitions {
+		if t.Guard == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func leadsToChoice(from, choice *State, visited map[*State]bool) bool {
+	for _, t := range from.Transitions {
+		next := t.NextState
+		if next == nil || !next.Choice || visited[next] {
+			continue
+		}
+		if next == choice {
+			return true
+		}
+		visited[next] = true
+		if leadsToChoice(next, choice, visited) {
+			return true
+		}
+	}
+	return false
+}
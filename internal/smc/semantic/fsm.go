@@ -1,42 +1,111 @@
 package semantic
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type FSM struct {
-	Errors       []Error
-	Warnings     []Error
-	Name         string
-	InitialState *State
-	States       []*State
-	Events       []string
-	Actions      []string
+	Errors           []Error
+	Warnings         []Error
+	Name             string
+	Package          string
+	ActionOrder      ActionOrder
+	InitialState     *State
+	States           []*State
+	Regions          []*Region
+	Events           []string
+	Actions          []string
+	Guards           []string
+	EventParameters  map[string][]Parameter
+	ActionParameters map[string][]Parameter
+}
+
+type ActionOrder string
+
+const (
+	ActionOrderSMC ActionOrder = "smc"
+	ActionOrderUML ActionOrder = "uml"
+)
+
+type Parameter struct {
+	Name string
+	Type string
 }
 
 type State struct {
 	Name         string
 	Abstract     bool
+	Choice       bool
+	Final        bool
 	Used         bool
+	Parent       *State
+	SubStates    []*State
+	Region       *Region
+	Regions      []*Region
 	SuperStates  []*State
 	EntryActions []string
 	ExitActions  []string
+	Timers       []Timer
 	Transitions  []Transition
 }
 
+type Timer struct {
+	Event    string
+	Duration time.Duration
+}
+
+type Region struct {
+	Name   string
+	Owner  *State
+	States []*State
+}
+
 type Transition struct {
 	Event     string
+	Guard     string
 	NextState *State
+	History   History
+	External  bool
 	Actions   []string
 }
 
+const WildcardEvent = "*"
+
+type History string
+
+const (
+	NoHistory      History = ""
+	ShallowHistory History = "H"
+	DeepHistory    History = "H*"
+)
+
+func (t Transition) Name() string {
+	if t.Guard != "" {
+		return t.Event + "[" + t.Guard + "]"
+	}
+	return t.Event
+}
+
 type Error struct {
-	Type    ErrorType
-	Element string
+	Type       ErrorType
+	Element    string
+	LineNumber int
+	Position   int
 }
 
 func (e Error) String() string {
 	return fmt.Sprintf("Type: %s - Element: %s", e.Type, e.Element)
 }
 
+func (e Error) Location() (line, pos int) {
+	return e.LineNumber, e.Position
+}
+
+func (e Error) Message() string {
+	return fmt.Sprintf("%s: %s", e.Type, e.Element)
+}
+
 type ErrorType string
 
 const (
@@ -54,4 +123,50 @@ const (
 	ErrorUnusedState                         ErrorType = "UNUSED_STATE"
 	ErrorDuplicateTransition                 ErrorType = "DUPLICATE_TRANSITION"
 	ErrorConflictingSuperStates              ErrorType = "CONFLICTING_SUPER_STATES"
+	ErrorCyclicSuperStates                   ErrorType = "CYCLIC_SUPER_STATES"
+	ErrorUnreachableState                    ErrorType = "UNREACHABLE_STATE"
+	ErrorUnreachableTransition               ErrorType = "UNREACHABLE_TRANSITION"
+	ErrorDeadEndState                        ErrorType = "DEAD_END_STATE"
+	ErrorTrapStates                          ErrorType = "TRAP_STATES"
+	ErrorUnreachableAlternative              ErrorType = "UNREACHABLE_ALTERNATIVE"
+	ErrorDuplicateEvent                      ErrorType = "DUPLICATE_EVENT"
+	ErrorDuplicateParameter                  ErrorType = "DUPLICATE_PARAMETER"
+	ErrorInvalidParameterName                ErrorType = "INVALID_PARAMETER_NAME"
+	ErrorUnexpectedParameters                ErrorType = "UNEXPECTED_PARAMETERS"
+	ErrorConflictingActionParameters         ErrorType = "CONFLICTING_ACTION_PARAMETERS"
+	ErrorConflictingParentStates             ErrorType = "CONFLICTING_PARENT_STATES"
+	ErrorConflictingFSMName                  ErrorType = "CONFLICTING_FSM_NAME"
+	ErrorAbstractStateWithSubStates          ErrorType = "ABSTRACT_STATE_WITH_SUBSTATES"
+	ErrorInvalidHistory                      ErrorType = "INVALID_HISTORY"
+	ErrorHistoryOfSimpleState                ErrorType = "HISTORY_OF_SIMPLE_STATE"
+	ErrorDuplicateRegion                     ErrorType = "DUPLICATE_REGION"
+	ErrorNoRegionInitial                     ErrorType = "NO_REGION_INITIAL"
+	ErrorNestedRegions                       ErrorType = "NESTED_REGIONS"
+	ErrorTransitionOutOfRegion               ErrorType = "TRANSITION_OUT_OF_REGION"
+	ErrorInvalidFinalState                   ErrorType = "INVALID_FINAL_STATE"
+	ErrorFinalStateWithTransitions           ErrorType = "FINAL_STATE_WITH_TRANSITIONS"
+	ErrorInvalidTimeout                      ErrorType = "INVALID_TIMEOUT"
+	ErrorExternalWithoutNextState            ErrorType = "EXTERNAL_WITHOUT_NEXT_STATE"
+	ErrorInvalidActionOrder                  ErrorType = "INVALID_ACTION_ORDER"
+	ErrorInvalidChoice                       ErrorType = "INVALID_CHOICE"
+	ErrorChoiceWithoutDefault                ErrorType = "CHOICE_WITHOUT_DEFAULT"
+	ErrorCyclicChoices                       ErrorType = "CYCLIC_CHOICES"
 )
+
+var WarningTypes = []ErrorType{
+	ErrorUnusedState,
+	ErrorUnreachableState,
+	ErrorUnreachableTransition,
+	ErrorUnreachableAlternative,
+	ErrorDeadEndState,
+	ErrorTrapStates,
+}
+
+func IsWarningType(errorType ErrorType) bool {
+	for _, warning := range WarningTypes {
+		if warning == errorType {
+			return true
+		}
+	}
+	return false
+}
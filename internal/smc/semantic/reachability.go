@@ -0,0 +1,174 @@
+package semantic
+
+type effectiveTransition struct {
+	owner      *State
+	index      int
+	transition Transition
+}
+
+func (a *Analyzer) analyzeReachability() {
+	reachable := findReachableStates(a.semanticFSM.InitialState)
+	machines := []map[*State]bool{reachable}
+	active := withAncestors(reachable)
+
+	for _, region := range a.semanticFSM.Regions {
+		if active[region.Owner] {
+			machines = append(machines, findReachableStates(region.States[0]))
+		}
+	}
+
+	reachable = union(machines)
+	active = withAncestors(reachable)
+	a.checkForUnreachableStates(active)
+	a.checkForUnreachableTransitions(reachable, active)
+	for _, machine := range machines {
+		a.checkForTrapStates(machine)
+	}
+}
+
+func findReachableStates(initialState *State) map[*State]bool {
+	initial := defaultLeaf(initialState)
+	reachable := map[*State]bool{initial: true}
+	pending := []*State{initial}
+
+	for len(pending) > 0 {
+		state := pending[0]
+		pending = pending[1:]
+
+		for _, next := range nextStates(state) {
+			if !reachable[next] {
+				reachable[next] = true
+				pending = append(pending, next)
+			}
+		}
+	}
+	return reachable
+}
+
+func union(sets []map[*State]bool) map[*State]bool {
+	result := map[*State]bool{}
+	for _, set := range sets {
+		for state := range set {
+			result[state] = true
+		}
+	}
+	return result
+}
+
+func withAncestors(states map[*State]bool) map[*State]bool {
+	result := map[*State]bool{}
+	for state := range states {
+		for s := state; s != nil; s = s.Parent {
+			result[s] = true
+		}
+	}
+	return result
+}
+
+func (a *Analyzer) checkForUnreachableStates(active map[*State]bool) {
+	for _, state := range a.semanticFSM.States {
+		if !state.Abstract && state.Used && !active[state] {
+			a.setStateLocation(state)
+			a.addWarning(ErrorUnreachableState, state.Name)
+		}
+	}
+}
+
+func (a *Analyzer) checkForUnreachableTransitions(reachable, active map[*State]bool) {
+	fired := map[*State]map[int]bool{}
+	for state := range reachable {
+		for _, t := range effectiveTransitions(state) {
+			if fired[t.owner] == nil {
+				fired[t.owner] = map[int]bool{}
+			}
+			fired[t.owner][t.index] = true
+		}
+	}
+
+	for _, state := range a.semanticFSM.States {
+		if !state.Abstract && !active[state] {
+			continue
+		}
+		for i, t := range state.Transitions {
+			if !fired[state][i] {
+				a.location = a.transitionLocations[state][i]
+				a.addWarning(ErrorUnreachableTransition, state.Name+":"+t.Event)
+			}
+		}
+	}
+}
+
+func nextStates(state *State) []*State {
+	states := []*State{}
+	for _, t := range effectiveTransitions(state) {
+		if t.transition.NextState != nil {
+			states = append(states, targetLeaves(t.transition)...)
+		} else {
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
+func effectiveTransitions(state *State) []effectiveTransition {
+	return collectEffectiveTransitions(state, []effectiveTransition{}, map[string]bool{})
+}
+
+func collectEffectiveTransitions(
+	state *State, result []effectiveTransition, handledEvents map[string]bool,
+) []effectiveTransition {
+	handledHere := map[string]bool{}
+	for i, t := range state.Transitions {
+		if !handledEvents[t.Event] {
+			result = append(result, effectiveTransition{owner: state, index: i, transition: t})
+			handledHere[t.Event] = handledHere[t.Event] || t.Guard == ""
+		}
+	}
+
+	for event := range handledHere {
+		handledEvents[event] = handledEvents[event] || handledHere[event]
+	}
+
+	for _, super := range state.SuperStates {
+		result = collectEffectiveTransitions(super, result, handledEvents)
+	}
+
+	if state.Parent != nil {
+		result = collectEffectiveTransitions(state.Parent, result, handledEvents)
+	}
+	return result
+}
+
+func targetLeaves(t Transition) []*State {
+	switch t.History {
+	case ShallowHistory:
+		states := []*State{}
+		for _, child := range t.NextState.SubStates {
+			states = append(states, defaultLeaf(child))
+		}
+		return states
+	case DeepHistory:
+		return leaves(t.NextState)
+	default:
+		return []*State{defaultLeaf(t.NextState)}
+	}
+}
+
+func leaves(state *State) []*State {
+	if len(state.SubStates) == 0 {
+		return []*State{state}
+	}
+
+	result := []*State{}
+	for _, child := range state.SubStates {
+		result = append(result, leaves(child)...)
+	}
+	return result
+}
+
+func defaultLeaf(state *State) *State {
+	for len(state.SubStates) > 0 {
+		state = state.SubStates[0]
+	}
+	return state
+}
@@ -0,0 +1,146 @@
+package semantic
+
+import "strings"
+
+func (a *Analyzer) checkForTrapStates(reachable map[*State]bool) {
+	for _, component := range a.closedComponents(reachable) {
+		if len(component) == len(reachable) || isFinal(component) {
+			continue
+		}
+
+		a.setStateLocation(component[0])
+		if len(component) == 1 {
+			a.addWarning(ErrorDeadEndState, component[0].Name)
+		} else {
+			a.addWarning(ErrorTrapStates, stateNames(component))
+		}
+	}
+}
+
+func (a *Analyzer) closedComponents(reachable map[*State]bool) [][]*State {
+	finder := newComponentFinder()
+	for _, state := range a.semanticFSM.States {
+		if reachable[state] && !finder.visited(state) {
+			finder.visit(state)
+		}
+	}
+
+	closed := [][]*State{}
+	for _, component := range finder.components {
+		if isClosed(component) {
+			closed = append(closed, a.sortByDefinition(component))
+		}
+	}
+	return closed
+}
+
+func (a *Analyzer) sortByDefinition(component []*State) []*State {
+	members := map[*State]bool{}
+	for _, s := range component {
+		members[s] = true
+	}
+
+	sorted := []*State{}
+	for _, s := range a.semanticFSM.States {
+		if members[s] {
+			sorted = append(sorted, s)
+		}
+	}
+	return sorted
+}
+
+func isFinal(component []*State) bool {
+	return len(component) == 1 && component[0].Final
+}
+
+func isClosed(component []*State) bool {
+	members := map[*State]bool{}
+	for _, s := range component {
+		members[s] = true
+	}
+
+	for _, s := range component {
+		for _, next := range nextStates(s) {
+			if !members[next] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func stateNames(states []*State) string {
+	names := []string{}
+	for _, s := range states {
+		names = append(names, s.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+// componentFinder implements Tarjan's strongly connected components algorithm.
+type componentFinder struct {
+	index      map[*State]int
+	lowLink    map[*State]int
+	onStack    map[*State]bool
+	stack      []*State
+	counter    int
+	components [][]*State
+}
+
+func newComponentFinder() *componentFinder {
+	return &componentFinder{
+		index:   map[*State]int{},
+		lowLink: map[*State]int{},
+		onStack: map[*State]bool{},
+	}
+}
+
+func (f *componentFinder) visited(state *State) bool {
+	_, ok := f.index[state]
+	return ok
+}
+
+func (f *componentFinder) visit(state *State) {
+	f.index[state] = f.counter
+	f.lowLink[state] = f.counter
+	f.counter++
+	f.push(state)
+
+	for _, next := range nextStates(state) {
+		if !f.visited(next) {
+			f.visit(next)
+			f.lowLink[state] = lowest(f.lowLink[state], f.lowLink[next])
+		} else if f.onStack[next] {
+			f.lowLink[state] = lowest(f.lowLink[state], f.index[next])
+		}
+	}
+
+	if f.lowLink[state] == f.index[state] {
+		f.components = append(f.components, f.popComponent(state))
+	}
+}
+
+func (f *componentFinder) push(state *State) {
+	f.stack = append(f.stack, state)
+	f.onStack[state] = true
+}
+
+func (f *componentFinder) popComponent(root *State) []*State {
+	component := []*State{}
+	for {
+		state := f.stack[len(f.stack)-1]
+		f.stack = f.stack[:len(f.stack)-1]
+		f.onStack[state] = false
+		component = append(component, state)
+		if state == root {
+			return component
+		}
+	}
+}
+
+func lowest(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -3,10 +3,15 @@ package smc
 import (
 	"errors"
 	"fmt"
+	"go/format"
 	"io"
 
+	"github.com/geisonbiazus/smc/internal/smc/coverage"
+	"github.com/geisonbiazus/smc/internal/smc/generator/nestedswitch"
 	"github.com/geisonbiazus/smc/internal/smc/generator/statepattern"
+	"github.com/geisonbiazus/smc/internal/smc/generator/table"
 	"github.com/geisonbiazus/smc/internal/smc/implementers/golang"
+	"github.com/geisonbiazus/smc/internal/smc/implementers/java"
 	"github.com/geisonbiazus/smc/internal/smc/lexer"
 	"github.com/geisonbiazus/smc/internal/smc/optimizer"
 	"github.com/geisonbiazus/smc/internal/smc/parser"
@@ -15,17 +20,54 @@ import (
 
 type Error interface {
 	String() string
+	Location() (line, pos int)
+	Message() string
 }
 
+func FormatDiagnostic(fileName, severity string, err Error) string {
+	line, pos := err.Location()
+	location := fileName
+	if line > 0 {
+		location = fmt.Sprintf("%s:%d:%d", fileName, line, pos)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, severity, err.Message())
+}
+
+type Generator string
+
+const (
+	GeneratorStatePattern Generator = "statepattern"
+	GeneratorTable        Generator = "table"
+	GeneratorSwitch       Generator = "switch"
+)
+
+type Language string
+
+const (
+	LanguageGo   Language = "go"
+	LanguageJava Language = "java"
+)
+
 type Compiler struct {
-	input          io.Reader
-	output         io.Writer
-	Errors         []Error
-	parsedFSM      parser.FSMSyntax
-	semanticFSM    *semantic.FSM
-	optimizedFSM   *optimizer.FSM
-	node           statepattern.Node
-	implementedFSM string
+	input              io.Reader
+	output             io.Writer
+	SourceFile         string
+	Package            string
+	DefaultPackage     string
+	Generator          Generator
+	Language           Language
+	TemplateDir        string
+	WarningsAsErrors   bool
+	SuppressedWarnings []semantic.ErrorType
+	Errors             []Error
+	Warnings           []Error
+	parsedFSM          parser.FSMSyntax
+	semanticFSM        *semantic.FSM
+	optimizedFSM       *optimizer.FSM
+	node               statepattern.Node
+	table              *table.Table
+	switchNode         nestedswitch.Node
+	implementedFSM     string
 }
 
 func NewCompiler(input io.Reader, output io.Writer) *Compiler {
@@ -37,17 +79,46 @@ func NewCompiler(input io.Reader, output io.Writer) *Compiler {
 
 func (c *Compiler) Compile() error {
 	if !c.parseFSM() {
-		return CompileError
+		return SyntaxError
 	}
 
 	if !c.analyzeFSM() {
-		return CompileError
+		return SemanticError
 	}
 
 	c.optimizeFSM()
+	if !c.checkLanguage() {
+		return SemanticError
+	}
+
 	c.generateFSM()
-	c.implementFSM()
-	c.writeImplementation()
+	if !c.implementFSM() {
+		return TemplateError
+	}
+	if !c.formatFSM() {
+		return FormatError
+	}
+	return c.writeImplementation()
+}
+
+func (c *Compiler) Coverage(format coverage.Format, failOnUnhandled bool) error {
+	if !c.parseFSM() {
+		return SyntaxError
+	}
+
+	if !c.analyzeFSM() {
+		return SemanticError
+	}
+
+	c.optimizeFSM()
+	matrix := coverage.NewBuilder().Build(c.optimizedFSM)
+	if err := matrix.Write(c.output, format); err != nil {
+		return err
+	}
+
+	if failOnUnhandled && matrix.HasUnhandled() {
+		return UnhandledEventsError
+	}
 	return nil
 }
 
@@ -72,7 +143,8 @@ func (c *Compiler) analyzeFSM() bool {
 	analyzer := semantic.NewAnalyzer()
 	c.semanticFSM = analyzer.Analyze(c.parsedFSM)
 	c.collectSemanticErrors()
-	return len(c.Errors) == 0
+	c.collectSemanticWarnings()
+	return len(c.Errors) == 0 && !(c.WarningsAsErrors && len(c.Warnings) > 0)
 }
 
 func (c *Compiler) collectSemanticErrors() {
@@ -81,23 +153,156 @@ func (c *Compiler) collectSemanticErrors() {
 	}
 }
 
+func (c *Compiler) collectSemanticWarnings() {
+	for _, warning := range c.semanticFSM.Warnings {
+		if !c.isSuppressed(warning.Type) {
+			c.Warnings = append(c.Warnings, warning)
+		}
+	}
+}
+
+func (c *Compiler) isSuppressed(errorType semantic.ErrorType) bool {
+	for _, suppressed := range c.SuppressedWarnings {
+		if suppressed == errorType {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *Compiler) optimizeFSM() {
 	opt := optimizer.New()
 	c.optimizedFSM = opt.Optimize(c.semanticFSM)
 }
 
+func (c *Compiler) checkLanguage() bool {
+	if c.Language != LanguageJava {
+		return true
+	}
+	for _, err := range java.Check(c.optimizedFSM) {
+		c.Errors = append(c.Errors, err)
+	}
+	return len(c.Errors) == 0
+}
+
 func (c *Compiler) generateFSM() {
-	generator := statepattern.NewNodeGenerator()
-	c.node = generator.Generate(c.optimizedFSM)
+	switch {
+	case c.Language == LanguageJava:
+		c.node = statepattern.NewNodeGenerator().Generate(c.optimizedFSM)
+	case c.Generator == GeneratorTable:
+		c.table = table.NewGenerator().Generate(c.optimizedFSM)
+	case c.Generator == GeneratorSwitch:
+		c.switchNode = nestedswitch.NewNodeGenerator().Generate(c.optimizedFSM)
+	default:
+		c.node = statepattern.NewNodeGenerator().Generate(c.optimizedFSM)
+	}
 }
 
-func (c *Compiler) implementFSM() {
-	impl := golang.NewImplementer("fsm")
-	c.implementedFSM = impl.Implement(c.node)
+func (c *Compiler) implementFSM() bool {
+	switch {
+	case c.Language == LanguageJava:
+		c.implementedFSM = java.NewImplementer(c.packageName()).Implement(c.node)
+	case c.Generator == GeneratorTable:
+		c.implementedFSM = golang.NewTableImplementer(c.packageName()).Implement(c.table)
+	case c.Generator == GeneratorSwitch:
+		c.implementedFSM = golang.NewSwitchImplementer(c.packageName()).Implement(c.switchNode)
+	case c.TemplateDir != "":
+		return c.implementTemplates()
+	default:
+		c.implementedFSM = golang.NewImplementer(c.packageName()).Implement(c.node)
+	}
+	return true
+}
+
+func (c *Compiler) implementTemplates() bool {
+	implementer, err := golang.NewTemplateImplementer(c.packageName(), c.TemplateDir)
+	if err == nil {
+		c.implementedFSM, err = implementer.Implement(c.node)
+	}
+	if err != nil {
+		c.Errors = append(c.Errors, TemplatingError{Err: err})
+		return false
+	}
+	return true
 }
 
-func (c *Compiler) writeImplementation() {
-	fmt.Fprint(c.output, c.implementedFSM)
+func (c *Compiler) formatFSM() bool {
+	if c.Language == LanguageJava {
+		c.implementedFSM = c.generatedHeader() + c.implementedFSM
+		return true
+	}
+
+	formatted, err := format.Source([]byte(c.generatedHeader() + c.implementedFSM))
+	if err != nil {
+		c.Errors = append(c.Errors, FormattingError{Err: err})
+		return false
+	}
+
+	c.implementedFSM = string(formatted)
+	return true
 }
 
-var CompileError = errors.New("Compile error")
+func (c *Compiler) generatedHeader() string {
+	if c.SourceFile == "" {
+		return "// Code generated by smc. DO NOT EDIT.\n\n"
+	}
+	return "// Code generated by smc from " + c.SourceFile + ". DO NOT EDIT.\n\n"
+}
+
+func (c *Compiler) packageName() string {
+	if c.Package != "" {
+		return c.Package
+	}
+	if c.optimizedFSM.Package != "" {
+		return c.optimizedFSM.Package
+	}
+	if c.DefaultPackage != "" {
+		return c.DefaultPackage
+	}
+	return "fsm"
+}
+
+func (c *Compiler) writeImplementation() error {
+	_, err := fmt.Fprint(c.output, c.implementedFSM)
+	return err
+}
+
+type FormattingError struct {
+	Err error
+}
+
+func (e FormattingError) String() string {
+	return "Type: FORMAT - Message: " + e.Err.Error()
+}
+
+func (e FormattingError) Location() (line, pos int) {
+	return 0, 0
+}
+
+func (e FormattingError) Message() string {
+	return "FORMAT: generated code is invalid: " + e.Err.Error()
+}
+
+type TemplatingError struct {
+	Err error
+}
+
+func (e TemplatingError) String() string {
+	return "Type: TEMPLATE - Message: " + e.Err.Error()
+}
+
+func (e TemplatingError) Location() (line, pos int) {
+	return 0, 0
+}
+
+func (e TemplatingError) Message() string {
+	return "TEMPLATE: " + e.Err.Error()
+}
+
+var (
+	SyntaxError          = errors.New("Syntax error")
+	SemanticError        = errors.New("Semantic error")
+	FormatError          = errors.New("Format error")
+	TemplateError        = errors.New("Template error")
+	UnhandledEventsError = errors.New("Unhandled events")
+)
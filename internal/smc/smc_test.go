@@ -2,8 +2,13 @@ package smc
 
 import (
 	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/geisonbiazus/smc/internal/smc/coverage"
 	"github.com/geisonbiazus/smc/internal/smc/parser"
 	"github.com/geisonbiazus/smc/internal/smc/semantic"
 	"github.com/stretchr/testify/assert"
@@ -17,7 +22,7 @@ func TestCompiler(t *testing.T) {
 				Type: parser.ErrorSyntax, LineNumber: 1, Position: 1,
 			},
 		)
-		assert.Equal(t, CompileError, err)
+		assert.Equal(t, SyntaxError, err)
 	})
 
 	t.Run("Collect parse errors", func(t *testing.T) {
@@ -27,7 +32,7 @@ func TestCompiler(t *testing.T) {
 				Type: parser.ErrorParse, LineNumber: 1, Position: 4, Msg: "HEADER|COLON",
 			},
 		)
-		assert.Equal(t, CompileError, err)
+		assert.Equal(t, SyntaxError, err)
 	})
 
 	t.Run("Collect semantic errors", func(t *testing.T) {
@@ -35,7 +40,55 @@ func TestCompiler(t *testing.T) {
 		assertContainsError(t, compiler,
 			semantic.Error{Type: semantic.ErrorNoFSM, Element: "FSM"},
 		)
-		assert.Equal(t, CompileError, err)
+		assert.Equal(t, SemanticError, err)
+	})
+
+	t.Run("Cyclic super states stop the compilation", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		compiler, err := compileFSM("FSM: fsm Initial: a { a:b e a - \n b:a e b - }", buffer)
+		assertContainsError(t, compiler,
+			semantic.Error{
+				Type: semantic.ErrorCyclicSuperStates, Element: "a->b->a", LineNumber: 1, Position: 23,
+			},
+		)
+		assert.Equal(t, SemanticError, err)
+		assert.Empty(t, buffer.String())
+	})
+
+	t.Run("Collect semantic warnings", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		compiler, err := compileFSM(unusedStateFSM, buffer)
+		assert.Equal(t, []Error{
+			semantic.Error{Type: semantic.ErrorUnusedState, Element: "other", LineNumber: 2, Position: 2},
+		}, compiler.Warnings)
+		assert.Empty(t, compiler.Errors)
+		assert.NotEmpty(t, buffer.String())
+		assert.Nil(t, err)
+	})
+
+	t.Run("Warnings as errors", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		compiler := NewCompiler(bytes.NewBufferString(unusedStateFSM), buffer)
+		compiler.WarningsAsErrors = true
+
+		assert.Equal(t, SemanticError, compiler.Compile())
+		assert.NotEmpty(t, compiler.Warnings)
+		assert.Empty(t, buffer.String())
+	})
+
+	t.Run("Suppressed warnings", func(t *testing.T) {
+		compiler := NewCompiler(bytes.NewBufferString(unusedStateFSM), &bytes.Buffer{})
+		compiler.WarningsAsErrors = true
+		compiler.SuppressedWarnings = []semantic.ErrorType{semantic.ErrorUnusedState}
+
+		assert.Nil(t, compiler.Compile())
+		assert.Empty(t, compiler.Warnings)
+	})
+
+	t.Run("Nothing is written on errors", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		compileFSM("FSM: fsm Initial: other { state event state action }", buffer)
+		assert.Empty(t, buffer.String())
 	})
 
 	t.Run("Write the compiled output", func(t *testing.T) {
@@ -45,8 +98,137 @@ func TestCompiler(t *testing.T) {
 		assert.Equal(t, compiledFSM, buffer.String())
 		assert.Nil(t, err)
 	})
+
+	t.Run("Generated header", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		compiler := NewCompiler(bytes.NewBufferString("FSM: fsm Initial: state { state event state action }"), buffer)
+		compiler.SourceFile = "turnstile.sm"
+
+		assert.Nil(t, compiler.Compile())
+		assert.True(t, strings.HasPrefix(buffer.String(), "// Code generated by smc from turnstile.sm. DO NOT EDIT.\n\npackage fsm\n"))
+	})
+
+	t.Run("Formatting errors stop the compilation", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		compiler, err := compileFSM("FSM: fsm Initial: a Event: e(n type) { a e a - }", buffer)
+
+		assert.Equal(t, FormatError, err)
+		assert.Len(t, compiler.Errors, 1)
+		assert.Contains(t, compiler.Errors[0].Message(), "FORMAT: generated code is invalid: ")
+		assert.Empty(t, buffer.String())
+	})
+
+	t.Run("Table generator", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		compiler := NewCompiler(bytes.NewBufferString("FSM: fsm Initial: state { state event state action }"), buffer)
+		compiler.Generator = GeneratorTable
+
+		assert.Nil(t, compiler.Compile())
+		assert.Contains(t, buffer.String(), "stateState: {")
+		assert.NotContains(t, buffer.String(), "type BaseState struct")
+	})
+
+	t.Run("Java", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		compiler := NewCompiler(bytes.NewBufferString("FSM: fsm Initial: state { state event state action }"), buffer)
+		compiler.Language = LanguageJava
+		compiler.Generator = GeneratorTable
+
+		assert.Nil(t, compiler.Compile())
+		assert.True(t, strings.HasPrefix(buffer.String(), "// Code generated by smc. DO NOT EDIT.\n\npackage fsm;\n"))
+		assert.Contains(t, buffer.String(), "    private static final class StateState extends State {\n")
+	})
+
+	t.Run("Java errors stop the compilation", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		compiler := NewCompiler(bytes.NewBufferString("FSM: fsm Initial: state Event: new(n *int) { state new state - }"), buffer)
+		compiler.Language = LanguageJava
+
+		assert.Equal(t, SemanticError, compiler.Compile())
+		assert.Empty(t, buffer.String())
+		assert.Len(t, compiler.Errors, 2)
+		assert.Equal(t, "JAVA_RESERVED_WORD: new", compiler.Errors[0].Message())
+		assert.Equal(t, "JAVA_UNSUPPORTED_TYPE: new:n *int", compiler.Errors[1].Message())
+	})
+
+	t.Run("Template errors stop the compilation", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "smc-templates")
+		assert.Nil(t, err)
+		defer os.RemoveAll(dir)
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "header.tmpl"), []byte("{{end}}"), 0644))
+
+		buffer := &bytes.Buffer{}
+		compiler := NewCompiler(bytes.NewBufferString("FSM: fsm Initial: state { state event state action }"), buffer)
+		compiler.TemplateDir = dir
+
+		assert.Equal(t, TemplateError, compiler.Compile())
+		assert.Empty(t, buffer.String())
+		assert.Len(t, compiler.Errors, 1)
+		assert.Contains(t, compiler.Errors[0].Message(), "TEMPLATE: ")
+	})
+
+	t.Run("Coverage", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		compiler := NewCompiler(bytes.NewBufferString("FSM: f Initial: a { a e b - \n b f a - }"), buffer)
+
+		assert.Nil(t, compiler.Coverage(coverage.FormatCSV, false))
+		assert.Equal(t, "State,e,f\na,handled,unhandled\nb,unhandled,handled\n", buffer.String())
+
+		compiler = NewCompiler(bytes.NewBufferString("FSM: f Initial: a { a e b - \n b f a - }"), &bytes.Buffer{})
+		assert.Equal(t, UnhandledEventsError, compiler.Coverage(coverage.FormatCSV, true))
+
+		compiler = NewCompiler(bytes.NewBufferString("a:b {}"), &bytes.Buffer{})
+		assert.Equal(t, SemanticError, compiler.Coverage(coverage.FormatCSV, true))
+	})
+
+	t.Run("Package name", func(t *testing.T) {
+		assertPackage(t, "FSM: f Initial: s { s e s a }", "", "", "package fsm\n")
+		assertPackage(t, "FSM: f Initial: s { s e s a }", "", "dir", "package dir\n")
+		assertPackage(t, "FSM: f Package: header Initial: s { s e s a }", "", "dir", "package header\n")
+		assertPackage(t, "FSM: f Package: header Initial: s { s e s a }", "flag", "dir", "package flag\n")
+	})
 }
 
+func TestFormatDiagnostic(t *testing.T) {
+	assert.Equal(t,
+		"turnstile.sm:3:5: error: UNDEFINED_STATE: Unlockd",
+		FormatDiagnostic("turnstile.sm", "error", semantic.Error{
+			Type: semantic.ErrorUndefinedState, Element: "Unlockd", LineNumber: 3, Position: 5,
+		}),
+	)
+	assert.Equal(t,
+		"turnstile.sm:1:4: error: PARSE: HEADER|COLON",
+		FormatDiagnostic("turnstile.sm", "error", parser.SyntaxError{
+			Type: parser.ErrorParse, Msg: "HEADER|COLON", LineNumber: 1, Position: 4,
+		}),
+	)
+	assert.Equal(t,
+		"turnstile.sm:2:1: error: SYNTAX",
+		FormatDiagnostic("turnstile.sm", "error", parser.SyntaxError{
+			Type: parser.ErrorSyntax, LineNumber: 2, Position: 1,
+		}),
+	)
+	assert.Equal(t,
+		"turnstile.sm: warning: NO_FSM: FSM",
+		FormatDiagnostic("turnstile.sm", "warning", semantic.Error{
+			Type: semantic.ErrorNoFSM, Element: "FSM",
+		}),
+	)
+}
+
+func assertPackage(t *testing.T, input, pkg, defaultPkg, expected string) {
+	t.Helper()
+	buffer := &bytes.Buffer{}
+	compiler := NewCompiler(bytes.NewBufferString(input), buffer)
+	compiler.Package = pkg
+	compiler.DefaultPackage = defaultPkg
+
+	assert.Nil(t, compiler.Compile())
+	assert.Contains(t, buffer.String(), "\n\n"+expected)
+}
+
+const unusedStateFSM = "FSM: fsm Initial: state { state event state action \n other event state action }"
+
 func compileFSM(input string, output *bytes.Buffer) (*Compiler, error) {
 	compiler := NewCompiler(bytes.NewBufferString(input), output)
 	err := compiler.Compile()
@@ -58,50 +240,53 @@ func assertContainsError(t *testing.T, compiler *Compiler, err Error) {
 	assert.Contains(t, compiler.Errors, err)
 }
 
-var compiledFSM = `package fsm
+var compiledFSM = `// Code generated by smc. DO NOT EDIT.
+
+package fsm
 
 type State interface {
-  Event(fsm *Fsm)
+	Event(fsm *Fsm)
 }
 
 type Actions interface {
-  Action()
-  UnhandledTransition(state string, event string)
+	Action()
+	UnhandledTransition(state string, event string)
 }
 
 type Fsm struct {
-  State State
-  Actions Actions
+	State   State
+	Actions Actions
 }
 
 func NewFsm(actions Actions) *Fsm {
-  return &Fsm{
-    Actions: actions,
-    State:   NewStateState(),
-  }
+	return &Fsm{
+		Actions: actions,
+		State:   NewStateState(),
+	}
 }
 
 func (f *Fsm) Event() {
-  f.State.Event(f)
+	f.State.Event(f)
 }
 
-type BaseState struct {  StateName string
+type BaseState struct {
+	StateName string
 }
 
 func (b BaseState) Event(fsm *Fsm) {
-  fsm.Actions.UnhandledTransition(b.StateName, "event")
+	fsm.Actions.UnhandledTransition(b.StateName, "event")
 }
 
 type StateState struct {
-  BaseState
+	BaseState
 }
 
 func NewStateState() StateState {
-  return StateState{BaseState{StateName: "state"}}
+	return StateState{BaseState{StateName: "state"}}
 }
 
 func (s StateState) Event(fsm *Fsm) {
-  fsm.State = NewStateState()
-  fsm.Actions.Action()
+	fsm.State = NewStateState()
+	fsm.Actions.Action()
 }
 `
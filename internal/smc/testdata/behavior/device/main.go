@@ -0,0 +1,32 @@
+package main
+
+import "fmt"
+
+type actions struct{}
+
+func (a *actions) Start()      { fmt.Println("start") }
+func (a *actions) Stop()       { fmt.Println("stop") }
+func (a *actions) Connect()    { fmt.Println("connect") }
+func (a *actions) Disconnect() { fmt.Println("disconnect") }
+func (a *actions) Discharge()  { fmt.Println("discharge") }
+func (a *actions) Charging()   { fmt.Println("charging") }
+
+func (a *actions) UnhandledTransition(state string, event string) {
+	fmt.Println("unhandled", state, event)
+}
+
+func main() {
+	fsm := NewDevice(&actions{})
+	fsm.Unplug()
+	fsm.PowerOn()
+	fsm.Unplug()
+	fsm.Charge()
+	fsm.Unplug()
+	fsm.Plug()
+	fsm.Plug()
+	fsm.Charge()
+	fsm.Charge()
+	fsm.Unplug()
+	fsm.Stop()
+	fsm.Charge()
+}
@@ -0,0 +1,35 @@
+package main
+
+import "fmt"
+
+type actions struct {
+	valid bool
+}
+
+func (a *actions) Record(p int, args string) { fmt.Println("record", p, args) }
+func (a *actions) Reject(p int, args string) { fmt.Println("reject", p, args) }
+
+func (a *actions) Calibrate(state int, event string, e int, r int) {
+	fmt.Println("calibrate", state, event, e, r)
+}
+
+func (a *actions) Guard(name string) bool {
+	a.valid = !a.valid
+	return a.valid
+}
+
+func (a *actions) UnhandledTransition(state string, event string) {
+	fmt.Println("unhandled", state, event)
+}
+
+func main() {
+	fsm := NewMeter(&actions{})
+	fsm.Read(1, "a")
+	fsm.Read(2, "b")
+	fsm.Tune(1, "x", 2, 3)
+	fsm.Start()
+	fsm.Tune(4, "y", 5, 6)
+	fsm.Read(3, "c")
+	fsm.Stop()
+	fsm.Tune(7, "z", 8, 9)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+type actions struct {
+	busy bool
+}
+
+func (a *actions) Ring()       { fmt.Println("ring") }
+func (a *actions) BusySignal() { fmt.Println("busySignal") }
+func (a *actions) Connect()    { fmt.Println("connect") }
+func (a *actions) HangUp()     { fmt.Println("hangUp") }
+func (a *actions) Timeout()    { fmt.Println("timeout") }
+func (a *actions) MuteOn()     { fmt.Println("muteOn") }
+func (a *actions) MuteOff()    { fmt.Println("muteOff") }
+func (a *actions) Guard(name string) bool {
+	fmt.Println("guard", name)
+	return a.busy
+}
+
+func (a *actions) UnhandledTransition(state string, event string) {
+	fmt.Println("unhandled", state, event)
+}
+
+type fakeClock struct {
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	d       time.Duration
+	f       func()
+	stopped bool
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	t := &fakeTimer{d: d, f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+func (t *fakeTimer) Stop() bool {
+	active := !t.stopped
+	t.stopped = true
+	return active
+}
+
+func (c *fakeClock) fire(d time.Duration) {
+	fmt.Println("fire", d)
+	for _, t := range c.timers {
+		if t.d == d && !t.stopped {
+			t.stopped = true
+			t.f()
+		}
+	}
+}
+
+func main() {
+	a := &actions{busy: true}
+	clock := &fakeClock{}
+	fsm := NewPhoneWithClock(a, clock)
+	fsm.Toggle()
+	fsm.Dial()
+	a.busy = false
+	fsm.Dial()
+	fsm.Toggle()
+	clock.fire(5 * time.Second)
+	fsm.Toggle()
+	fsm.Hang()
+	clock.fire(5 * time.Second)
+	fsm.Toggle()
+	fsm.Dial()
+	clock.fire(time.Minute)
+	fsm.Toggle()
+}
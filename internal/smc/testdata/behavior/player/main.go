@@ -0,0 +1,30 @@
+package main
+
+import "fmt"
+
+type actions struct{}
+
+func (a *actions) StartMotor() { fmt.Println("startMotor") }
+func (a *actions) StopMotor()  { fmt.Println("stopMotor") }
+func (a *actions) Ignore()     { fmt.Println("ignore") }
+
+func (a *actions) UnhandledTransition(state string, event string) {
+	fmt.Println("unhandled", state, event)
+}
+
+func main() {
+	fsm := NewPlayer(&actions{})
+	fsm.Resume()
+	fsm.Play()
+	fsm.Faster()
+	fsm.Pause()
+	fsm.Faster()
+	fsm.Resume()
+	fsm.Faster()
+	fsm.Slower()
+	fsm.Faster()
+	fsm.Restart()
+	fsm.Slower()
+	fsm.Stop()
+	fsm.Resume()
+}
@@ -0,0 +1,38 @@
+package main
+
+import "fmt"
+
+type actions struct {
+	enough bool
+}
+
+func (a *actions) Armed()            { fmt.Println("armed") }
+func (a *actions) Disarmed()         { fmt.Println("disarmed") }
+func (a *actions) Alarm()            { fmt.Println("alarm") }
+func (a *actions) Lock()             { fmt.Println("lock") }
+func (a *actions) Unlock(amount int) { fmt.Println("unlock", amount) }
+func (a *actions) Refund(amount int) { fmt.Println("refund", amount) }
+func (a *actions) Completed()        { fmt.Println("completed") }
+func (a *actions) Guard(name string) bool {
+	fmt.Println("guard", name)
+	return a.enough
+}
+
+func (a *actions) UnhandledTransition(state string, event string) {
+	fmt.Println("unhandled", state, event)
+}
+
+func main() {
+	a := &actions{}
+	fsm := NewTurnstile(a)
+	fsm.Pass()
+	fsm.Coin(1)
+	a.enough = true
+	fsm.Coin(2)
+	fsm.Coin(3)
+	fsm.Pass()
+	fmt.Println("finished", fsm.IsFinished())
+	fsm.Fail()
+	fmt.Println("finished", fsm.IsFinished())
+	fsm.Pass()
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+type actions struct {
+	ticks int
+}
+
+func (a *actions) Tick() { a.ticks++ }
+
+func (a *actions) UnhandledTransition(state string, event string) {}
+
+func main() {
+	a := &actions{}
+	fsm := NewBlinker(a)
+	deadline := time.Now().Add(200 * time.Millisecond)
+	for time.Now().Before(deadline) && !fsm.IsFinished() {
+		fsm.Toggle()
+		time.Sleep(100 * time.Microsecond)
+	}
+	fsm.Kill()
+	time.Sleep(5 * time.Millisecond)
+	fmt.Println("ticked", a.ticks > 0)
+	fmt.Println("finished", fsm.IsFinished())
+}